@@ -77,6 +77,28 @@ func PrintHelp() {
 	fmt.Println(color.GreenString("--------------------------------------------------"))
 	fmt.Println(color.WhiteString("Usage:"))
 	fmt.Println(color.WhiteString("  kodo [flags]"))
+	fmt.Println(color.WhiteString("  kodo [flags] <command> [command flags]"))
+	fmt.Println()
+	fmt.Println(color.WhiteString("Available Commands:"))
+	fmt.Println(color.WhiteString("  backfill                Rebuild item history from git commits"))
+	fmt.Println(color.WhiteString("      -b, --branch <name>   Branch to replay (default current branch)"))
+	fmt.Println(color.WhiteString("      --every <n>           Scan every n-th commit"))
+	fmt.Println(color.WhiteString("      --limit <n>           Scan at most n evenly spaced commits"))
+	fmt.Println(color.WhiteString("      --since <date>        Only replay commits after YYYY-MM-DD"))
+	fmt.Println(color.WhiteString("  hotspots                Rank files and directories by items and churn"))
+	fmt.Println(color.WhiteString("      -b, --board <id>      Board to report on (default board)"))
+	fmt.Println(color.WhiteString("      --window <days>       Count commits from the last n days (default 90)"))
+	fmt.Println(color.WhiteString("      --limit <n>           Show at most n entries (default 20)"))
+	fmt.Println(color.WhiteString("      --depth <n>           Aggregate directories up to n levels deep"))
+	fmt.Println(color.WhiteString("  release-notes <from> [to] List items resolved and introduced between two tags"))
+	fmt.Println(color.WhiteString("      --format <format>     Output markdown or json (default markdown)"))
+	fmt.Println(color.WhiteString("  export <dataset>        Export items, transitions, snapshots or snapshot_counts"))
+	fmt.Println(color.WhiteString("      --format <format>     Output csv or ndjson (default csv)"))
+	fmt.Println(color.WhiteString("      -b, --board <id>      Board to export (default board)"))
+	fmt.Println(color.WhiteString("      -o, --output <file>   Write to a file instead of stdout"))
+	fmt.Println(color.WhiteString("  migrate-storage <backend> Move notes and item history to json, bolt or files storage"))
+	fmt.Println(color.WhiteString("  install-merge-driver    Merge notes, items and settings semantically in git"))
+	fmt.Println(color.WhiteString("  merge-driver <base> <ours> <theirs> [path] Three-way merge of a data file, run by git"))
 	fmt.Println()
 	fmt.Println(color.WhiteString("Available Flags:"))
 	fmt.Println(color.WhiteString("  -p, --port <port>       Change the app’s port (default 3519)"))
@@ -0,0 +1,62 @@
+package entities
+
+import "time"
+
+type AutomationAction string
+
+const (
+	AutomationEscalate  AutomationAction = "escalate"
+	AutomationFlagStale AutomationAction = "flag_stale"
+	// AutomationUnflagStale is only recorded in the log, when a stale
+	// item no longer matches the rule that flagged it.
+	AutomationUnflagStale AutomationAction = "unflag_stale"
+)
+
+func (a AutomationAction) IsValid() bool {
+	return a == AutomationEscalate || a == AutomationFlagStale
+}
+
+// AutomationRule applies Action to every item matching Query that has not
+// been touched for UntouchedDays and, when set, whose file has had no
+// commits for NoCommitDays.
+type AutomationRule struct {
+	ID            string           `json:"id"`
+	Name          string           `json:"name"`
+	Enabled       bool             `json:"enabled"`
+	Query         string           `json:"query"`
+	UntouchedDays int              `json:"untouched_days"`
+	NoCommitDays  int              `json:"no_commit_days,omitempty"`
+	Action        AutomationAction `json:"action"`
+	Priority      ItemPriority     `json:"priority,omitempty"`
+}
+
+type AutomationChange struct {
+	RuleID    string           `json:"rule_id"`
+	RuleName  string           `json:"rule_name"`
+	Action    AutomationAction `json:"action"`
+	ItemKey   string           `json:"item_key"`
+	Title     string           `json:"title"`
+	File      string           `json:"file"`
+	Line      int              `json:"line"`
+	From      string           `json:"from,omitempty"`
+	To        string           `json:"to,omitempty"`
+	Error     string           `json:"error,omitempty"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+type StaleFlag struct {
+	RuleID    string    `json:"rule_id"`
+	FlaggedAt time.Time `json:"flagged_at"`
+}
+
+type AutomationStorage struct {
+	Stale map[string]StaleFlag `json:"stale"`
+	Log   []AutomationChange   `json:"log"`
+}
+
+type AutomationReport struct {
+	DryRun      bool               `json:"dry_run"`
+	EvaluatedAt time.Time          `json:"evaluated_at"`
+	Rules       int                `json:"rules"`
+	Changes     []AutomationChange `json:"changes"`
+}
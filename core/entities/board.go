@@ -0,0 +1,119 @@
+package entities
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const DefaultBoardID = "default"
+
+type Board struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Columns     []KanbanColumn `json:"columns"`
+	PathFilters []string       `json:"path_filters,omitempty"`
+}
+
+func (b *Board) ItemTypes() []string {
+	var types []string
+	for _, col := range b.Columns {
+		if col.AutoAssignPattern == nil {
+			continue
+		}
+		for _, pattern := range strings.Split(*col.AutoAssignPattern, "|") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				types = append(types, pattern)
+			}
+		}
+	}
+	return types
+}
+
+func (b *Board) MatchesType(itemType ItemType) bool {
+	for _, t := range b.ItemTypes() {
+		if t == string(itemType) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Board) MatchesFile(file string) bool {
+	if len(b.PathFilters) == 0 {
+		return true
+	}
+
+	path := filepath.ToSlash(file)
+	for _, filter := range b.PathFilters {
+		filter = filepath.ToSlash(filter)
+		if matched, err := doublestar.Match(filter, path); err == nil && matched {
+			return true
+		}
+		if strings.HasPrefix(path, strings.TrimSuffix(filter, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Board) Contains(itemType ItemType, file string) bool {
+	return b.MatchesType(itemType) && b.MatchesFile(file)
+}
+
+func (b *Board) GetColumn(id string) *KanbanColumn {
+	for i := range b.Columns {
+		if b.Columns[i].ID == id {
+			return &b.Columns[i]
+		}
+	}
+	return nil
+}
+
+func (b *Board) StatusFor(status ItemStatus) ItemStatus {
+	for _, col := range b.Columns {
+		if string(status) == col.ID || strings.EqualFold(string(status), col.Name) {
+			return ItemStatus(col.ID)
+		}
+	}
+	if len(b.Columns) == 0 {
+		return status
+	}
+	return ItemStatus(b.Columns[0].ID)
+}
+
+func (s *Settings) DefaultBoard() Board {
+	return Board{
+		ID:      DefaultBoardID,
+		Name:    "Default",
+		Columns: s.KanbanColumns,
+	}
+}
+
+func (s *Settings) GetBoards() []Board {
+	boards := []Board{s.DefaultBoard()}
+	return append(boards, s.Boards...)
+}
+
+func (s *Settings) BoardFor(item *Item) Board {
+	for _, id := range item.Boards {
+		if board, ok := s.GetBoard(id); ok {
+			return *board
+		}
+	}
+	return s.DefaultBoard()
+}
+
+func (s *Settings) GetBoard(id string) (*Board, bool) {
+	if id == "" || id == DefaultBoardID {
+		board := s.DefaultBoard()
+		return &board, true
+	}
+	for i := range s.Boards {
+		if s.Boards[i].ID == id {
+			return &s.Boards[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,42 @@
+package entities
+
+type ChartRange struct {
+	Board    string `json:"board"`
+	Branch   string `json:"branch"`
+	SprintID int    `json:"sprint_id,omitempty"`
+	Query    string `json:"query,omitempty"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+type BurndownPoint struct {
+	Date      string  `json:"date"`
+	Remaining int     `json:"remaining"`
+	Ideal     float64 `json:"ideal"`
+	Scope     int     `json:"scope"`
+	Completed int     `json:"completed"`
+}
+
+type BurndownChart struct {
+	ChartRange
+	Points []BurndownPoint `json:"points"`
+}
+
+type BurnupPoint struct {
+	Date      string `json:"date"`
+	Scope     int    `json:"scope"`
+	Completed int    `json:"completed"`
+}
+
+type BurnupChart struct {
+	ChartRange
+	Points []BurnupPoint `json:"points"`
+}
+
+// CumulativeFlowChart points hold a "date" key plus one count per column
+// ID, so they can be passed straight to a stacked area chart.
+type CumulativeFlowChart struct {
+	ChartRange
+	Columns []KanbanColumn           `json:"columns"`
+	Points  []map[string]interface{} `json:"points"`
+}
@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+type DirectoryDebt struct {
+	Path     string  `json:"path"`
+	Items    int     `json:"items"`
+	Score    float64 `json:"score"`
+	Previous float64 `json:"previous"`
+	Delta    float64 `json:"delta"`
+}
+
+type DebtReport struct {
+	Total       float64         `json:"total"`
+	Since       time.Time       `json:"since"`
+	Threshold   float64         `json:"threshold"`
+	Directories []DirectoryDebt `json:"directories"`
+	Regressions []DirectoryDebt `json:"regressions"`
+}
+
+type DebtPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Commit    string    `json:"commit"`
+	Score     float64   `json:"score"`
+}
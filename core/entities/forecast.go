@@ -0,0 +1,39 @@
+package entities
+
+import "time"
+
+type ThroughputSample struct {
+	Date      string `json:"date"`
+	Completed int    `json:"completed"`
+}
+
+// CompletionPercentile means: with Percentile% probability the remaining
+// items are finished within Days, on or before Date.
+type CompletionPercentile struct {
+	Percentile int       `json:"percentile"`
+	Days       int       `json:"days"`
+	Date       time.Time `json:"date"`
+}
+
+// VolumePercentile means: with Percentile% probability at least Items are
+// finished by the target date.
+type VolumePercentile struct {
+	Percentile int `json:"percentile"`
+	Items      int `json:"items"`
+}
+
+type Forecast struct {
+	Board             string                 `json:"board"`
+	Branch            string                 `json:"branch"`
+	Query             string                 `json:"query,omitempty"`
+	Column            string                 `json:"column,omitempty"`
+	Remaining         int                    `json:"remaining"`
+	WindowDays        int                    `json:"window_days"`
+	Trials            int                    `json:"trials"`
+	AverageThroughput float64                `json:"average_throughput"`
+	Throughput        []ThroughputSample     `json:"throughput"`
+	Completion        []CompletionPercentile `json:"completion,omitempty"`
+	Until             string                 `json:"until,omitempty"`
+	ItemsByDate       []VolumePercentile     `json:"items_by_date,omitempty"`
+	Message           string                 `json:"message,omitempty"`
+}
@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// Hotspot is a file or directory where open items meet git churn. Score is
+// Items multiplied by Commits.
+type Hotspot struct {
+	Path         string `json:"path"`
+	Items        int    `json:"items"`
+	HighPriority int    `json:"high_priority"`
+	Commits      int    `json:"commits"`
+	LinesChanged int    `json:"lines_changed"`
+	Score        int    `json:"score"`
+}
+
+type HotspotReport struct {
+	Since       time.Time `json:"since"`
+	WindowDays  int       `json:"window_days"`
+	Commits     int       `json:"commits"`
+	Files       []Hotspot `json:"files"`
+	Directories []Hotspot `json:"directories"`
+}
@@ -1,6 +1,11 @@
 package entities
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -18,6 +23,7 @@ type StatusHistory struct {
 
 type Item struct {
 	ID          int          `json:"id"`
+	Key         string       `json:"key"`
 	Type        ItemType     `json:"type"`
 	Title       string       `json:"title"`
 	Description string       `json:"description"`
@@ -28,6 +34,9 @@ type Item struct {
 	IsDone      bool         `json:"is_done"`
 	DoneAt      *time.Time   `json:"done_at"`
 	DoneBy      *string      `json:"done_by"`
+	IssueNumber *int         `json:"issue_number,omitempty"`
+	Boards      []string     `json:"boards"`
+	Stale       bool         `json:"stale,omitempty"`
 
 	History []StatusHistory `json:"history,omitempty"`
 
@@ -57,15 +66,46 @@ func (i *Item) SetStatus(status ItemStatus, user string) {
 	})
 }
 
+func (i *Item) InBoard(boardID string) bool {
+	if len(i.Boards) == 0 {
+		return boardID == DefaultBoardID
+	}
+	for _, id := range i.Boards {
+		if id == boardID {
+			return true
+		}
+	}
+	return false
+}
+
 func (i *Item) GetFullTitle() string {
 	return string(i.Type) + ": " + i.Title
 }
 
+// ItemKey identifies an item across scans. occurrence counts the earlier
+// items of the same type and title in the file, so that identical comments
+// get keys of their own; the first one keeps the key of a unique item.
+func ItemKey(file string, itemType ItemType, title string, occurrence int) string {
+	parts := []string{
+		filepath.ToSlash(file),
+		strings.ToUpper(string(itemType)),
+		strings.Join(strings.Fields(title), " "),
+	}
+	if occurrence > 0 {
+		parts = append(parts, strconv.Itoa(occurrence))
+	}
+	content := strings.Join(parts, "\x00")
+
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:16])
+}
+
 func NewItem(id int, itemType ItemType, itemStatus ItemStatus, itemPriority ItemPriority, title, description, file string, line int, user string) *Item {
 	now := time.Now()
 
 	item := &Item{
 		ID:          id,
+		Key:         ItemKey(file, itemType, title, 0),
 		Type:        itemType,
 		Title:       title,
 		Description: description,
@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+type ItemComment struct {
+	ID        int       `json:"id"`
+	ParentID  *int      `json:"parent_id,omitempty"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ItemCommentThread struct {
+	ItemKey   string        `json:"item_key"`
+	File      string        `json:"file"`
+	Line      int           `json:"line"`
+	Type      ItemType      `json:"type"`
+	Title     string        `json:"title"`
+	Comments  []ItemComment `json:"comments"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type ItemCommentStorage struct {
+	Threads []ItemCommentThread `json:"threads"`
+	NextID  int                 `json:"next_id"`
+}
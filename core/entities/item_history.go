@@ -3,6 +3,7 @@ package entities
 import "time"
 
 type ItemsHistory struct {
+	SchemaVersion  int              `json:"schema_version"`
 	ProjectPath    string           `json:"project_path"`
 	LastScanAt     time.Time        `json:"last_scan_at"`
 	GitBranch      string           `json:"git_branch"`
@@ -14,17 +15,39 @@ type ItemsHistory struct {
 	ItemsByFile    map[string]int   `json:"items_by_file"`
 	CurrentItems   []TaskItem       `json:"current_items"`
 	BranchHistory  []BranchSnapshot `json:"branch_history,omitempty"`
+	Lifecycles     []ItemLifecycle  `json:"lifecycles,omitempty"`
 	CreatedAt      time.Time        `json:"created_at"`
 	UpdatedAt      time.Time        `json:"updated_at"`
 }
 
 type BranchSnapshot struct {
-	Branch        string    `json:"branch"`
-	Commit        string    `json:"commit"`
-	CommitShort   string    `json:"commit_short"`
-	CommitMessage string    `json:"commit_message"`
-	Timestamp     time.Time `json:"timestamp"`
-	History       ItemStats `json:"history"`
+	Branch        string                `json:"branch"`
+	Commit        string                `json:"commit"`
+	CommitShort   string                `json:"commit_short"`
+	CommitMessage string                `json:"commit_message"`
+	Timestamp     time.Time             `json:"timestamp"`
+	History       ItemStats             `json:"history"`
+	Boards        map[string]BoardStats `json:"boards,omitempty"`
+	Backfilled    bool                  `json:"backfilled,omitempty"`
+	Rollup        SnapshotRollup        `json:"rollup,omitempty"`
+	// Debt maps directories to their rolled up debt points; "." is the
+	// whole project.
+	Debt map[string]float64 `json:"debt,omitempty"`
+}
+
+type SnapshotRollup string
+
+const (
+	SnapshotRollupDaily  SnapshotRollup = "daily"
+	SnapshotRollupWeekly SnapshotRollup = "weekly"
+)
+
+type BoardStats struct {
+	Total      int            `json:"total"`
+	Done       int            `json:"done"`
+	ByStatus   map[string]int `json:"by_status"`
+	ByType     map[string]int `json:"by_type"`
+	ByPriority map[string]int `json:"by_priority"`
 }
 
 type ItemStats struct {
@@ -37,6 +60,7 @@ type ItemStats struct {
 
 type TaskItem struct {
 	ID       int          `json:"id"`
+	Key      string       `json:"key,omitempty"`
 	Type     ItemType     `json:"type"`
 	Title    string       `json:"title"`
 	File     string       `json:"file"`
@@ -47,4 +71,74 @@ type TaskItem struct {
 	DoneAt   *time.Time   `json:"done_at"`
 	DoneBy   *string      `json:"done_by"`
 	Hash     string       `json:"hash"`
+	Boards   []string     `json:"boards,omitempty"`
+}
+
+func (t *TaskItem) GetKey() string {
+	if t.Key != "" {
+		return t.Key
+	}
+	return ItemKey(t.File, t.Type, t.Title, 0)
+}
+
+func (t *TaskItem) InBoard(boardID string) bool {
+	if len(t.Boards) == 0 {
+		return boardID == DefaultBoardID
+	}
+	for _, id := range t.Boards {
+		if id == boardID {
+			return true
+		}
+	}
+	return false
+}
+
+type ItemRemoval string
+
+const (
+	RemovalResolved  ItemRemoval = "resolved"
+	RemovalDiscarded ItemRemoval = "discarded"
+)
+
+func (r ItemRemoval) IsValid() bool {
+	return r == RemovalResolved || r == RemovalDiscarded
+}
+
+type ItemLifecycle struct {
+	Key             string       `json:"key"`
+	Type            ItemType     `json:"type"`
+	Title           string       `json:"title"`
+	File            string       `json:"file"`
+	Line            int          `json:"line"`
+	Priority        ItemPriority `json:"priority"`
+	LastStatus      ItemStatus   `json:"last_status"`
+	Boards          []string     `json:"boards,omitempty"`
+	FirstSeenAt     time.Time    `json:"first_seen_at"`
+	FirstSeenCommit string       `json:"first_seen_commit"`
+	LastSeenAt      time.Time    `json:"last_seen_at"`
+	LastSeenCommit  string       `json:"last_seen_commit"`
+	LastSeenBranch  string       `json:"last_seen_branch,omitempty"`
+	RemovedAt       *time.Time   `json:"removed_at,omitempty"`
+	RemovedCommit   string       `json:"removed_commit,omitempty"`
+	RemovedMessage  string       `json:"removed_message,omitempty"`
+	Removal         ItemRemoval  `json:"removal,omitempty"`
+	ManualRemoval   bool         `json:"manual_removal,omitempty"`
+
+	RemovalCheckedCommit string `json:"removal_checked_commit,omitempty"`
+}
+
+func (l *ItemLifecycle) IsRemoved() bool {
+	return l.RemovedAt != nil
+}
+
+func (l *ItemLifecycle) InBoard(boardID string) bool {
+	if len(l.Boards) == 0 {
+		return boardID == DefaultBoardID
+	}
+	for _, id := range l.Boards {
+		if id == boardID {
+			return true
+		}
+	}
+	return false
 }
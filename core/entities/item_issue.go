@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+type ItemIssue struct {
+	IssueNumber int        `json:"issue_number"`
+	IssueURL    string     `json:"issue_url"`
+	ItemKey     string     `json:"item_key"`
+	File        string     `json:"file"`
+	Line        int        `json:"line"`
+	Type        ItemType   `json:"type"`
+	Title       string     `json:"title"`
+	Commit      string     `json:"commit"`
+	State       string     `json:"state"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastSync    *time.Time `json:"last_sync,omitempty"`
+}
+
+type ItemIssueStorage struct {
+	Issues []ItemIssue `json:"issues"`
+}
@@ -0,0 +1,29 @@
+package entities
+
+import "testing"
+
+func TestItemKey(t *testing.T) {
+	first := ItemKey("pkg/a.go", "TODO", "fix this", 0)
+
+	if got := ItemKey("pkg/a.go", "todo", "  fix   this ", 0); got != first {
+		t.Errorf("key changed with case and spacing: %s != %s", got, first)
+	}
+
+	second := ItemKey("pkg/a.go", "TODO", "fix this", 1)
+	if second == first {
+		t.Errorf("second occurrence has the key of the first: %s", second)
+	}
+	if third := ItemKey("pkg/a.go", "TODO", "fix this", 2); third == second || third == first {
+		t.Errorf("third occurrence collides: %s", third)
+	}
+
+	for _, other := range []string{
+		ItemKey("pkg/b.go", "TODO", "fix this", 0),
+		ItemKey("pkg/a.go", "FIXME", "fix this", 0),
+		ItemKey("pkg/a.go", "TODO", "fix that", 0),
+	} {
+		if other == first {
+			t.Errorf("different item has key %s", other)
+		}
+	}
+}
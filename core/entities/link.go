@@ -0,0 +1,44 @@
+package entities
+
+import "time"
+
+type LinkType string
+
+const (
+	LinkReferences LinkType = "references"
+	LinkBlocks     LinkType = "blocks"
+	LinkDocuments  LinkType = "documents"
+)
+
+func (t LinkType) IsValid() bool {
+	switch t {
+	case LinkReferences, LinkBlocks, LinkDocuments:
+		return true
+	}
+	return false
+}
+
+type Link struct {
+	ID        int       `json:"id"`
+	NoteID    int       `json:"note_id"`
+	ItemKey   string    `json:"item_key"`
+	Type      LinkType  `json:"type"`
+	File      string    `json:"file"`
+	Line      int       `json:"line"`
+	ItemType  ItemType  `json:"item_type"`
+	ItemTitle string    `json:"item_title"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type LinkStorage struct {
+	Links  []Link `json:"links"`
+	NextID int    `json:"next_id"`
+}
+
+type ResolvedLink struct {
+	Link
+	Note *Note `json:"note,omitempty"`
+	Item *Item `json:"item,omitempty"`
+}
@@ -7,12 +7,13 @@ import (
 type NoteHistoryAction string
 
 const (
-	ActionCreated NoteHistoryAction = "created"
-	ActionUpdated NoteHistoryAction = "updated"
-	ActionDeleted NoteHistoryAction = "deleted"
-	ActionMoved   NoteHistoryAction = "moved"
-	ActionTagged  NoteHistoryAction = "tagged"
-	ActionSynced  NoteHistoryAction = "synced"
+	ActionCreated   NoteHistoryAction = "created"
+	ActionUpdated   NoteHistoryAction = "updated"
+	ActionDeleted   NoteHistoryAction = "deleted"
+	ActionMoved     NoteHistoryAction = "moved"
+	ActionTagged    NoteHistoryAction = "tagged"
+	ActionSynced    NoteHistoryAction = "synced"
+	ActionConverted NoteHistoryAction = "converted"
 )
 
 type NoteHistoryEntry struct {
@@ -67,6 +68,7 @@ type NoteWithHistory struct {
 }
 
 type EnhancedNoteStorage struct {
+	SchemaVersion int                `json:"schema_version"`
 	Notes         []Note             `json:"notes"`
 	Folders       []Folder           `json:"folders"`
 	History       []NoteHistoryEntry `json:"history"`
@@ -0,0 +1,45 @@
+package entities
+
+import "time"
+
+type ReleaseCommit struct {
+	Hash      string    `json:"hash"`
+	Short     string    `json:"short"`
+	Author    string    `json:"author"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	URL       string    `json:"url,omitempty"`
+}
+
+type ReleaseItem struct {
+	Type     ItemType     `json:"type"`
+	Title    string       `json:"title"`
+	File     string       `json:"file"`
+	Line     int          `json:"line"`
+	Priority ItemPriority `json:"priority"`
+	// Resolution is "removed" when the comment was deleted and "done" when
+	// it was moved to the last column.
+	Resolution string          `json:"resolution,omitempty"`
+	Commits    []ReleaseCommit `json:"commits"`
+}
+
+type ReleaseGroup struct {
+	Type      ItemType      `json:"type"`
+	Directory string        `json:"directory"`
+	Items     []ReleaseItem `json:"items"`
+}
+
+type ReleaseRef struct {
+	Ref         string    `json:"ref"`
+	Commit      string    `json:"commit"`
+	CommitShort string    `json:"commit_short"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type ReleaseNotes struct {
+	From       ReleaseRef     `json:"from"`
+	To         ReleaseRef     `json:"to"`
+	Resolved   []ReleaseGroup `json:"resolved"`
+	Introduced []ReleaseGroup `json:"introduced"`
+	Summary    map[string]int `json:"summary"`
+}
@@ -3,11 +3,21 @@ package entities
 import "time"
 
 type Settings struct {
+	SchemaVersion int `json:"schema_version"`
+
 	KanbanColumns    []KanbanColumn   `json:"kanban_columns"`
+	Boards           []Board          `json:"boards"`
 	PriorityPatterns PriorityPatterns `json:"priority_patterns"`
 
-	CodeScanSettings CodeScanConfig `json:"code_scan_settings"`
-	GithubAuth       GithubAuth     `json:"github_auth"`
+	CodeScanSettings CodeScanConfig   `json:"code_scan_settings"`
+	GithubAuth       GithubAuth       `json:"github_auth"`
+	SavedQueries     []SavedQuery     `json:"saved_queries"`
+	HistoryRetention HistoryRetention `json:"history_retention"`
+	AutomationRules  []AutomationRule `json:"automation_rules"`
+	DebtModel        DebtModel        `json:"debt_model"`
+	// Storage is the backend of the notes and item history, "json",
+	// "bolt" or "files". It is changed with the migrate-storage command.
+	Storage string `json:"storage"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -35,3 +45,29 @@ type CodeScanConfig struct {
 type GithubAuth struct {
 	Token string `json:"token"`
 }
+
+type SavedQuery struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// HistoryRetention controls how long snapshots are kept in full before they
+// are compacted into daily and then weekly rollups. WeeklyDays of zero keeps
+// weekly rollups forever.
+type HistoryRetention struct {
+	FullDays   int `json:"full_days"`
+	DailyDays  int `json:"daily_days"`
+	WeeklyDays int `json:"weekly_days"`
+}
+
+// DebtModel weighs open items into debt points: the type weight times the
+// priority weight, growing by AgePerMonth for every month the item has been
+// open. Unlisted types and priorities weigh 1. A directory whose debt grows
+// by AlertThreshold points within AlertWindowDays counts as a regression.
+type DebtModel struct {
+	TypeWeights     map[string]float64 `json:"type_weights"`
+	PriorityWeights map[string]float64 `json:"priority_weights"`
+	AgePerMonth     float64            `json:"age_per_month"`
+	AlertThreshold  float64            `json:"alert_threshold"`
+	AlertWindowDays int                `json:"alert_window_days"`
+}
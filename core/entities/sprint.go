@@ -0,0 +1,94 @@
+package entities
+
+import "time"
+
+type SprintKind string
+
+const (
+	SprintKindSprint    SprintKind = "sprint"
+	SprintKindMilestone SprintKind = "milestone"
+)
+
+func (k SprintKind) IsValid() bool {
+	return k == SprintKindSprint || k == SprintKindMilestone
+}
+
+type SprintState string
+
+const (
+	SprintPlanned SprintState = "planned"
+	SprintActive  SprintState = "active"
+	SprintClosed  SprintState = "closed"
+)
+
+type SprintItem struct {
+	ItemKey   string     `json:"item_key"`
+	Type      ItemType   `json:"type"`
+	Title     string     `json:"title"`
+	File      string     `json:"file"`
+	AddedAt   time.Time  `json:"added_at"`
+	AddedBy   string     `json:"added_by"`
+	RemovedAt *time.Time `json:"removed_at,omitempty"`
+}
+
+type Sprint struct {
+	ID        int          `json:"id"`
+	Name      string       `json:"name"`
+	Kind      SprintKind   `json:"kind"`
+	Goal      string       `json:"goal"`
+	StartDate time.Time    `json:"start_date"`
+	EndDate   time.Time    `json:"end_date"`
+	Items     []SprintItem `json:"items"`
+	NoteIDs   []int        `json:"note_ids"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+func (s *Sprint) StateAt(t time.Time) SprintState {
+	if t.Before(s.StartDate) {
+		return SprintPlanned
+	}
+	if t.After(s.EndDate) {
+		return SprintClosed
+	}
+	return SprintActive
+}
+
+type SprintStorage struct {
+	Sprints []Sprint `json:"sprints"`
+	NextID  int      `json:"next_id"`
+}
+
+type SprintItemReport struct {
+	ItemKey     string     `json:"item_key"`
+	Type        ItemType   `json:"type"`
+	Title       string     `json:"title"`
+	File        string     `json:"file"`
+	Line        int        `json:"line"`
+	Status      ItemStatus `json:"status"`
+	InCode      bool       `json:"in_code"`
+	AddedAt     time.Time  `json:"added_at"`
+	RemovedAt   *time.Time `json:"removed_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+type SprintReport struct {
+	Sprint            Sprint             `json:"sprint"`
+	State             SprintState        `json:"state"`
+	DaysTotal         int                `json:"days_total"`
+	DaysElapsed       int                `json:"days_elapsed"`
+	InitialScope      int                `json:"initial_scope"`
+	ScopeAdded        int                `json:"scope_added"`
+	ScopeRemoved      int                `json:"scope_removed"`
+	Scope             int                `json:"scope"`
+	Completed         int                `json:"completed"`
+	DoneBeforeStart   int                `json:"done_before_start"`
+	Remaining         int                `json:"remaining"`
+	CarryOver         int                `json:"carry_over"`
+	CompletionPercent float64            `json:"completion_percent"`
+	Items             []SprintItemReport `json:"items"`
+	AddedItems        []SprintItemReport `json:"added_items"`
+	RemovedItems      []SprintItemReport `json:"removed_items"`
+	CarryOverItems    []SprintItemReport `json:"carry_over_items"`
+	Notes             []Note             `json:"notes"`
+}
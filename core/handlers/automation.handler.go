@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/prodemmi/kodo/core/services"
+	"go.uber.org/zap"
+)
+
+type AutomationHandler struct {
+	logger            *zap.Logger
+	automationService *services.AutomationService
+	scannerService    *services.ScannerService
+}
+
+func NewAutomationHandler(logger *zap.Logger,
+	automationService *services.AutomationService,
+	scannerService *services.ScannerService) *AutomationHandler {
+	return &AutomationHandler{
+		logger:            logger,
+		automationService: automationService,
+		scannerService:    scannerService,
+	}
+}
+
+// HandlePreview reports what the rules would change without applying it.
+func (s *AutomationHandler) HandlePreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := s.scannerService.Rescan(); err != nil {
+		s.logger.Error("Failed to rescan before automation preview", zap.Error(err))
+		http.Error(w, "Failed to scan items", http.StatusInternalServerError)
+		return
+	}
+
+	report, err := s.automationService.Evaluate(s.scannerService.GetItems(), r.URL.Query().Get("rule"), true)
+	if err != nil {
+		switch err.Error() {
+		case "automation rule not found":
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			s.logger.Error("Failed to preview automation", zap.Error(err))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+func (s *AutomationHandler) HandleLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := 100
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	log, err := s.automationService.GetLog(limit)
+	if err != nil {
+		s.logger.Error("Failed to load automation log", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"log":   log,
+		"count": len(log),
+	})
+}
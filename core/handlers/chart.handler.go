@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/prodemmi/kodo/core/services"
+	"go.uber.org/zap"
+)
+
+type ChartHandler struct {
+	logger          *zap.Logger
+	chartService    *services.ChartService
+	settingsService *services.SettingsService
+	scannerService  *services.ScannerService
+}
+
+func NewChartHandler(logger *zap.Logger,
+	chartService *services.ChartService,
+	settingsService *services.SettingsService,
+	scannerService *services.ScannerService) *ChartHandler {
+	return &ChartHandler{
+		logger:          logger,
+		chartService:    chartService,
+		settingsService: settingsService,
+		scannerService:  scannerService,
+	}
+}
+
+func (s *ChartHandler) parseScope(r *http.Request) (services.ChartScope, int, error) {
+	params := r.URL.Query()
+	scope := services.ChartScope{BoardID: params.Get("board"), Branch: params.Get("branch")}
+
+	if _, ok := s.settingsService.GetBoard(scope.BoardID); !ok {
+		return scope, http.StatusNotFound, fmt.Errorf("board %q not found", scope.BoardID)
+	}
+
+	if raw := params.Get("sprint"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			return scope, http.StatusBadRequest, fmt.Errorf("invalid sprint id")
+		}
+		scope.SprintID = id
+	}
+
+	query, err := parseRequestQuery(r, s.settingsService)
+	if err != nil {
+		return scope, http.StatusBadRequest, err
+	}
+	scope.Query = query
+
+	if scope.From, err = parseSprintDate(params.Get("from"), false); err != nil {
+		return scope, http.StatusBadRequest, fmt.Errorf("invalid from date: %v", err)
+	}
+	if scope.To, err = parseSprintDate(params.Get("to"), false); err != nil {
+		return scope, http.StatusBadRequest, fmt.Errorf("invalid to date: %v", err)
+	}
+
+	return scope, http.StatusOK, nil
+}
+
+func (s *ChartHandler) serveChart(w http.ResponseWriter, r *http.Request, build func(services.ChartScope) (interface{}, error)) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	scope, status, err := s.parseScope(r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	chart, err := build(scope)
+	if err != nil {
+		switch err.Error() {
+		case "sprint not found":
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			s.logger.Warn("Failed to build chart", zap.Error(err))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(chart)
+}
+
+func (s *ChartHandler) HandleBurndown(w http.ResponseWriter, r *http.Request) {
+	s.serveChart(w, r, func(scope services.ChartScope) (interface{}, error) {
+		return s.chartService.GetBurndown(scope)
+	})
+}
+
+func (s *ChartHandler) HandleBurnup(w http.ResponseWriter, r *http.Request) {
+	s.serveChart(w, r, func(scope services.ChartScope) (interface{}, error) {
+		return s.chartService.GetBurnup(scope)
+	})
+}
+
+func (s *ChartHandler) HandleCumulativeFlow(w http.ResponseWriter, r *http.Request) {
+	s.serveChart(w, r, func(scope services.ChartScope) (interface{}, error) {
+		return s.chartService.GetCumulativeFlow(scope)
+	})
+}
+
+func (s *ChartHandler) HandleForecast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	opts := services.ForecastOptions{
+		BoardID: params.Get("board"),
+		Branch:  params.Get("branch"),
+		Column:  params.Get("column"),
+	}
+
+	if _, ok := s.settingsService.GetBoard(opts.BoardID); !ok {
+		http.Error(w, fmt.Sprintf("board %q not found", opts.BoardID), http.StatusNotFound)
+		return
+	}
+
+	query, err := parseRequestQuery(r, s.settingsService)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts.Query = query
+
+	if opts.Until, err = parseSprintDate(params.Get("until"), false); err != nil {
+		http.Error(w, fmt.Sprintf("invalid until date: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	for name, target := range map[string]*int{"window": &opts.Window, "trials": &opts.Trials} {
+		raw := params.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, fmt.Sprintf("invalid %s", name), http.StatusBadRequest)
+			return
+		}
+		*target = n
+	}
+
+	if raw := params.Get("seed"); raw != "" {
+		if opts.Seed, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			http.Error(w, "invalid seed", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := s.scannerService.Rescan(); err != nil {
+		s.logger.Error("Failed to rescan before forecast", zap.Error(err))
+		http.Error(w, "Failed to scan items", http.StatusInternalServerError)
+		return
+	}
+
+	forecast, err := s.chartService.Forecast(opts, s.scannerService.GetItems())
+	if err != nil {
+		switch err.Error() {
+		case "column not found":
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			s.logger.Warn("Failed to build forecast", zap.Error(err))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(forecast)
+}
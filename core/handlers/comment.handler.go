@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/services"
+	"go.uber.org/zap"
+)
+
+type CommentHandler struct {
+	logger         *zap.Logger
+	commentService *services.CommentService
+	scannerService *services.ScannerService
+}
+
+func NewCommentHandler(logger *zap.Logger,
+	commentService *services.CommentService,
+	scannerService *services.ScannerService) *CommentHandler {
+	return &CommentHandler{
+		logger:         logger,
+		commentService: commentService,
+		scannerService: scannerService,
+	}
+}
+
+func (s *CommentHandler) findItem(id int, key string) *entities.Item {
+	if key != "" {
+		return s.scannerService.GetItemByKey(key)
+	}
+	return s.scannerService.GetItemByID(id)
+}
+
+func (s *CommentHandler) HandleComments(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case "GET":
+		itemID, _ := strconv.Atoi(r.URL.Query().Get("id"))
+		item := s.findItem(itemID, r.URL.Query().Get("key"))
+		if item == nil {
+			http.Error(w, "Item not found", http.StatusNotFound)
+			return
+		}
+
+		thread, err := s.commentService.GetThread(item)
+		if err != nil {
+			s.logger.Error("Failed to get comments", zap.Error(err))
+			http.Error(w, "Failed to get comments", http.StatusInternalServerError)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"thread": thread,
+			"count":  len(thread.Comments),
+		})
+
+	case "POST":
+		var commentReq struct {
+			ItemID   int    `json:"item_id"`
+			ItemKey  string `json:"item_key"`
+			ParentID *int   `json:"parent_id"`
+			Body     string `json:"body"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&commentReq); err != nil {
+			s.logger.Error("Invalid JSON for comment creation", zap.Error(err))
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+
+		item := s.findItem(commentReq.ItemID, commentReq.ItemKey)
+		if item == nil {
+			http.Error(w, "Item not found", http.StatusNotFound)
+			return
+		}
+
+		comment, err := s.commentService.AddComment(item, commentReq.ParentID, commentReq.Body)
+		if err != nil {
+			s.logger.Error("Failed to add comment", zap.String("item_key", item.Key), zap.Error(err))
+			if err.Error() == "comment body is required" || err.Error() == "parent comment not found" {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "Failed to add comment", http.StatusInternalServerError)
+			return
+		}
+
+		s.logger.Info("Comment added", zap.Int("id", comment.ID), zap.String("item_key", item.Key))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "success",
+			"comment": comment,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *CommentHandler) HandleCommentUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PUT" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var updateReq struct {
+		ID   int    `json:"id"`
+		Body string `json:"body"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := s.commentService.UpdateComment(updateReq.ID, updateReq.Body)
+	if err != nil {
+		s.logger.Error("Failed to update comment", zap.Int("id", updateReq.ID), zap.Error(err))
+		switch err.Error() {
+		case "comment not found":
+			http.Error(w, "Comment not found", http.StatusNotFound)
+		case "comment body is required":
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to update comment", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"comment": comment,
+	})
+}
+
+func (s *CommentHandler) HandleCommentDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var deleteReq struct {
+		ID int `json:"id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&deleteReq); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.commentService.DeleteComment(deleteReq.ID); err != nil {
+		s.logger.Error("Failed to delete comment", zap.Int("id", deleteReq.ID), zap.Error(err))
+		if err.Error() == "comment not found" {
+			http.Error(w, "Comment not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete comment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Comment deleted successfully",
+	})
+}
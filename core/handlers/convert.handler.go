@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/services"
+	"go.uber.org/zap"
+)
+
+type ConvertHandler struct {
+	logger         *zap.Logger
+	convertService *services.ConvertService
+	scannerService *services.ScannerService
+}
+
+func NewConvertHandler(logger *zap.Logger,
+	convertService *services.ConvertService,
+	scannerService *services.ScannerService) *ConvertHandler {
+	return &ConvertHandler{
+		logger:         logger,
+		convertService: convertService,
+		scannerService: scannerService,
+	}
+}
+
+func (s *ConvertHandler) HandleNoteToItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var convertReq struct {
+		NoteID int               `json:"note_id"`
+		File   string            `json:"file"`
+		Line   int               `json:"line"`
+		Type   entities.ItemType `json:"type"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&convertReq); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if convertReq.File == "" || convertReq.Line <= 0 {
+		http.Error(w, "File and line are required", http.StatusBadRequest)
+		return
+	}
+
+	item, err := s.convertService.NoteToItem(convertReq.NoteID, convertReq.File, convertReq.Line, convertReq.Type)
+	if err != nil {
+		s.logger.Error("Failed to convert note to item", zap.Int("note_id", convertReq.NoteID), zap.Error(err))
+		if err.Error() == "note not found" {
+			http.Error(w, "Note not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, services.ErrInvalidItemComment) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to convert note: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.Info("Note converted to item", zap.Int("note_id", convertReq.NoteID), zap.String("file", item.File), zap.Int("line", item.Line))
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"item":   item,
+	})
+}
+
+func (s *ConvertHandler) HandleItemToNote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var convertReq struct {
+		ItemKey      string `json:"item_key"`
+		FolderID     *int   `json:"folderId"`
+		DeleteSource bool   `json:"delete_source"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&convertReq); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	item := s.scannerService.GetItemByKey(convertReq.ItemKey)
+	if item == nil {
+		http.Error(w, "Item not found", http.StatusNotFound)
+		return
+	}
+
+	note, err := s.convertService.ItemToNote(item, convertReq.FolderID, convertReq.DeleteSource)
+	if err != nil {
+		s.logger.Error("Failed to convert item to note", zap.String("item_key", convertReq.ItemKey), zap.Error(err))
+		http.Error(w, fmt.Sprintf("Failed to convert item: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.Info("Item converted to note", zap.String("item_key", convertReq.ItemKey), zap.Int("note_id", note.ID))
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"note":   note,
+	})
+}
@@ -9,42 +9,138 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/prodemmi/kodo/core/entities"
 	"github.com/prodemmi/kodo/core/services"
 	"go.uber.org/zap"
 )
 
+// itemsMaxAge is how long item reads serve the current snapshot before
+// they rescan the project to pick up edits made outside kodo.
+const itemsMaxAge = 5 * time.Second
+
 type ItemHandler struct {
 	logger          *zap.Logger
 	scannerService  *services.ScannerService
 	historyService  *services.HistoryService
 	settingsService *services.SettingsService
+	remoteService   *services.RemoteService
 }
 
 func NewItemHandler(logger *zap.Logger,
 	scannerService *services.ScannerService,
 	historyService *services.HistoryService,
-	settingsService *services.SettingsService) *ItemHandler {
+	settingsService *services.SettingsService,
+	remoteService *services.RemoteService) *ItemHandler {
 	return &ItemHandler{
 		logger:          logger,
 		scannerService:  scannerService,
 		historyService:  historyService,
 		settingsService: settingsService,
+		remoteService:   remoteService,
 	}
 }
 
 func (s *ItemHandler) HandleItems(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	err := s.scannerService.Rescan()
 
+	if err := s.rescanItems(r); err != nil {
+		s.logger.Error("Failed to get items", zap.Error(err))
+		http.Error(w, "Failed to get items", http.StatusInternalServerError)
+		return
+	}
+
+	query, err := s.resolveItemQuery(r)
 	if err != nil {
+		s.logger.Warn("Invalid item query", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	board, ok := s.settingsService.GetBoard(r.URL.Query().Get("board"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "error",
+			"message": fmt.Sprintf("board %q not found", r.URL.Query().Get("board")),
+		})
+		return
+	}
+
+	items := s.scannerService.GetBoardItems(board)
+	if query.IsEmpty() {
+		_ = json.NewEncoder(w).Encode(items)
+		return
+	}
+
+	query = query.WithFirstSeen(s.historyService.GetFirstSeen())
+	_ = json.NewEncoder(w).Encode(query.Filter(items))
+}
+
+func (s *ItemHandler) HandleBoards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := s.rescanItems(r); err != nil {
 		s.logger.Error("Failed to get items", zap.Error(err))
 		http.Error(w, "Failed to get items", http.StatusInternalServerError)
 		return
 	}
 
-	_ = json.NewEncoder(w).Encode(s.scannerService.GetItems())
+	boards := []map[string]interface{}{}
+	for _, board := range s.settingsService.GetBoards() {
+		boards = append(boards, map[string]interface{}{
+			"id":           board.ID,
+			"name":         board.Name,
+			"columns":      board.Columns,
+			"path_filters": board.PathFilters,
+			"item_count":   len(s.scannerService.GetBoardItems(&board)),
+		})
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"boards": boards,
+		"count":  len(boards),
+	})
+}
+
+// rescanItems rescans the project when asked to with refresh=true or when
+// the items are older than itemsMaxAge.
+func (s *ItemHandler) rescanItems(r *http.Request) error {
+	if r.URL.Query().Get("refresh") == "true" {
+		return s.scannerService.Rescan()
+	}
+	return s.scannerService.RescanOlderThan(itemsMaxAge)
+}
+
+func (s *ItemHandler) resolveItemQuery(r *http.Request) (*services.ItemQuery, error) {
+	return parseRequestQuery(r, s.settingsService)
+}
+
+// parseRequestQuery combines the saved query named by ?saved= with the raw
+// query in ?q=.
+func parseRequestQuery(r *http.Request, settingsService *services.SettingsService) (*services.ItemQuery, error) {
+	raw := r.URL.Query().Get("q")
+
+	if name := r.URL.Query().Get("saved"); name != "" {
+		saved, ok := settingsService.GetSavedQuery(name)
+		if !ok {
+			return nil, fmt.Errorf("saved query %q not found", name)
+		}
+		raw = strings.TrimSpace(saved.Query + " " + raw)
+	}
+
+	return services.ParseItemQuery(raw)
 }
 
 func (s *ItemHandler) HandleUpdateTodo(w http.ResponseWriter, r *http.Request) {
@@ -55,6 +151,7 @@ func (s *ItemHandler) HandleUpdateTodo(w http.ResponseWriter, r *http.Request) {
 
 	var updateReq struct {
 		ID     int    `json:"id"`
+		Board  string `json:"board"`
 		Status string `json:"status"`
 	}
 
@@ -82,7 +179,11 @@ func (s *ItemHandler) HandleUpdateTodo(w http.ResponseWriter, r *http.Request) {
 
 	s.logger.Info("Found item", zap.String("file", targetItem.File), zap.Int("line", targetItem.Line), zap.String("current_status", string(targetItem.Status)))
 
-	err := s.scannerService.UpdateItemStatus(targetItem, updateReq.Status)
+	if updateReq.Board == "" {
+		updateReq.Board = entities.DefaultBoardID
+	}
+
+	err := s.scannerService.UpdateItemStatus(targetItem, updateReq.Board, updateReq.Status)
 
 	if err != nil {
 		s.logger.Error("Failed to update status", zap.Int("id", targetItem.ID), zap.String("status", updateReq.Status), zap.Error(err))
@@ -90,6 +191,10 @@ func (s *ItemHandler) HandleUpdateTodo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if updated := s.scannerService.GetItemByID(targetItem.ID); updated != nil && updated.Key == targetItem.Key {
+		targetItem = updated
+	}
+
 	if err := s.historyService.SaveStats(s.scannerService.GetItems(), s.settingsService); err != nil {
 		s.logger.Warn("Failed to save history after item update", zap.Error(err))
 	}
@@ -103,8 +208,138 @@ func (s *ItemHandler) HandleUpdateTodo(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (s *ItemHandler) HandleArchivedItems(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case "GET":
+		if r.URL.Query().Get("refresh") == "true" {
+			if err := s.scannerService.Rescan(); err != nil {
+				s.logger.Error("Failed to rescan before listing archived items", zap.Error(err))
+				http.Error(w, "Failed to scan items", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		archived := s.historyService.GetArchivedItems(r.URL.Query().Get("board"), entities.ItemRemoval(r.URL.Query().Get("removal")))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"items": archived,
+			"count": len(archived),
+		})
+
+	case "PUT":
+		var removalReq struct {
+			Key     string               `json:"key"`
+			Removal entities.ItemRemoval `json:"removal"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&removalReq); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+
+		lifecycle, err := s.historyService.SetItemRemoval(removalReq.Key, removalReq.Removal)
+		if err != nil {
+			s.logger.Error("Failed to update archived item", zap.String("key", removalReq.Key), zap.Error(err))
+			switch err.Error() {
+			case "item not found":
+				http.Error(w, "Item not found", http.StatusNotFound)
+			case "invalid removal type", "item is still in code":
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				http.Error(w, "Failed to update archived item", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"item":   lifecycle,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *ItemHandler) HandleItemIssues(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case "GET":
+		issues, err := s.remoteService.GetItemIssues()
+		if err != nil {
+			s.logger.Error("Failed to get item issues", zap.Error(err))
+			http.Error(w, "Failed to get item issues", http.StatusInternalServerError)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issues": issues,
+			"count":  len(issues),
+		})
+
+	case "POST":
+		var issueReq struct {
+			ItemID int `json:"item_id"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&issueReq); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+
+		item := s.scannerService.GetItemByID(issueReq.ItemID)
+		if item == nil {
+			http.Error(w, "Item not found", http.StatusNotFound)
+			return
+		}
+
+		itemIssue, err := s.remoteService.CreateIssueForItem(item)
+		if err != nil {
+			s.logger.Error("Failed to create issue for item", zap.Int("id", item.ID), zap.Error(err))
+			http.Error(w, fmt.Sprintf("Failed to create issue: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		if err := s.scannerService.Rescan(); err != nil {
+			s.logger.Warn("Failed to rescan after creating issue", zap.Error(err))
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"issue":  itemIssue,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *ItemHandler) HandleSyncItemIssues(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := s.remoteService.SyncItemIssues()
+	if err != nil {
+		s.logger.Error("Failed to sync item issues", zap.Error(err))
+		http.Error(w, fmt.Sprintf("Failed to sync item issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
 func (s *ItemHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
-	_ = s.scannerService.Rescan()
+	if err := s.scannerService.Rescan(); err != nil {
+		s.logger.Error("Failed to refresh items", zap.Error(err))
+		http.Error(w, fmt.Sprintf("Failed to refresh items: %v", err), http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
 		"status": "success",
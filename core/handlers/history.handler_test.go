@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/services"
+	"github.com/prodemmi/kodo/core/storage"
+	"go.uber.org/zap"
+)
+
+func newTestItemHandler(t *testing.T, files map[string]string) (*ItemHandler, *services.ScannerService) {
+	t.Helper()
+	dir := t.TempDir()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := entities.NewDefaultConfig()
+	config.Flags.Config = ".kodo"
+	logger := zap.NewNop()
+	store, err := storage.Open(config.Flags.Config, storage.BackendJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+
+	settings := services.NewSettingsService(config, logger)
+	history := services.NewHistoryService(config, logger, store)
+	scanner := services.NewScannerService(config, settings, history, logger)
+	return NewItemHandler(logger, scanner, history, settings, nil), scanner
+}
+
+func TestConcurrentItemReadsAndUpdates(t *testing.T) {
+	const files = 8
+	sources := map[string]string{}
+	for i := 0; i < files; i++ {
+		sources[fmt.Sprintf("f%d.go", i)] = fmt.Sprintf("package f\n\n// TODO: task %d\nfunc f() {}\n", i)
+	}
+	handler, scanner := newTestItemHandler(t, sources)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+
+	getItems := func(url string) {
+		w := httptest.NewRecorder()
+		handler.HandleItems(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("GET %s: status %d: %s", url, w.Code, w.Body.String())
+			return
+		}
+		var items []*entities.Item
+		if err := json.NewDecoder(w.Body).Decode(&items); err != nil {
+			t.Errorf("GET %s: %v", url, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, item := range scanner.GetItems() {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"id":%d,"board":%q,"status":"done"}`, item.ID, entities.DefaultBoardID)
+			w := httptest.NewRecorder()
+			handler.HandleUpdateTodo(w, httptest.NewRequest("PUT", "/api/items/update", strings.NewReader(body)))
+			if w.Code != http.StatusOK {
+				t.Errorf("update %s: status %d: %s", item.File, w.Code, w.Body.String())
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			getItems("/api/items")
+		}()
+		go func() {
+			defer wg.Done()
+			getItems("/api/items?refresh=true")
+		}()
+	}
+	wg.Wait()
+
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+	version := scanner.Snapshot().Version
+	getItems("/api/items")
+	if scanner.Snapshot().Version != version {
+		t.Error("GET /api/items rescanned a fresh snapshot")
+	}
+
+	for _, item := range scanner.GetItems() {
+		if item.Status != "done" {
+			t.Errorf("%s: got status %s", item.File, item.Status)
+		}
+	}
+}
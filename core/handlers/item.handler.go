@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/prodemmi/kodo/core/services"
 	"go.uber.org/zap"
@@ -32,11 +35,15 @@ func (s *HistoryHandler) HandleStats(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case "GET":
-		history := s.historyService.GetProjectStats(s.settingsService)
+		history := s.historyService.GetProjectStats(s.settingsService, r.URL.Query().Get("board"))
 		_ = json.NewEncoder(w).Encode(history)
 	case "POST":
-		_ = s.scannerService.Rescan()
-		history := s.historyService.GetProjectStats(s.settingsService)
+		if err := s.scannerService.Rescan(); err != nil {
+			s.logger.Error("Failed to refresh stats", zap.Error(err))
+			http.Error(w, fmt.Sprintf("Failed to refresh stats: %v", err), http.StatusInternalServerError)
+			return
+		}
+		history := s.historyService.GetProjectStats(s.settingsService, r.URL.Query().Get("board"))
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{
 			"status":  "refreshed",
 			"history": history,
@@ -69,10 +76,99 @@ func (s *HistoryHandler) HandleStatsCompare(w http.ResponseWriter, r *http.Reque
 
 	w.Header().Set("Content-Type", "application/json")
 
-	comparison := s.historyService.CompareWithPrevious(s.settingsService)
+	params := r.URL.Query()
+	comparison, err := s.historyService.Compare(s.settingsService, params.Get("board"), params.Get("from"), params.Get("to"))
+	if err != nil {
+		if errors.Is(err, services.ErrRefNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	_ = json.NewEncoder(w).Encode(comparison)
 }
 
+func (s *HistoryHandler) HandleStatsReleaseNotes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	format := params.Get("format")
+	if format != "" && format != "json" && format != "markdown" {
+		http.Error(w, "format must be json or markdown", http.StatusBadRequest)
+		return
+	}
+
+	notes, err := s.historyService.ReleaseNotes(s.settingsService, params.Get("from"), params.Get("to"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrRefNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if format == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(services.RenderReleaseNotesMarkdown(notes)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(notes)
+}
+
+func (s *HistoryHandler) HandleStatsExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	opts := services.ExportOptions{
+		Dataset: params.Get("dataset"),
+		Format:  params.Get("format"),
+		BoardID: params.Get("board"),
+	}
+	if opts.Format == "" {
+		opts.Format = services.ExportCSV
+	}
+
+	if err := opts.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := s.settingsService.GetBoard(opts.BoardID); !ok {
+		http.Error(w, fmt.Sprintf("board %q not found", opts.BoardID), http.StatusNotFound)
+		return
+	}
+
+	if s.scannerService.GetItemsLength() == 0 {
+		if err := s.scannerService.Rescan(); err != nil {
+			s.logger.Error("Failed to scan items for export", zap.Error(err))
+			http.Error(w, "Failed to scan items", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if opts.Format == services.ExportNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "kodo-"+opts.Dataset+"."+opts.Format))
+
+	if err := s.historyService.Export(w, s.settingsService, s.scannerService.GetItems(), opts); err != nil {
+		s.logger.Error("Failed to export history", zap.String("dataset", opts.Dataset), zap.Error(err))
+	}
+}
+
 func (s *HistoryHandler) HandleStatsCleanup(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -80,7 +176,7 @@ func (s *HistoryHandler) HandleStatsCleanup(w http.ResponseWriter, r *http.Reque
 	}
 
 	historyService := s.historyService
-	if err := historyService.CleanupOldStats(); err != nil {
+	if err := historyService.CleanupOldStats(s.settingsService); err != nil {
 		s.logger.Error("Failed to cleanup old history", zap.Error(err))
 		http.Error(w, "Failed to cleanup history", http.StatusInternalServerError)
 		return
@@ -89,7 +185,7 @@ func (s *HistoryHandler) HandleStatsCleanup(w http.ResponseWriter, r *http.Reque
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]string{
 		"status":  "success",
-		"message": "Old history cleaned up",
+		"message": "Old history compacted",
 	})
 }
 
@@ -119,6 +215,111 @@ func (s *HistoryHandler) HandleStatsItemsByFile(w http.ResponseWriter, r *http.R
 	_ = json.NewEncoder(w).Encode(fileGroups)
 }
 
+func (s *HistoryHandler) HandleStatsHotspots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	opts := services.HotspotOptions{BoardID: params.Get("board")}
+
+	if _, ok := s.settingsService.GetBoard(opts.BoardID); !ok {
+		http.Error(w, fmt.Sprintf("board %q not found", opts.BoardID), http.StatusNotFound)
+		return
+	}
+
+	for name, target := range map[string]*int{"window": &opts.Window, "limit": &opts.Limit, "depth": &opts.Depth} {
+		raw := params.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("invalid %s", name), http.StatusBadRequest)
+			return
+		}
+		*target = n
+	}
+
+	if s.scannerService.GetItemsLength() == 0 {
+		if err := s.scannerService.Rescan(); err != nil {
+			s.logger.Error("Failed to scan items for hotspots", zap.Error(err))
+			http.Error(w, "Failed to scan items", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	report, err := s.historyService.GetHotspots(s.settingsService, opts)
+	if err != nil {
+		s.logger.Error("Failed to build hotspot report", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+func (s *HistoryHandler) HandleStatsDebt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	params := r.URL.Query()
+	opts := services.DebtOptions{Path: params.Get("path")}
+
+	if raw := params.Get("depth"); raw != "" {
+		depth, err := strconv.Atoi(raw)
+		if err != nil || depth < 0 {
+			http.Error(w, "invalid depth", http.StatusBadRequest)
+			return
+		}
+		opts.Depth = depth
+	}
+
+	since, err := parseSprintDate(params.Get("since"), false)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid since date: %v", err), http.StatusBadRequest)
+		return
+	}
+	opts.Since = since
+
+	if s.scannerService.GetItemsLength() == 0 {
+		if err := s.scannerService.Rescan(); err != nil {
+			s.logger.Error("Failed to scan items for debt report", zap.Error(err))
+			http.Error(w, "Failed to scan items", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	report, err := s.historyService.GetDebtReport(s.settingsService, opts)
+	if err != nil {
+		s.logger.Error("Failed to build debt report", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+func (s *HistoryHandler) HandleStatsDebtHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	points := s.historyService.GetDebtHistory(s.settingsService, r.URL.Query().Get("path"))
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"points": points,
+		"count":  len(points),
+	})
+}
+
 func (s *HistoryHandler) HandleStatsTrends(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "GET" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -128,7 +329,7 @@ func (s *HistoryHandler) HandleStatsTrends(w http.ResponseWriter, r *http.Reques
 	w.Header().Set("Content-Type", "application/json")
 
 	historyService := s.historyService
-	trends := historyService.GetItemTrends(s.settingsService)
+	trends := historyService.GetItemTrends(s.settingsService, r.URL.Query().Get("board"))
 	_ = json.NewEncoder(w).Encode(trends)
 }
 
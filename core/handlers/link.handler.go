@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/services"
+	"go.uber.org/zap"
+)
+
+type LinkHandler struct {
+	logger         *zap.Logger
+	linkService    *services.LinkService
+	scannerService *services.ScannerService
+}
+
+func NewLinkHandler(logger *zap.Logger,
+	linkService *services.LinkService,
+	scannerService *services.ScannerService) *LinkHandler {
+	return &LinkHandler{
+		logger:         logger,
+		linkService:    linkService,
+		scannerService: scannerService,
+	}
+}
+
+func (s *LinkHandler) HandleLinks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.scannerService.GetItemsLength() == 0 {
+		if err := s.scannerService.Rescan(); err != nil {
+			s.logger.Error("Failed to scan items for links", zap.Error(err))
+			http.Error(w, "Failed to scan items", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	switch r.Method {
+	case "GET":
+		var noteID *int
+		if noteIdStr := r.URL.Query().Get("note_id"); noteIdStr != "" {
+			id, err := strconv.Atoi(noteIdStr)
+			if err != nil {
+				http.Error(w, "Invalid note ID", http.StatusBadRequest)
+				return
+			}
+			noteID = &id
+		}
+
+		itemKey := r.URL.Query().Get("item_key")
+		if itemIdStr := r.URL.Query().Get("item_id"); itemIdStr != "" && itemKey == "" {
+			itemID, _ := strconv.Atoi(itemIdStr)
+			item := s.scannerService.GetItemByID(itemID)
+			if item == nil {
+				http.Error(w, "Item not found", http.StatusNotFound)
+				return
+			}
+			itemKey = item.Key
+		}
+
+		links, err := s.linkService.GetLinks(noteID, itemKey)
+		if err != nil {
+			s.logger.Error("Failed to get links", zap.Error(err))
+			http.Error(w, "Failed to get links", http.StatusInternalServerError)
+			return
+		}
+
+		resolved, err := s.linkService.ResolveLinks(links, s.scannerService.GetItems())
+		if err != nil {
+			s.logger.Error("Failed to resolve links", zap.Error(err))
+			http.Error(w, "Failed to resolve links", http.StatusInternalServerError)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"links": resolved,
+			"count": len(resolved),
+		})
+
+	case "POST":
+		var linkReq struct {
+			NoteID  int               `json:"note_id"`
+			ItemID  int               `json:"item_id"`
+			ItemKey string            `json:"item_key"`
+			Type    entities.LinkType `json:"type"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&linkReq); err != nil {
+			s.logger.Error("Invalid JSON for link creation", zap.Error(err))
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+
+		if linkReq.Type == "" {
+			linkReq.Type = entities.LinkReferences
+		}
+
+		var item *entities.Item
+		if linkReq.ItemKey != "" {
+			item = s.scannerService.GetItemByKey(linkReq.ItemKey)
+		} else {
+			item = s.scannerService.GetItemByID(linkReq.ItemID)
+		}
+		if item == nil {
+			http.Error(w, "Item not found", http.StatusNotFound)
+			return
+		}
+
+		link, err := s.linkService.CreateLink(linkReq.NoteID, item, linkReq.Type)
+		if err != nil {
+			s.logger.Error("Failed to create link", zap.Int("note_id", linkReq.NoteID), zap.String("item_key", item.Key), zap.Error(err))
+			switch err.Error() {
+			case "note not found":
+				http.Error(w, "Note not found", http.StatusNotFound)
+			case "invalid link type":
+				http.Error(w, "Invalid link type", http.StatusBadRequest)
+			default:
+				http.Error(w, "Failed to create link", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		s.logger.Info("Link created", zap.Int("id", link.ID), zap.Int("note_id", link.NoteID), zap.String("item_key", link.ItemKey))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"link":   link,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *LinkHandler) HandleLinkDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var deleteReq struct {
+		ID int `json:"id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&deleteReq); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.linkService.DeleteLink(deleteReq.ID); err != nil {
+		s.logger.Error("Failed to delete link", zap.Int("id", deleteReq.ID), zap.Error(err))
+		if err.Error() == "link not found" {
+			http.Error(w, "Link not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete link", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Link deleted successfully",
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"sort"
 	"strconv"
 	"time"
 
@@ -380,23 +379,14 @@ func (s *NoteHandler) HandleNoteWithHistory(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	storage, err := s.noteService.LoadEnhancedNoteStorage()
+	note, err := s.noteService.GetNote(noteId)
 	if err != nil {
-		s.logger.Error("Failed to load note storage", zap.Error(err))
-		http.Error(w, "Failed to load notes", http.StatusInternalServerError)
-		return
-	}
-
-	var note *entities.Note
-	for _, n := range storage.Notes {
-		if n.ID == noteId {
-			note = &n
-			break
+		if err.Error() == "note not found" {
+			http.Error(w, "Note not found", http.StatusNotFound)
+			return
 		}
-	}
-
-	if note == nil {
-		http.Error(w, "Note not found", http.StatusNotFound)
+		s.logger.Error("Failed to load note", zap.Error(err))
+		http.Error(w, "Failed to load notes", http.StatusInternalServerError)
 		return
 	}
 
@@ -530,56 +520,20 @@ func (s *NoteHandler) HandleCleanupHistory(w http.ResponseWriter, r *http.Reques
 		req.KeepMinimum = 10
 	}
 
-	storage, err := s.noteService.LoadEnhancedNoteStorage()
+	removedCount, remainingCount, err := s.noteService.CleanupHistory(req.OlderThanDays, req.KeepMinimum)
 	if err != nil {
-		s.logger.Error("Failed to load note storage", zap.Error(err))
-		http.Error(w, "Failed to load notes", http.StatusInternalServerError)
-		return
-	}
-
-	cutoff := time.Now().AddDate(0, 0, -req.OlderThanDays)
-
-	noteHistory := make(map[int][]entities.NoteHistoryEntry)
-	for _, entry := range storage.History {
-		noteHistory[entry.NoteID] = append(noteHistory[entry.NoteID], entry)
-	}
-
-	var filteredHistory []entities.NoteHistoryEntry
-	removedCount := 0
-
-	for _, entries := range noteHistory {
-
-		sort.Slice(entries, func(i, j int) bool {
-			return entries[i].Timestamp.After(entries[j].Timestamp)
-		})
-
-		keptCount := 0
-		for _, entry := range entries {
-
-			if keptCount < req.KeepMinimum || entry.Timestamp.After(cutoff) {
-				filteredHistory = append(filteredHistory, entry)
-				keptCount++
-			} else {
-				removedCount++
-			}
-		}
-	}
-
-	storage.History = filteredHistory
-
-	if err := s.noteService.SaveEnhancedNoteStorage(storage); err != nil {
 		s.logger.Error("Failed to save cleaned history", zap.Error(err))
 		http.Error(w, "Failed to save changes", http.StatusInternalServerError)
 		return
 	}
 
-	s.logger.Info("History cleanup completed", zap.Int("removed_entries", removedCount), zap.Int("remaining_entries", len(filteredHistory)))
+	s.logger.Info("History cleanup completed", zap.Int("removed_entries", removedCount), zap.Int("remaining_entries", remainingCount))
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
 		"status":            "success",
 		"removed_entries":   removedCount,
-		"remaining_entries": len(filteredHistory),
+		"remaining_entries": remainingCount,
 		"message":           fmt.Sprintf("Removed %d old history entries", removedCount),
 	})
 }
@@ -629,8 +583,6 @@ func (s *NoteHandler) HandleSyncNotes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_ = s.noteService.SaveNoteStorage(s.noteService)
-
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(history)
 }
@@ -28,7 +28,12 @@ func (s *SettingHandler) HandleSettings(w http.ResponseWriter, r *http.Request)
 
 	switch r.Method {
 	case "GET":
-		settings := s.settingsService.LoadSettings()
+		settings, err := s.settingsService.LoadSettings()
+		if err != nil {
+			s.logger.Error("Failed to load settings", zap.Error(err))
+			http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+			return
+		}
 		_ = json.NewEncoder(w).Encode(settings)
 
 	default:
@@ -50,7 +55,12 @@ func (s *SettingHandler) HandleSettingsUpdate(w http.ResponseWriter, r *http.Req
 	}
 
 	s.logger.Info("Updating settings", zap.Any("updates", updateReq))
-	oldSettings := s.settingsService.LoadSettings()
+	oldSettings, err := s.settingsService.LoadSettings()
+	if err != nil {
+		s.logger.Error("Failed to load settings", zap.Error(err))
+		http.Error(w, "Failed to load settings", http.StatusInternalServerError)
+		return
+	}
 
 	updatedSettings, err := s.settingsService.UpdatePartialSettings(updateReq)
 	if err != nil {
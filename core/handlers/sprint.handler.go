@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/services"
+	"go.uber.org/zap"
+)
+
+type SprintHandler struct {
+	logger         *zap.Logger
+	sprintService  *services.SprintService
+	scannerService *services.ScannerService
+}
+
+func NewSprintHandler(logger *zap.Logger,
+	sprintService *services.SprintService,
+	scannerService *services.ScannerService) *SprintHandler {
+	return &SprintHandler{
+		logger:         logger,
+		sprintService:  sprintService,
+		scannerService: scannerService,
+	}
+}
+
+type sprintRequest struct {
+	ID        int                 `json:"id"`
+	Name      string              `json:"name"`
+	Kind      entities.SprintKind `json:"kind"`
+	Goal      string              `json:"goal"`
+	StartDate string              `json:"start_date"`
+	EndDate   string              `json:"end_date"`
+}
+
+func (req sprintRequest) dates() (time.Time, time.Time, error) {
+	start, err := parseSprintDate(req.StartDate, false)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date: %v", err)
+	}
+
+	end, err := parseSprintDate(req.EndDate, true)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end date: %v", err)
+	}
+
+	return start, end, nil
+}
+
+func parseSprintDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+
+	return t, nil
+}
+
+func (s *SprintHandler) writeSprintError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "sprint not found", "note not found", "item not assigned to sprint", "note not assigned to sprint":
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case "item already assigned to sprint":
+		http.Error(w, err.Error(), http.StatusConflict)
+	case "sprint name is required", "invalid sprint kind", "end date is required", "end date must be after start date":
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Failed to process sprint request", http.StatusInternalServerError)
+	}
+}
+
+func (s *SprintHandler) HandleSprints(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case "GET":
+		sprints, err := s.sprintService.GetSprints()
+		if err != nil {
+			s.logger.Error("Failed to get sprints", zap.Error(err))
+			http.Error(w, "Failed to get sprints", http.StatusInternalServerError)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"sprints": sprints,
+			"count":   len(sprints),
+		})
+
+	case "POST":
+		var sprintReq sprintRequest
+		if err := json.NewDecoder(r.Body).Decode(&sprintReq); err != nil {
+			http.Error(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+
+		start, end, err := sprintReq.dates()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		sprint, err := s.sprintService.CreateSprint(sprintReq.Name, sprintReq.Kind, sprintReq.Goal, start, end)
+		if err != nil {
+			s.logger.Error("Failed to create sprint", zap.String("name", sprintReq.Name), zap.Error(err))
+			s.writeSprintError(w, err)
+			return
+		}
+
+		s.logger.Info("Sprint created", zap.Int("id", sprint.ID), zap.String("name", sprint.Name))
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"sprint": sprint,
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *SprintHandler) HandleSprintUpdate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PUT" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var sprintReq sprintRequest
+	if err := json.NewDecoder(r.Body).Decode(&sprintReq); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	start, end, err := sprintReq.dates()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sprint, err := s.sprintService.UpdateSprint(sprintReq.ID, sprintReq.Name, sprintReq.Kind, sprintReq.Goal, start, end)
+	if err != nil {
+		s.logger.Error("Failed to update sprint", zap.Int("id", sprintReq.ID), zap.Error(err))
+		s.writeSprintError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"sprint": sprint,
+	})
+}
+
+func (s *SprintHandler) HandleSprintDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var deleteReq struct {
+		ID int `json:"id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&deleteReq); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.sprintService.DeleteSprint(deleteReq.ID); err != nil {
+		s.logger.Error("Failed to delete sprint", zap.Int("id", deleteReq.ID), zap.Error(err))
+		s.writeSprintError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"message": "Sprint deleted successfully",
+	})
+}
+
+func (s *SprintHandler) HandleSprintAssign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" && r.Method != "DELETE" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var assignReq struct {
+		SprintID int    `json:"sprint_id"`
+		ItemID   int    `json:"item_id"`
+		ItemKey  string `json:"item_key"`
+		NoteID   int    `json:"note_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&assignReq); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if s.scannerService.GetItemsLength() == 0 {
+		if err := s.scannerService.Rescan(); err != nil {
+			s.logger.Error("Failed to scan items for sprint assignment", zap.Error(err))
+			http.Error(w, "Failed to scan items", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	var sprint *entities.Sprint
+	var err error
+
+	switch {
+	case assignReq.NoteID != 0 && r.Method == "POST":
+		sprint, err = s.sprintService.AssignNote(assignReq.SprintID, assignReq.NoteID)
+	case assignReq.NoteID != 0:
+		sprint, err = s.sprintService.UnassignNote(assignReq.SprintID, assignReq.NoteID)
+	default:
+		var item *entities.Item
+		if assignReq.ItemKey != "" {
+			item = s.scannerService.GetItemByKey(assignReq.ItemKey)
+		} else {
+			item = s.scannerService.GetItemByID(assignReq.ItemID)
+		}
+
+		if r.Method == "DELETE" {
+			itemKey := assignReq.ItemKey
+			if item != nil {
+				itemKey = item.Key
+			}
+			sprint, err = s.sprintService.UnassignItem(assignReq.SprintID, itemKey)
+			break
+		}
+
+		if item == nil {
+			http.Error(w, "Item not found", http.StatusNotFound)
+			return
+		}
+		sprint, err = s.sprintService.AssignItem(assignReq.SprintID, item)
+	}
+
+	if err != nil {
+		s.logger.Error("Failed to update sprint assignment", zap.Int("sprint_id", assignReq.SprintID), zap.Error(err))
+		s.writeSprintError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"sprint": sprint,
+	})
+}
+
+func (s *SprintHandler) HandleSprintReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid sprint ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.scannerService.Rescan(); err != nil {
+		s.logger.Error("Failed to rescan before sprint report", zap.Error(err))
+		http.Error(w, "Failed to scan items", http.StatusInternalServerError)
+		return
+	}
+
+	report, err := s.sprintService.GetSprintReport(id, s.scannerService.GetItems())
+	if err != nil {
+		s.logger.Error("Failed to build sprint report", zap.Int("id", id), zap.Error(err))
+		s.writeSprintError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(report)
+}
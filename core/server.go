@@ -21,11 +21,17 @@ type Server struct {
 	staticFiles    embed.FS
 	logger         *zap.Logger
 
-	noteHandler     *handlers.NoteHandler
-	historyHandler  *handlers.HistoryHandler
-	chatHandler     *handlers.ChatHandler
-	settingsHandler *handlers.SettingHandler
-	itemHandler     *handlers.ItemHandler
+	noteHandler       *handlers.NoteHandler
+	historyHandler    *handlers.HistoryHandler
+	chatHandler       *handlers.ChatHandler
+	settingsHandler   *handlers.SettingHandler
+	itemHandler       *handlers.ItemHandler
+	commentHandler    *handlers.CommentHandler
+	linkHandler       *handlers.LinkHandler
+	convertHandler    *handlers.ConvertHandler
+	sprintHandler     *handlers.SprintHandler
+	chartHandler      *handlers.ChartHandler
+	automationHandler *handlers.AutomationHandler
 }
 
 func NewServer(
@@ -36,19 +42,31 @@ func NewServer(
 	chatHandler *handlers.ChatHandler,
 	settingsHandler *handlers.SettingHandler,
 	itemHandler *handlers.ItemHandler,
+	commentHandler *handlers.CommentHandler,
+	linkHandler *handlers.LinkHandler,
+	convertHandler *handlers.ConvertHandler,
+	sprintHandler *handlers.SprintHandler,
+	chartHandler *handlers.ChartHandler,
+	automationHandler *handlers.AutomationHandler,
 	staticFiles embed.FS,
 	scannerService *services.ScannerService,
 ) *Server {
 	return &Server{
-		config:          config,
-		staticFiles:     staticFiles,
-		scannerService:  scannerService,
-		logger:          logger,
-		noteHandler:     noteHandler,
-		historyHandler:  historyHandler,
-		chatHandler:     chatHandler,
-		settingsHandler: settingsHandler,
-		itemHandler:     itemHandler,
+		config:            config,
+		staticFiles:       staticFiles,
+		scannerService:    scannerService,
+		logger:            logger,
+		noteHandler:       noteHandler,
+		historyHandler:    historyHandler,
+		chatHandler:       chatHandler,
+		settingsHandler:   settingsHandler,
+		itemHandler:       itemHandler,
+		commentHandler:    commentHandler,
+		linkHandler:       linkHandler,
+		convertHandler:    convertHandler,
+		sprintHandler:     sprintHandler,
+		chartHandler:      chartHandler,
+		automationHandler: automationHandler,
 	}
 }
 
@@ -61,6 +79,10 @@ func (s *Server) Start() {
 	s.registerNoteRoutes(mux)
 	s.registerHistoryRoutes(mux)
 	s.registerSettingsRoutes(mux)
+	s.registerLinkRoutes(mux)
+	s.registerSprintRoutes(mux)
+	s.registerChartRoutes(mux)
+	s.registerAutomationRoutes(mux)
 	s.registerMiscRoutes(mux)
 
 	port := s.config.Flags.Port
@@ -156,9 +178,20 @@ func (s *Server) handleInvestor(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) registerItemRoutes(mux *http.ServeMux) {
 	mux.Handle("/api/items", s.withCORS(http.HandlerFunc(s.itemHandler.HandleItems)))
+	mux.Handle("/api/boards", s.withCORS(http.HandlerFunc(s.itemHandler.HandleBoards)))
 	mux.Handle("/api/items/update", s.withCORS(http.HandlerFunc(s.itemHandler.HandleUpdateTodo)))
+	mux.Handle("/api/items/archived", s.withCORS(http.HandlerFunc(s.itemHandler.HandleArchivedItems)))
 	mux.Handle("/api/items/open-file", s.withCORS(http.HandlerFunc(s.itemHandler.HandleOpenFile)))
 	mux.Handle("/api/items/get-context", s.withCORS(http.HandlerFunc(s.itemHandler.HandleGetContext)))
+
+	mux.Handle("/api/items/comments", s.withCORS(http.HandlerFunc(s.commentHandler.HandleComments)))
+	mux.Handle("/api/items/comments/update", s.withCORS(http.HandlerFunc(s.commentHandler.HandleCommentUpdate)))
+	mux.Handle("/api/items/comments/delete", s.withCORS(http.HandlerFunc(s.commentHandler.HandleCommentDelete)))
+
+	mux.Handle("/api/items/issues", s.withCORS(http.HandlerFunc(s.itemHandler.HandleItemIssues)))
+	mux.Handle("/api/items/issues/sync", s.withCORS(http.HandlerFunc(s.itemHandler.HandleSyncItemIssues)))
+
+	mux.Handle("/api/items/to-note", s.withCORS(http.HandlerFunc(s.convertHandler.HandleItemToNote)))
 }
 
 func (s *Server) registerNoteRoutes(mux *http.ServeMux) {
@@ -170,6 +203,7 @@ func (s *Server) registerNoteRoutes(mux *http.ServeMux) {
 	mux.Handle("/api/notes/export", s.withCORS(http.HandlerFunc(s.noteHandler.HandleExportNotes)))
 	mux.Handle("/api/notes/tags", s.withCORS(http.HandlerFunc(s.noteHandler.HandleNoteTags)))
 	mux.Handle("/api/notes/sync", s.withCORS(http.HandlerFunc(s.noteHandler.HandleSyncNotes)))
+	mux.Handle("/api/notes/to-item", s.withCORS(http.HandlerFunc(s.convertHandler.HandleNoteToItem)))
 
 	mux.Handle("/api/notes/history", s.withCORS(http.HandlerFunc(s.noteHandler.HandleNoteHistory)))
 	mux.Handle("/api/notes/history/history", s.withCORS(http.HandlerFunc(s.noteHandler.HandleNoteHistoryStats)))
@@ -190,9 +224,14 @@ func (s *Server) registerHistoryRoutes(mux *http.ServeMux) {
 	mux.Handle("/api/history", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStats)))
 	mux.Handle("/api/history/history", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsHistory)))
 	mux.Handle("/api/history/compare", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsCompare)))
+	mux.Handle("/api/history/release-notes", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsReleaseNotes)))
+	mux.Handle("/api/history/export", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsExport)))
 	mux.Handle("/api/history/cleanup", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsCleanup)))
 	mux.Handle("/api/history/items", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsItems)))
 	mux.Handle("/api/history/items/by-file", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsItemsByFile)))
+	mux.Handle("/api/history/hotspots", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsHotspots)))
+	mux.Handle("/api/history/debt", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsDebt)))
+	mux.Handle("/api/history/debt/history", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsDebtHistory)))
 	mux.Handle("/api/history/trends", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsTrends)))
 	mux.Handle("/api/history/changes", s.withCORS(http.HandlerFunc(s.historyHandler.HandleStatsChanges)))
 }
@@ -202,6 +241,31 @@ func (s *Server) registerSettingsRoutes(mux *http.ServeMux) {
 	mux.Handle("/api/settings/update", s.withCORS(http.HandlerFunc(s.settingsHandler.HandleSettingsUpdate)))
 }
 
+func (s *Server) registerLinkRoutes(mux *http.ServeMux) {
+	mux.Handle("/api/links", s.withCORS(http.HandlerFunc(s.linkHandler.HandleLinks)))
+	mux.Handle("/api/links/delete", s.withCORS(http.HandlerFunc(s.linkHandler.HandleLinkDelete)))
+}
+
+func (s *Server) registerSprintRoutes(mux *http.ServeMux) {
+	mux.Handle("/api/sprints", s.withCORS(http.HandlerFunc(s.sprintHandler.HandleSprints)))
+	mux.Handle("/api/sprints/update", s.withCORS(http.HandlerFunc(s.sprintHandler.HandleSprintUpdate)))
+	mux.Handle("/api/sprints/delete", s.withCORS(http.HandlerFunc(s.sprintHandler.HandleSprintDelete)))
+	mux.Handle("/api/sprints/assign", s.withCORS(http.HandlerFunc(s.sprintHandler.HandleSprintAssign)))
+	mux.Handle("/api/sprints/report", s.withCORS(http.HandlerFunc(s.sprintHandler.HandleSprintReport)))
+}
+
+func (s *Server) registerChartRoutes(mux *http.ServeMux) {
+	mux.Handle("/api/charts/burndown", s.withCORS(http.HandlerFunc(s.chartHandler.HandleBurndown)))
+	mux.Handle("/api/charts/burnup", s.withCORS(http.HandlerFunc(s.chartHandler.HandleBurnup)))
+	mux.Handle("/api/charts/cfd", s.withCORS(http.HandlerFunc(s.chartHandler.HandleCumulativeFlow)))
+	mux.Handle("/api/charts/forecast", s.withCORS(http.HandlerFunc(s.chartHandler.HandleForecast)))
+}
+
+func (s *Server) registerAutomationRoutes(mux *http.ServeMux) {
+	mux.Handle("/api/automation/preview", s.withCORS(http.HandlerFunc(s.automationHandler.HandlePreview)))
+	mux.Handle("/api/automation/log", s.withCORS(http.HandlerFunc(s.automationHandler.HandleLog)))
+}
+
 // func (s *Server) registerChatRoutes(mux *http.ServeMux) {
 // 	mux.Handle("/api/chat/project-files", s.withCORS(http.HandlerFunc(s.chatHandler.HandleProjectFiles)))
 // }
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
+	"go.uber.org/zap"
+)
+
+const maxAutomationLog = 1000
+
+type AutomationService struct {
+	config         *entities.Config
+	logger         *zap.Logger
+	settings       *SettingsService
+	historyService *HistoryService
+	scannerService *ScannerService
+}
+
+func NewAutomationService(config *entities.Config, logger *zap.Logger, settings *SettingsService, historyService *HistoryService, scannerService *ScannerService) *AutomationService {
+	return &AutomationService{
+		config:         config,
+		logger:         logger,
+		settings:       settings,
+		historyService: historyService,
+		scannerService: scannerService,
+	}
+}
+
+func (s *AutomationService) getAutomationFilePath() string {
+	wd, _ := os.Getwd()
+	return filepath.Join(wd, s.config.Flags.Config, "automation.json")
+}
+
+func (s *AutomationService) loadAutomationStorage() (*entities.AutomationStorage, error) {
+	data, err := os.ReadFile(s.getAutomationFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &entities.AutomationStorage{
+				Stale: map[string]entities.StaleFlag{},
+				Log:   []entities.AutomationChange{},
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to read automation file: %v", err)
+	}
+
+	var storage entities.AutomationStorage
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal automation: %v", err)
+	}
+
+	if storage.Stale == nil {
+		storage.Stale = map[string]entities.StaleFlag{}
+	}
+	if storage.Log == nil {
+		storage.Log = []entities.AutomationChange{}
+	}
+
+	return &storage, nil
+}
+
+func (s *AutomationService) saveAutomationStorage(automation *entities.AutomationStorage) error {
+	if len(automation.Log) > maxAutomationLog {
+		automation.Log = automation.Log[len(automation.Log)-maxAutomationLog:]
+	}
+
+	data, err := json.MarshalIndent(automation, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal automation: %v", err)
+	}
+
+	if err := storage.WriteFile(s.getAutomationFilePath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write automation file: %v", err)
+	}
+
+	return nil
+}
+
+// updateAutomationStorage runs fn on the automation state while holding the
+// lock of automation.json and saves it, unless fn returns an error or
+// errUnchanged.
+func (s *AutomationService) updateAutomationStorage(fn func(automation *entities.AutomationStorage) error) error {
+	return storage.WithFileLock(s.getAutomationFilePath(), func() error {
+		automation, err := s.loadAutomationStorage()
+		if err != nil {
+			return err
+		}
+
+		if err := fn(automation); err != nil {
+			if errors.Is(err, errUnchanged) {
+				return nil
+			}
+			return err
+		}
+		return s.saveAutomationStorage(automation)
+	})
+}
+
+// Reconcile runs the enabled rules after a rescan.
+func (s *AutomationService) Reconcile(items []*entities.Item) error {
+	_, err := s.Evaluate(items, "", false)
+	return err
+}
+
+// Evaluate matches the rules against items. With dryRun nothing is written
+// and the report lists what would change. An empty ruleID evaluates the
+// enabled rules; a rule asked for by ID is evaluated even when disabled.
+func (s *AutomationService) Evaluate(items []*entities.Item, ruleID string, dryRun bool) (*entities.AutomationReport, error) {
+	settings, err := s.settings.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []entities.AutomationRule{}
+	for _, rule := range settings.AutomationRules {
+		if (ruleID == "" && rule.Enabled) || rule.ID == ruleID {
+			rules = append(rules, rule)
+		}
+	}
+	if ruleID != "" && len(rules) == 0 {
+		return nil, fmt.Errorf("automation rule not found")
+	}
+
+	now := time.Now()
+	report := &entities.AutomationReport{
+		DryRun:      dryRun,
+		EvaluatedAt: now,
+		Rules:       len(rules),
+		Changes:     []entities.AutomationChange{},
+	}
+
+	firstSeen := make(map[string]time.Time)
+	for _, lifecycle := range s.historyService.GetLifecycles() {
+		firstSeen[lifecycle.Key] = lifecycle.FirstSeenAt
+	}
+	lastCommits := make(map[string]time.Time)
+
+	escalations := make(map[*entities.Item]entities.AutomationRule)
+	flagged := make(map[string]entities.AutomationRule)
+	for _, rule := range rules {
+		query, err := ParseItemQuery(rule.Query)
+		if err != nil {
+			s.logger.Warn("Skipping automation rule with invalid query", zap.String("rule", rule.ID), zap.Error(err))
+			continue
+		}
+		query = query.WithFirstSeen(firstSeen)
+
+		for _, item := range items {
+			if !query.Match(item) {
+				continue
+			}
+
+			seen, ok := firstSeen[item.Key]
+			if !ok {
+				seen = itemFirstSeen(item)
+			}
+			if daysSince(itemLastTouched(item, seen), now) < rule.UntouchedDays {
+				continue
+			}
+
+			if rule.NoCommitDays > 0 {
+				lastCommit, ok := lastCommits[item.File]
+				if !ok {
+					lastCommit = fileLastCommit(item.File)
+					lastCommits[item.File] = lastCommit
+				}
+				if lastCommit.IsZero() {
+					lastCommit = seen
+				}
+				if daysSince(lastCommit, now) < rule.NoCommitDays {
+					continue
+				}
+			}
+
+			switch rule.Action {
+			case entities.AutomationEscalate:
+				if priorityRank(rule.Priority) <= priorityRank(item.Priority) {
+					continue
+				}
+				if current, ok := escalations[item]; !ok || priorityRank(rule.Priority) > priorityRank(current.Priority) {
+					escalations[item] = rule
+				}
+			case entities.AutomationFlagStale:
+				if _, ok := flagged[item.Key]; !ok {
+					flagged[item.Key] = rule
+				}
+			}
+		}
+	}
+
+	// Later items first, so rewriting a comment never shifts the line of
+	// an item that is still to be rewritten.
+	escalated := make([]*entities.Item, 0, len(escalations))
+	for item := range escalations {
+		escalated = append(escalated, item)
+	}
+	slices.SortFunc(escalated, func(a, b *entities.Item) int {
+		if c := strings.Compare(a.File, b.File); c != 0 {
+			return c
+		}
+		return b.Line - a.Line
+	})
+
+	for _, item := range escalated {
+		rule := escalations[item]
+		change := newAutomationChange(rule, entities.AutomationEscalate, item, now)
+		change.From = string(item.Priority)
+		change.To = string(rule.Priority)
+
+		if !dryRun {
+			if err := s.scannerService.SetItemPriority(item, rule.Priority); err != nil {
+				change.Error = err.Error()
+				s.logger.Warn("Failed to escalate item", zap.String("rule", rule.ID), zap.String("file", item.File), zap.Int("line", item.Line), zap.Error(err))
+			} else {
+				s.logger.Info("Escalated item", zap.String("rule", rule.ID), zap.String("file", item.File), zap.Int("line", item.Line), zap.String("priority", change.To))
+			}
+		}
+		report.Changes = append(report.Changes, change)
+	}
+
+	evaluated := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		evaluated[rule.ID] = true
+	}
+
+	var stale map[string]entities.StaleFlag
+	err = s.updateAutomationStorage(func(automation *entities.AutomationStorage) error {
+		for _, item := range items {
+			rule, isStale := flagged[item.Key]
+			flag, wasStale := automation.Stale[item.Key]
+
+			switch {
+			case isStale && !wasStale:
+				report.Changes = append(report.Changes, newAutomationChange(rule, entities.AutomationFlagStale, item, now))
+				if !dryRun {
+					automation.Stale[item.Key] = entities.StaleFlag{RuleID: rule.ID, FlaggedAt: now}
+					s.logger.Info("Flagged stale item", zap.String("rule", rule.ID), zap.String("file", item.File), zap.Int("line", item.Line))
+				}
+			case !isStale && wasStale:
+				// A full run also clears flags of rules that were removed or
+				// disabled; a single-rule run only touches its own flags.
+				if ruleID != "" && !evaluated[flag.RuleID] {
+					continue
+				}
+				unflag := entities.AutomationRule{ID: flag.RuleID, Name: flag.RuleID}
+				for _, r := range settings.AutomationRules {
+					if r.ID == flag.RuleID {
+						unflag = r
+					}
+				}
+				report.Changes = append(report.Changes, newAutomationChange(unflag, entities.AutomationUnflagStale, item, now))
+				if !dryRun {
+					delete(automation.Stale, item.Key)
+				}
+			}
+		}
+
+		if dryRun {
+			return errUnchanged
+		}
+
+		present := make(map[string]bool, len(items))
+		for _, item := range items {
+			present[item.Key] = true
+		}
+		for key := range automation.Stale {
+			if !present[key] {
+				delete(automation.Stale, key)
+			}
+		}
+
+		automation.Log = append(automation.Log, report.Changes...)
+		stale = automation.Stale
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	err = s.scannerService.SetStale(func(key string) bool {
+		_, ok := stale[key]
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetLog returns the most recent automated changes, newest first.
+func (s *AutomationService) GetLog(limit int) ([]entities.AutomationChange, error) {
+	storage, err := s.loadAutomationStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	log := slices.Clone(storage.Log)
+	slices.Reverse(log)
+	if limit > 0 && len(log) > limit {
+		log = log[:limit]
+	}
+
+	return log, nil
+}
+
+func newAutomationChange(rule entities.AutomationRule, action entities.AutomationAction, item *entities.Item, now time.Time) entities.AutomationChange {
+	return entities.AutomationChange{
+		RuleID:    rule.ID,
+		RuleName:  rule.Name,
+		Action:    action,
+		ItemKey:   item.Key,
+		Title:     item.Title,
+		File:      item.File,
+		Line:      item.Line,
+		Timestamp: now,
+	}
+}
+
+func priorityRank(priority entities.ItemPriority) int {
+	switch priority {
+	case "LOW":
+		return 0
+	case "MEDIUM":
+		return 1
+	case "HIGH":
+		return 2
+	}
+	return -1
+}
+
+// itemLastTouched is the latest status change of an item, or when it was
+// first seen. The scanner stamps items without status lines with the scan
+// time, so only entries before that count.
+func itemLastTouched(item *entities.Item, firstSeen time.Time) time.Time {
+	touched := firstSeen
+	for _, h := range item.History {
+		if h.Timestamp.After(touched) && h.Timestamp.Before(item.CreatedAt) {
+			touched = h.Timestamp
+		}
+	}
+	return touched
+}
+
+func daysSince(t, now time.Time) int {
+	return int(now.Sub(t) / (24 * time.Hour))
+}
+
+// fileLastCommit returns the time of the last commit touching file, or the
+// zero time when it was never committed.
+func fileLastCommit(file string) time.Time {
+	out, err := exec.Command("git", "log", "-1", "--format=%ct", "--", file).Output()
+	if err != nil {
+		return time.Time{}
+	}
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0)
+}
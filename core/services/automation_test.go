@@ -0,0 +1,171 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"go.uber.org/zap"
+)
+
+func newTestAutomation(t *testing.T, scanner *ScannerService, rules ...entities.AutomationRule) *AutomationService {
+	t.Helper()
+	setAutomationRules(t, scanner, rules...)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+	return NewAutomationService(scanner.historyService.config, zap.NewNop(), scanner.settings, scanner.historyService, scanner)
+}
+
+func setAutomationRules(t *testing.T, scanner *ScannerService, rules ...entities.AutomationRule) {
+	t.Helper()
+	_, err := scanner.settings.UpdateSettings(func(settings *entities.Settings) error {
+		settings.AutomationRules = rules
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutomationDryRunWritesNothing(t *testing.T) {
+	source := "package a\n\n// TODO: old work\nfunc a() {}\n"
+	config := newTestProject(t, map[string]string{"a.go": source})
+	scanner := newTestScanner(t, config)
+	automation := newTestAutomation(t, scanner,
+		entities.AutomationRule{ID: "escalate", Enabled: true, Action: entities.AutomationEscalate, Priority: "HIGH"},
+		entities.AutomationRule{ID: "stale", Enabled: true, Action: entities.AutomationFlagStale},
+	)
+
+	report, err := automation.Evaluate(scanner.GetItems(), "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || len(report.Changes) != 2 {
+		t.Fatalf("report = %+v, want a dry run with an escalation and a flag", report)
+	}
+
+	if got := readTestFile(t, "a.go"); got != source {
+		t.Errorf("dry run rewrote a.go:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(config.Flags.Config, "automation.json")); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote automation.json: %v", err)
+	}
+	item := scanner.GetItems()[0]
+	if item.Stale || item.Priority == "HIGH" {
+		t.Errorf("dry run changed the item: %+v", item)
+	}
+}
+
+func TestAutomationEscalatesItemsOfOneFile(t *testing.T) {
+	config := newTestProject(t, map[string]string{
+		"a.go": "package a\n\n// TODO: first\nfunc a() {}\n\n// TODO: second\n// LOW\nfunc b() {}\n\n// TODO: third\nfunc c() {}\n",
+	})
+	scanner := newTestScanner(t, config)
+	automation := newTestAutomation(t, scanner,
+		entities.AutomationRule{ID: "escalate", Enabled: true, Action: entities.AutomationEscalate, Priority: "HIGH"},
+	)
+
+	report, err := automation.Evaluate(scanner.GetItems(), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changes) != 3 {
+		t.Fatalf("got %d changes, want 3", len(report.Changes))
+	}
+	for i, want := range []string{"third", "second", "first"} {
+		if change := report.Changes[i]; change.Title != want || change.Error != "" {
+			t.Errorf("change %d = %q (error %q), want %q escalated bottom up", i, change.Title, change.Error, want)
+		}
+	}
+
+	want := "package a\n\n// TODO: first\n// HIGH\nfunc a() {}\n\n// TODO: second\n// HIGH\nfunc b() {}\n\n// TODO: third\n// HIGH\nfunc c() {}\n"
+	if got := readTestFile(t, "a.go"); got != want {
+		t.Errorf("a.go =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range scanner.GetItems() {
+		if item.Priority != "HIGH" {
+			t.Errorf("item %q has priority %q after a rescan, want HIGH", item.Title, item.Priority)
+		}
+	}
+}
+
+func TestAutomationUnflagsItemsOfRemovedRules(t *testing.T) {
+	config := newTestProject(t, map[string]string{
+		"a.go": "package a\n\n// TODO: old work\nfunc a() {}\n",
+	})
+	scanner := newTestScanner(t, config)
+	automation := newTestAutomation(t, scanner,
+		entities.AutomationRule{ID: "stale", Name: "Stale", Enabled: true, Action: entities.AutomationFlagStale},
+	)
+
+	if _, err := automation.Evaluate(scanner.GetItems(), "", false); err != nil {
+		t.Fatal(err)
+	}
+	if !scanner.GetItems()[0].Stale {
+		t.Fatal("item was not flagged stale")
+	}
+
+	setAutomationRules(t, scanner)
+	report, err := automation.Evaluate(scanner.GetItems(), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Action != entities.AutomationUnflagStale || report.Changes[0].RuleID != "stale" {
+		t.Fatalf("changes = %+v, want the flag of the removed rule cleared", report.Changes)
+	}
+	if scanner.GetItems()[0].Stale {
+		t.Error("item is still stale after its rule was removed")
+	}
+
+	stored, err := automation.loadAutomationStorage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Stale) != 0 {
+		t.Errorf("stale flags = %+v, want none", stored.Stale)
+	}
+}
+
+func TestAutomationNoCommitDays(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	config := newTestProject(t, map[string]string{
+		"old.go": "package a\n\n// TODO: old work\nfunc a() {}\n",
+	})
+	runGit(t, "init", "-q")
+	runGit(t, "add", "old.go")
+	t.Setenv("GIT_COMMITTER_DATE", time.Now().AddDate(0, 0, -30).Format(time.RFC3339))
+	runGit(t, "commit", "-qm", "add old work")
+	t.Setenv("GIT_COMMITTER_DATE", "")
+
+	writeTestFile(t, "new.go", "package a\n\n// TODO: new work\nfunc b() {}\n")
+	runGit(t, "add", "new.go")
+	runGit(t, "commit", "-qm", "add new work")
+
+	// Never committed: the file counts as untouched since the item was
+	// first seen.
+	writeTestFile(t, "draft.go", "package a\n\n// TODO: draft work\nfunc c() {}\n")
+
+	scanner := newTestScanner(t, config)
+	automation := newTestAutomation(t, scanner,
+		entities.AutomationRule{ID: "stale", Enabled: true, Action: entities.AutomationFlagStale, NoCommitDays: 7},
+	)
+
+	report, err := automation.Evaluate(scanner.GetItems(), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].File != "old.go" {
+		t.Fatalf("changes = %+v, want only the item of old.go flagged", report.Changes)
+	}
+}
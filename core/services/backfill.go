@@ -0,0 +1,391 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"go.uber.org/zap"
+)
+
+type BackfillOptions struct {
+	Branch string
+	Every  int
+	Limit  int
+	Since  time.Time
+}
+
+type BackfillResult struct {
+	Branch  string `json:"branch"`
+	Commits int    `json:"commits"`
+	Scanned int    `json:"scanned"`
+	Added   int    `json:"added"`
+	Updated int    `json:"updated"`
+	Skipped int    `json:"skipped"`
+}
+
+type backfillCommit struct {
+	Hash      string
+	Short     string
+	Author    string
+	Message   string
+	Timestamp time.Time
+}
+
+type treeEntry struct {
+	Blob string
+	Size int64
+	Path string
+}
+
+// Backfill replays the first-parent history of a branch and records a
+// snapshot for every sampled commit, reading files straight from the object
+// database so the working tree is never touched.
+func (s *ScannerService) Backfill(opts BackfillOptions) (*BackfillResult, error) {
+	if opts.Branch == "" {
+		opts.Branch = s.historyService.GetGitBranch()
+	}
+
+	history, err := s.historyService.LoadStats()
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		if err := s.Rescan(); err != nil {
+			return nil, err
+		}
+	}
+
+	commits, err := listBackfillCommits(opts.Branch, opts.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BackfillResult{Branch: opts.Branch, Commits: len(commits)}
+	commits = sampleBackfillCommits(commits, opts.Every, opts.Limit)
+
+	blobs, err := newBlobReader()
+	if err != nil {
+		return nil, err
+	}
+	defer blobs.Close()
+
+	settings, err := s.settings.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := newItemScanner(settings)
+	cache := make(map[string][]*entities.Item)
+	snapshots := make([]entities.BranchSnapshot, 0, len(commits))
+	firstSeen := make(map[string]time.Time)
+
+	for _, commit := range commits {
+		items, err := scanCommitItems(scanner, blobs, cache, commit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			if _, ok := firstSeen[item.Key]; !ok {
+				firstSeen[item.Key] = commit.Timestamp
+			}
+		}
+
+		snapshot := entities.BranchSnapshot{
+			Branch:        opts.Branch,
+			Commit:        commit.Hash,
+			CommitShort:   commit.Short,
+			CommitMessage: commit.Message,
+			Timestamp:     commit.Timestamp,
+			History:       s.historyService.generateItemStats(items, settings),
+			Boards:        s.historyService.generateBoardStats(items, settings),
+			Backfilled:    true,
+		}
+		snapshot.Debt = snapshotDebt(snapshot.History.Items, firstSeen, commit.Timestamp, scanner.settings)
+		snapshots = append(snapshots, snapshot)
+		result.Scanned++
+
+		s.logger.Debug("Backfilled commit",
+			zap.String("commit", commit.Short),
+			zap.Int("items", len(items)))
+	}
+
+	result.Added, result.Updated, result.Skipped, err = s.historyService.MergeBackfill(snapshots, s.settings)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("History backfill finished",
+		zap.String("branch", result.Branch),
+		zap.Int("scanned", result.Scanned),
+		zap.Int("added", result.Added),
+		zap.Int("updated", result.Updated),
+		zap.Int("skipped", result.Skipped))
+
+	return result, nil
+}
+
+// MergeBackfill adds backfilled snapshots to the history. Commits that were
+// already scanned live are kept as they are; earlier backfills are replaced.
+// The merged history is compacted with the retention policy, so backfilled
+// snapshots older than FullDays end up in daily and weekly rollups without
+// their items.
+func (pt *HistoryService) MergeBackfill(snapshots []entities.BranchSnapshot, settings *SettingsService) (added, updated, skipped int, err error) {
+	currentSettings, err := settings.LoadSettings()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	err = pt.store.UpdateStats(func(history *entities.ItemsHistory) error {
+		if history.CreatedAt.IsZero() {
+			return errNoHistory
+		}
+
+		existing := make(map[string]int, len(history.BranchHistory))
+		for i, snapshot := range history.BranchHistory {
+			existing[snapshot.Commit] = i
+		}
+
+		for _, snapshot := range snapshots {
+			if i, ok := existing[snapshot.Commit]; ok {
+				if history.BranchHistory[i].Backfilled {
+					history.BranchHistory[i] = snapshot
+					updated++
+				} else {
+					skipped++
+				}
+				continue
+			}
+			existing[snapshot.Commit] = len(history.BranchHistory)
+			history.BranchHistory = append(history.BranchHistory, snapshot)
+			added++
+		}
+
+		history.BranchHistory = compactSnapshots(history.BranchHistory, currentSettings.HistoryRetention, currentSettings, time.Now())
+
+		if len(history.BranchHistory) > 0 && history.BranchHistory[0].Timestamp.Before(history.CreatedAt) {
+			history.CreatedAt = history.BranchHistory[0].Timestamp
+		}
+		history.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return added, updated, skipped, nil
+}
+
+// scanCommitItems parses the items of every file in a commit. Results are
+// cached per blob and path, since most files do not change between commits.
+func scanCommitItems(scanner *itemScanner, blobs *blobReader, cache map[string][]*entities.Item, commit backfillCommit) ([]*entities.Item, error) {
+	entries, err := listTree(commit.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	author := commit.Author
+	items := []*entities.Item{}
+	for _, entry := range entries {
+		if entry.Size > LargeFileSize || isExcludedTreePath(scanner, entry.Path) {
+			continue
+		}
+
+		cacheKey := entry.Blob + "\x00" + entry.Path
+		fileItems, ok := cache[cacheKey]
+		if !ok {
+			content, err := blobs.Read(entry.Blob)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s at %s: %v", entry.Path, commit.Short, err)
+			}
+			if !isBinaryContent(content) {
+				fileItems = scanner.scan(entry.Path, bytes.NewReader(content), commit.Timestamp, func() string { return author })
+			}
+			cache[cacheKey] = fileItems
+		}
+
+		for _, item := range fileItems {
+			copied := *item
+			copied.ID = len(items) + 1
+			items = append(items, &copied)
+		}
+	}
+
+	return items, nil
+}
+
+func listBackfillCommits(branch string, since time.Time) ([]backfillCommit, error) {
+	args := []string{"log", "--first-parent", "--reverse", "--format=%H%x1f%h%x1f%an%x1f%ct%x1f%s"}
+	if !since.IsZero() {
+		args = append(args, fmt.Sprintf("--since=%d", since.Unix()))
+	}
+	args = append(args, branch, "--")
+
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits of %s: %v", branch, err)
+	}
+
+	var commits []backfillCommit
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, "\x1f", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		unix, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		commits = append(commits, backfillCommit{
+			Hash:      fields[0],
+			Short:     fields[1],
+			Author:    fields[2],
+			Timestamp: time.Unix(unix, 0),
+			Message:   fields[4],
+		})
+	}
+
+	return commits, nil
+}
+
+// sampleBackfillCommits keeps every n-th commit and then thins the result to
+// at most limit evenly spaced commits. The newest commit is always kept.
+func sampleBackfillCommits(commits []backfillCommit, every, limit int) []backfillCommit {
+	if len(commits) == 0 {
+		return commits
+	}
+
+	if every > 1 {
+		sampled := []backfillCommit{}
+		for i := 0; i < len(commits); i += every {
+			sampled = append(sampled, commits[i])
+		}
+		if last := commits[len(commits)-1]; sampled[len(sampled)-1].Hash != last.Hash {
+			sampled = append(sampled, last)
+		}
+		commits = sampled
+	}
+
+	if limit > 0 && len(commits) > limit {
+		if limit == 1 {
+			return commits[len(commits)-1:]
+		}
+		sampled := make([]backfillCommit, 0, limit)
+		for i := 0; i < limit; i++ {
+			sampled = append(sampled, commits[i*(len(commits)-1)/(limit-1)])
+		}
+		commits = sampled
+	}
+
+	return commits
+}
+
+func listTree(commit string) ([]treeEntry, error) {
+	output, err := exec.Command("git", "ls-tree", "-r", "-z", "-l", commit).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files of %s: %v", commit, err)
+	}
+
+	var entries []treeEntry
+	for _, record := range strings.Split(string(output), "\x00") {
+		meta, path, ok := strings.Cut(record, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(meta)
+		if len(fields) != 4 || fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, treeEntry{Blob: fields[2], Size: size, Path: filepath.FromSlash(path)})
+	}
+
+	return entries, nil
+}
+
+func isExcludedTreePath(scanner *itemScanner, path string) bool {
+	dirs := strings.Split(filepath.ToSlash(filepath.Dir(path)), "/")
+	for _, dir := range dirs {
+		if dir != "." && scanner.isExcludedDir(dir) {
+			return true
+		}
+	}
+
+	excluded, err := scanner.isExcludedFile(path)
+	return err != nil || excluded
+}
+
+func isBinaryContent(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// blobReader keeps a single `git cat-file --batch` process around so that
+// reading thousands of blobs does not fork git for each one.
+type blobReader struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+func newBlobReader() (*blobReader, error) {
+	cmd := exec.Command("git", "cat-file", "--batch")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start git cat-file: %v", err)
+	}
+
+	return &blobReader{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}, nil
+}
+
+func (b *blobReader) Read(blob string) ([]byte, error) {
+	if _, err := fmt.Fprintln(b.stdin, blob); err != nil {
+		return nil, err
+	}
+
+	header, err := b.stdout.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unexpected object header %q", strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected object header %q", strings.TrimSpace(header))
+	}
+
+	content := make([]byte, size+1)
+	if _, err := io.ReadFull(b.stdout, content); err != nil {
+		return nil, err
+	}
+
+	return content[:size], nil
+}
+
+func (b *blobReader) Close() {
+	_ = b.stdin.Close()
+	_ = b.cmd.Wait()
+}
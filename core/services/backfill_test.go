@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+func TestSampleBackfillCommits(t *testing.T) {
+	commits := make([]backfillCommit, 7)
+	for i := range commits {
+		commits[i].Hash = fmt.Sprint(i)
+	}
+
+	tests := []struct {
+		every, limit int
+		want         string
+	}{
+		{0, 0, "0123456"},
+		{2, 0, "0246"},
+		{3, 0, "036"},
+		{4, 0, "046"},
+		{0, 3, "036"},
+		{0, 1, "6"},
+		{2, 2, "06"},
+	}
+	for _, tt := range tests {
+		var got strings.Builder
+		for _, commit := range sampleBackfillCommits(commits, tt.every, tt.limit) {
+			got.WriteString(commit.Hash)
+		}
+		if got.String() != tt.want {
+			t.Errorf("every %d, limit %d: sampled %s, want %s", tt.every, tt.limit, got.String(), tt.want)
+		}
+	}
+}
+
+// newBackfillRepo commits one more item per day for the given number of
+// days, ending today.
+func newBackfillRepo(t *testing.T, days int) (*entities.Config, []time.Time) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	config := newTestProject(t, nil)
+	runGit(t, "init", "-q")
+
+	start := time.Now().AddDate(0, 0, -days+1).Truncate(time.Second)
+	var source strings.Builder
+	source.WriteString("package a\n")
+	dates := make([]time.Time, days)
+	for i := range dates {
+		dates[i] = start.AddDate(0, 0, i)
+		fmt.Fprintf(&source, "\n// TODO: item %d\nfunc f%d() {}\n", i, i)
+		writeTestFile(t, "a.go", source.String())
+		runGit(t, "add", "a.go")
+		t.Setenv("GIT_COMMITTER_DATE", dates[i].Format(time.RFC3339))
+		runGit(t, "commit", "-qm", fmt.Sprintf("add item %d", i))
+	}
+	return config, dates
+}
+
+func backfilledTotals(t *testing.T, scanner *ScannerService) []int {
+	t.Helper()
+	totals := []int{}
+	for _, snapshot := range scanner.historyService.branchSnapshots(scanner.historyService.GetGitBranch()) {
+		if snapshot.Backfilled {
+			totals = append(totals, snapshot.History.Total)
+		}
+	}
+	return totals
+}
+
+func TestBackfillSamplesCommits(t *testing.T) {
+	tests := []struct {
+		name string
+		opts func(dates []time.Time) BackfillOptions
+		want []int
+	}{
+		{"every", func([]time.Time) BackfillOptions { return BackfillOptions{Every: 2} }, []int{1, 3, 5}},
+		{"limit", func([]time.Time) BackfillOptions { return BackfillOptions{Limit: 3} }, []int{1, 3}},
+		{"since", func(dates []time.Time) BackfillOptions { return BackfillOptions{Since: dates[3]} }, []int{4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, dates := newBackfillRepo(t, 6)
+			scanner := newTestScanner(t, config)
+			if err := scanner.Rescan(); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := scanner.Backfill(tt.opts(dates))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The newest commit is always sampled, and it was already
+			// scanned live.
+			if result.Skipped != 1 || result.Added != len(tt.want) {
+				t.Errorf("result = %+v, want %d added and the live commit skipped", result, len(tt.want))
+			}
+
+			totals := backfilledTotals(t, scanner)
+			if fmt.Sprint(totals) != fmt.Sprint(tt.want) {
+				t.Errorf("backfilled snapshots count %v items, want %v", totals, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackfillReplacesEarlierBackfills(t *testing.T) {
+	config, _ := newBackfillRepo(t, 4)
+	scanner := newTestScanner(t, config)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := scanner.Backfill(BackfillOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Added != 3 || first.Skipped != 1 {
+		t.Fatalf("first backfill = %+v, want 3 added and the live commit skipped", first)
+	}
+
+	second, err := scanner.Backfill(BackfillOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Added != 0 || second.Updated != 3 || second.Skipped != 1 {
+		t.Errorf("second backfill = %+v, want the 3 backfilled snapshots replaced", second)
+	}
+
+	if totals := backfilledTotals(t, scanner); fmt.Sprint(totals) != "[1 2 3]" {
+		t.Errorf("backfilled snapshots count %v items, want one snapshot per commit", totals)
+	}
+}
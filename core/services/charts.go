@@ -0,0 +1,343 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"go.uber.org/zap"
+)
+
+const maxChartDays = 5 * 366
+
+type ChartScope struct {
+	BoardID string
+	// Branch picks the snapshots the chart is drawn from; it defaults to
+	// the checked out branch.
+	Branch   string
+	SprintID int
+	Query    *ItemQuery
+	From     time.Time
+	To       time.Time
+}
+
+type chartSeries struct {
+	Board *entities.Board
+	Range entities.ChartRange
+	Days  []chartDay
+	// End is the day the work is due: the sprint end, or the range end.
+	End time.Time
+}
+
+type chartDay struct {
+	Date      time.Time
+	Scope     int
+	Completed int
+	ByColumn  map[string]int
+}
+
+type ChartService struct {
+	logger         *zap.Logger
+	settings       *SettingsService
+	historyService *HistoryService
+	sprintService  *SprintService
+}
+
+func NewChartService(logger *zap.Logger, settings *SettingsService, historyService *HistoryService, sprintService *SprintService) *ChartService {
+	return &ChartService{
+		logger:         logger,
+		settings:       settings,
+		historyService: historyService,
+		sprintService:  sprintService,
+	}
+}
+
+func (c *ChartService) GetBurndown(scope ChartScope) (*entities.BurndownChart, error) {
+	series, err := c.dailySeries(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	chart := &entities.BurndownChart{ChartRange: series.Range, Points: []entities.BurndownPoint{}}
+	if len(series.Days) == 0 {
+		return chart, nil
+	}
+
+	// The ideal line runs from the first remaining count down to zero on
+	// the day the work is due.
+	start := series.Days[0]
+	startRemaining := float64(start.Scope - start.Completed)
+	span := chartDaysBetween(start.Date, series.End)
+
+	for _, day := range series.Days {
+		ideal := 0.0
+		if span > 0 {
+			ideal = max(0, startRemaining*(1-float64(chartDaysBetween(start.Date, day.Date))/float64(span)))
+		}
+		chart.Points = append(chart.Points, entities.BurndownPoint{
+			Date:      day.Date.Format("2006-01-02"),
+			Remaining: day.Scope - day.Completed,
+			Ideal:     ideal,
+			Scope:     day.Scope,
+			Completed: day.Completed,
+		})
+	}
+
+	return chart, nil
+}
+
+func (c *ChartService) GetBurnup(scope ChartScope) (*entities.BurnupChart, error) {
+	series, err := c.dailySeries(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	chart := &entities.BurnupChart{ChartRange: series.Range, Points: []entities.BurnupPoint{}}
+	for _, day := range series.Days {
+		chart.Points = append(chart.Points, entities.BurnupPoint{
+			Date:      day.Date.Format("2006-01-02"),
+			Scope:     day.Scope,
+			Completed: day.Completed,
+		})
+	}
+
+	return chart, nil
+}
+
+func (c *ChartService) GetCumulativeFlow(scope ChartScope) (*entities.CumulativeFlowChart, error) {
+	series, err := c.dailySeries(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	chart := &entities.CumulativeFlowChart{
+		ChartRange: series.Range,
+		Columns:    series.Board.Columns,
+		Points:     []map[string]interface{}{},
+	}
+	for _, day := range series.Days {
+		point := map[string]interface{}{"date": day.Date.Format("2006-01-02")}
+		for _, col := range series.Board.Columns {
+			point[col.ID] = day.ByColumn[col.ID]
+		}
+		chart.Points = append(chart.Points, point)
+	}
+
+	return chart, nil
+}
+
+// dailySeries counts the scope for every day in the range from the last
+// snapshot taken on or before that day, so days without a scan carry the
+// previous state forward. Rollups have no item lists and are skipped when
+// the scope needs to look at single items.
+func (c *ChartService) dailySeries(scope ChartScope) (*chartSeries, error) {
+	board, err := c.historyService.resolveBoard(c.settings, scope.BoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sprint *entities.Sprint
+	if scope.SprintID != 0 {
+		sprint, err = c.sprintService.GetSprint(scope.SprintID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	from, to, err := chartRangeFor(scope, sprint)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := c.historyService.resolveBranch(scope.Branch)
+	series := &chartSeries{
+		Board: board,
+		Range: entities.ChartRange{
+			Board:    board.ID,
+			Branch:   branch,
+			SprintID: scope.SprintID,
+			From:     from.Format("2006-01-02"),
+			To:       to.Format("2006-01-02"),
+		},
+		Days: []chartDay{},
+		End:  to,
+	}
+	if sprint != nil && startOfDay(sprint.EndDate).After(to) {
+		series.End = startOfDay(sprint.EndDate)
+	}
+	if scope.Query != nil {
+		series.Range.Query = scope.Query.Raw
+	}
+
+	snapshots := c.historyService.branchSnapshots(branch)
+
+	lifecycles := c.historyService.GetLifecycles()
+	firstSeen := make(map[string]time.Time, len(lifecycles))
+	for _, lifecycle := range lifecycles {
+		firstSeen[lifecycle.Key] = lifecycle.FirstSeenAt
+	}
+
+	needsItems := sprint != nil || !scope.Query.IsEmpty()
+	next := 0
+	var current *entities.BranchSnapshot
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		for next < len(snapshots) && snapshots[next].Timestamp.Before(dayEnd) {
+			if !needsItems || snapshots[next].Rollup == "" {
+				current = &snapshots[next]
+			}
+			next++
+		}
+		if current == nil {
+			continue
+		}
+
+		series.Days = append(series.Days, c.countDay(day, dayEnd, current, board, sprint, scope.Query, needsItems, lifecycles, firstSeen))
+	}
+
+	return series, nil
+}
+
+func (c *ChartService) countDay(day, dayEnd time.Time, snapshot *entities.BranchSnapshot, board *entities.Board, sprint *entities.Sprint, query *ItemQuery, needsItems bool, lifecycles []entities.ItemLifecycle, firstSeen map[string]time.Time) chartDay {
+	result := chartDay{Date: day, ByColumn: make(map[string]int)}
+	for _, col := range board.Columns {
+		result.ByColumn[col.ID] = 0
+	}
+	doneColumnID := board.Columns[len(board.Columns)-1].ID
+
+	removed := make(map[string]bool)
+	for _, lifecycle := range lifecycles {
+		if !lifecycle.IsRemoved() || !lifecycle.InBoard(board.ID) || !lifecycle.RemovedAt.Before(dayEnd) {
+			continue
+		}
+		if needsItems && !inChartScope(lifecycle.Key, lifecycleQueryItem(lifecycle), sprint, query, dayEnd) {
+			continue
+		}
+		removed[lifecycle.Key] = true
+		result.Scope++
+		result.Completed++
+		result.ByColumn[doneColumnID]++
+	}
+
+	if !needsItems {
+		stats := snapshotBoardStats(*snapshot, board)
+		for _, col := range board.Columns {
+			result.ByColumn[col.ID] += stats.ByStatus[col.ID]
+		}
+		result.Scope += stats.Total
+		result.Completed += stats.ByStatus[doneColumnID]
+		return result
+	}
+
+	for _, item := range boardTaskItems(snapshot.History.Items, board) {
+		key := item.GetKey()
+		if removed[key] || !inChartScope(key, taskQueryItem(item, firstSeen[key]), sprint, query, dayEnd) {
+			continue
+		}
+
+		status := string(item.Status)
+		if item.IsDone {
+			status = doneColumnID
+		}
+
+		result.Scope++
+		if _, ok := result.ByColumn[status]; ok {
+			result.ByColumn[status]++
+		}
+		if status == doneColumnID {
+			result.Completed++
+		}
+	}
+
+	return result
+}
+
+func inChartScope(key string, item *entities.Item, sprint *entities.Sprint, query *ItemQuery, at time.Time) bool {
+	if sprint != nil && !sprintContainsAt(sprint, key, at) {
+		return false
+	}
+	return query.Match(item)
+}
+
+func sprintContainsAt(sprint *entities.Sprint, key string, at time.Time) bool {
+	for _, assigned := range sprint.Items {
+		if assigned.ItemKey != key || assigned.AddedAt.After(at) {
+			continue
+		}
+		if assigned.RemovedAt == nil || assigned.RemovedAt.After(at) {
+			return true
+		}
+	}
+	return false
+}
+
+func taskQueryItem(item entities.TaskItem, firstSeen time.Time) *entities.Item {
+	return &entities.Item{
+		ID:        item.ID,
+		Key:       item.GetKey(),
+		Type:      item.Type,
+		Title:     item.Title,
+		File:      item.File,
+		Line:      item.Line,
+		Status:    item.Status,
+		Priority:  item.Priority,
+		Boards:    item.Boards,
+		CreatedAt: firstSeen,
+	}
+}
+
+func lifecycleQueryItem(lifecycle entities.ItemLifecycle) *entities.Item {
+	return &entities.Item{
+		Key:       lifecycle.Key,
+		Type:      lifecycle.Type,
+		Title:     lifecycle.Title,
+		File:      lifecycle.File,
+		Line:      lifecycle.Line,
+		Status:    lifecycle.LastStatus,
+		Priority:  lifecycle.Priority,
+		Boards:    lifecycle.Boards,
+		CreatedAt: lifecycle.FirstSeenAt,
+	}
+}
+
+// chartRangeFor defaults to the sprint dates, or the last 30 days, and never
+// reaches past today.
+func chartRangeFor(scope ChartScope, sprint *entities.Sprint) (time.Time, time.Time, error) {
+	today := startOfDay(time.Now())
+
+	from, to := scope.From, scope.To
+	if sprint != nil {
+		if from.IsZero() {
+			from = sprint.StartDate
+		}
+		if to.IsZero() {
+			to = sprint.EndDate
+		}
+	}
+	if to.IsZero() || to.After(today) {
+		to = today
+	}
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -29)
+	}
+
+	from, to = startOfDay(from), startOfDay(to)
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("invalid date range")
+	}
+	if chartDaysBetween(from, to) > maxChartDays {
+		return time.Time{}, time.Time{}, errors.New("date range too long")
+	}
+
+	return from, to, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func chartDaysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Round(time.Hour).Hours() / 24)
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
+	"go.uber.org/zap"
+)
+
+// errUnchanged is returned by the fn of a storage update to skip the save.
+var errUnchanged = errors.New("unchanged")
+
+type CommentService struct {
+	config *entities.Config
+	logger *zap.Logger
+}
+
+func NewCommentService(config *entities.Config, logger *zap.Logger) *CommentService {
+	return &CommentService{
+		config: config,
+		logger: logger,
+	}
+}
+
+func (s *CommentService) getCommentsFilePath() string {
+	wd, _ := os.Getwd()
+	return filepath.Join(wd, s.config.Flags.Config, "comments.json")
+}
+
+func (s *CommentService) loadCommentStorage() (*entities.ItemCommentStorage, error) {
+	filePath := s.getCommentsFilePath()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &entities.ItemCommentStorage{
+				Threads: []entities.ItemCommentThread{},
+				NextID:  1,
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to read comments file: %v", err)
+	}
+
+	var storage entities.ItemCommentStorage
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal comments: %v", err)
+	}
+
+	if storage.NextID < 1 {
+		storage.NextID = 1
+	}
+
+	return &storage, nil
+}
+
+func (s *CommentService) saveCommentStorage(comments *entities.ItemCommentStorage) error {
+	data, err := json.MarshalIndent(comments, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal comments: %v", err)
+	}
+
+	if err := storage.WriteFile(s.getCommentsFilePath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write comments file: %v", err)
+	}
+
+	return nil
+}
+
+// updateCommentStorage runs fn on the comments while holding the lock of
+// comments.json and saves them, unless fn returns an error or errUnchanged.
+func (s *CommentService) updateCommentStorage(fn func(comments *entities.ItemCommentStorage) error) error {
+	return storage.WithFileLock(s.getCommentsFilePath(), func() error {
+		comments, err := s.loadCommentStorage()
+		if err != nil {
+			return err
+		}
+
+		if err := fn(comments); err != nil {
+			if errors.Is(err, errUnchanged) {
+				return nil
+			}
+			return err
+		}
+		return s.saveCommentStorage(comments)
+	})
+}
+
+func (s *CommentService) findThread(storage *entities.ItemCommentStorage, itemKey string) int {
+	for i, thread := range storage.Threads {
+		if thread.ItemKey == itemKey {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *CommentService) GetThread(item *entities.Item) (*entities.ItemCommentThread, error) {
+	storage, err := s.loadCommentStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	if index := s.findThread(storage, item.Key); index != -1 {
+		return &storage.Threads[index], nil
+	}
+
+	return &entities.ItemCommentThread{
+		ItemKey:  item.Key,
+		File:     item.File,
+		Line:     item.Line,
+		Type:     item.Type,
+		Title:    item.Title,
+		Comments: []entities.ItemComment{},
+	}, nil
+}
+
+func (s *CommentService) GetCommentCounts() (map[string]int, error) {
+	storage, err := s.loadCommentStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, thread := range storage.Threads {
+		counts[thread.ItemKey] = len(thread.Comments)
+	}
+
+	return counts, nil
+}
+
+func (s *CommentService) AddComment(item *entities.Item, parentID *int, body string) (*entities.ItemComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, errors.New("comment body is required")
+	}
+
+	var comment entities.ItemComment
+	err := s.updateCommentStorage(func(comments *entities.ItemCommentStorage) error {
+		now := time.Now()
+		index := s.findThread(comments, item.Key)
+		if index == -1 {
+			comments.Threads = append(comments.Threads, entities.ItemCommentThread{
+				ItemKey:   item.Key,
+				Comments:  []entities.ItemComment{},
+				CreatedAt: now,
+			})
+			index = len(comments.Threads) - 1
+		}
+
+		thread := &comments.Threads[index]
+
+		if parentID != nil {
+			found := false
+			for _, c := range thread.Comments {
+				if c.ID == *parentID {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return errors.New("parent comment not found")
+			}
+		}
+
+		comment = entities.ItemComment{
+			ID:        comments.NextID,
+			ParentID:  parentID,
+			Author:    getGitAuthor(),
+			Body:      body,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		thread.File = item.File
+		thread.Line = item.Line
+		thread.Type = item.Type
+		thread.Title = item.Title
+		thread.UpdatedAt = now
+		thread.Comments = append(thread.Comments, comment)
+		comments.NextID++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func (s *CommentService) UpdateComment(id int, body string) (*entities.ItemComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, errors.New("comment body is required")
+	}
+
+	var comment entities.ItemComment
+	err := s.updateCommentStorage(func(comments *entities.ItemCommentStorage) error {
+		for i := range comments.Threads {
+			thread := &comments.Threads[i]
+			for j := range thread.Comments {
+				if thread.Comments[j].ID != id {
+					continue
+				}
+
+				now := time.Now()
+				thread.Comments[j].Body = body
+				thread.Comments[j].UpdatedAt = now
+				thread.UpdatedAt = now
+
+				comment = thread.Comments[j]
+				return nil
+			}
+		}
+		return errors.New("comment not found")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+func (s *CommentService) DeleteComment(id int) error {
+	return s.updateCommentStorage(func(comments *entities.ItemCommentStorage) error {
+		for i := range comments.Threads {
+			thread := &comments.Threads[i]
+
+			removed := map[int]bool{}
+			for _, c := range thread.Comments {
+				if c.ID == id {
+					removed[id] = true
+					break
+				}
+			}
+			if len(removed) == 0 {
+				continue
+			}
+
+			for changed := true; changed; {
+				changed = false
+				for _, c := range thread.Comments {
+					if c.ParentID != nil && removed[*c.ParentID] && !removed[c.ID] {
+						removed[c.ID] = true
+						changed = true
+					}
+				}
+			}
+
+			kept := thread.Comments[:0]
+			for _, c := range thread.Comments {
+				if !removed[c.ID] {
+					kept = append(kept, c)
+				}
+			}
+			thread.Comments = kept
+			thread.UpdatedAt = time.Now()
+
+			if len(thread.Comments) == 0 {
+				comments.Threads = append(comments.Threads[:i], comments.Threads[i+1:]...)
+			}
+			return nil
+		}
+		return errors.New("comment not found")
+	})
+}
+
+func (s *CommentService) Reconcile(items []*entities.Item) error {
+	return s.updateCommentStorage(func(comments *entities.ItemCommentStorage) error {
+		if len(comments.Threads) == 0 {
+			return errUnchanged
+		}
+
+		itemsByKey := make(map[string]*entities.Item)
+		for _, item := range items {
+			itemsByKey[item.Key] = item
+		}
+
+		threadKeys := make(map[string]bool)
+		for _, thread := range comments.Threads {
+			threadKeys[thread.ItemKey] = true
+		}
+
+		changed := false
+		for i := range comments.Threads {
+			thread := &comments.Threads[i]
+
+			item, ok := itemsByKey[thread.ItemKey]
+			if !ok {
+				item = findMovedItem(thread.Type, thread.Title, items, threadKeys)
+				if item == nil {
+					continue
+				}
+
+				s.logger.Info("Re-anchored comment thread",
+					zap.String("old_key", thread.ItemKey),
+					zap.String("new_key", item.Key),
+					zap.String("file", item.File))
+
+				delete(threadKeys, thread.ItemKey)
+				threadKeys[item.Key] = true
+				thread.ItemKey = item.Key
+				changed = true
+			}
+
+			if thread.File != item.File || thread.Line != item.Line || thread.Title != item.Title {
+				thread.File = item.File
+				thread.Line = item.Line
+				thread.Type = item.Type
+				thread.Title = item.Title
+				changed = true
+			}
+		}
+
+		if !changed {
+			return errUnchanged
+		}
+		return nil
+	})
+}
+
+func findMovedItem(itemType entities.ItemType, title string, items []*entities.Item, takenKeys map[string]bool) *entities.Item {
+	var candidates []*entities.Item
+	for _, item := range items {
+		if takenKeys[item.Key] {
+			continue
+		}
+		if strings.EqualFold(string(item.Type), string(itemType)) &&
+			strings.EqualFold(strings.TrimSpace(item.Title), strings.TrimSpace(title)) {
+			candidates = append(candidates, item)
+		}
+	}
+
+	if len(candidates) != 1 {
+		return nil
+	}
+
+	return candidates[0]
+}
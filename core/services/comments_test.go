@@ -0,0 +1,45 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"go.uber.org/zap"
+)
+
+func TestConcurrentCommentsKeepEveryWrite(t *testing.T) {
+	config := newTestProject(t, nil)
+	comments := NewCommentService(config, zap.NewNop())
+	item := &entities.Item{Key: entities.ItemKey("a.go", "TODO", "fix this", 0), File: "a.go", Type: "TODO", Title: "fix this"}
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := comments.AddComment(item, nil, fmt.Sprintf("comment %d", i)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	thread, err := comments.GetThread(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thread.Comments) != writers {
+		t.Fatalf("got %d comments, want %d", len(thread.Comments), writers)
+	}
+
+	ids := map[int]bool{}
+	for _, comment := range thread.Comments {
+		if ids[comment.ID] {
+			t.Errorf("comment ID %d given out twice", comment.ID)
+		}
+		ids[comment.ID] = true
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+var ErrRefNotFound = errors.New("ref not found")
+
+type CompareSide struct {
+	Ref         string              `json:"ref"`
+	Commit      string              `json:"commit"`
+	CommitShort string              `json:"commit_short"`
+	Message     string              `json:"message"`
+	Branch      string              `json:"branch,omitempty"`
+	Timestamp   time.Time           `json:"timestamp"`
+	Source      string              `json:"source"`
+	History     entities.ItemStats  `json:"history"`
+	Board       entities.BoardStats `json:"board"`
+}
+
+type ColumnDelta struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	Delta int    `json:"delta"`
+}
+
+// Compare diffs two points in history. Each side is a commit-ish ref or a
+// date; empty sides default to the two most recent full snapshots. Commits
+// without a stored snapshot are scanned from git on demand.
+func (pt *HistoryService) Compare(settings *SettingsService, boardID, from, to string) (map[string]interface{}, error) {
+	board, err := pt.resolveBoard(settings, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := pt.fullSnapshots()
+
+	var toSide *CompareSide
+	if to == "" {
+		if len(snapshots) == 0 {
+			return nil, errors.New("not enough history for comparison")
+		}
+		toSide = snapshotSide("", snapshots[len(snapshots)-1])
+	} else if toSide, err = pt.resolveCompareSide(to, snapshots, settings); err != nil {
+		return nil, err
+	}
+
+	var fromSide *CompareSide
+	if from == "" {
+		for i := len(snapshots) - 1; i >= 0; i-- {
+			if snapshots[i].Timestamp.Before(toSide.Timestamp) && snapshots[i].Commit != toSide.Commit {
+				fromSide = snapshotSide("", snapshots[i])
+				break
+			}
+		}
+		if fromSide == nil {
+			return nil, errors.New("not enough history for comparison")
+		}
+	} else if fromSide, err = pt.resolveCompareSide(from, snapshots, settings); err != nil {
+		return nil, err
+	}
+
+	fromItems := boardTaskItems(fromSide.History.Items, board)
+	toItems := boardTaskItems(toSide.History.Items, board)
+	fromSide.Board = taskItemBoardStats(fromSide.History.Items, board)
+	toSide.Board = taskItemBoardStats(toSide.History.Items, board)
+
+	diff := diffTaskItems(fromItems, toItems)
+
+	columns := []ColumnDelta{}
+	changes := make(map[string]int)
+	for _, col := range board.Columns {
+		delta := ColumnDelta{
+			ID:   col.ID,
+			Name: col.Name,
+			From: fromSide.Board.ByStatus[col.ID],
+			To:   toSide.Board.ByStatus[col.ID],
+		}
+		delta.Delta = delta.To - delta.From
+		columns = append(columns, delta)
+		changes[col.Name] = delta.Delta
+	}
+
+	return map[string]interface{}{
+		"board":          board.ID,
+		"from":           fromSide,
+		"to":             toSide,
+		"added":          diff.Added,
+		"removed":        diff.Removed,
+		"changed":        diff.Changed,
+		"status_changed": diff.StatusChanged,
+		"columns":        columns,
+		"summary":        diff.Summary(),
+		"previous":       fromSide,
+		"current":        toSide,
+		"changes":        changes,
+	}, nil
+}
+
+// fullSnapshots returns the snapshots that still carry their items, oldest
+// first.
+func (pt *HistoryService) fullSnapshots() []entities.BranchSnapshot {
+	snapshots := []entities.BranchSnapshot{}
+	for _, snapshot := range pt.GetBranchHistory() {
+		if snapshot.Rollup == "" {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	slices.SortFunc(snapshots, func(a, b entities.BranchSnapshot) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return snapshots
+}
+
+func (pt *HistoryService) resolveCompareSide(ref string, snapshots []entities.BranchSnapshot, settings *SettingsService) (*CompareSide, error) {
+	var commit string
+
+	if date, ok := parseCompareDate(ref); ok {
+		output, err := exec.Command("git", "rev-list", "-1", "--first-parent", fmt.Sprintf("--before=%d", date.Unix()), "HEAD").Output()
+		commit = strings.TrimSpace(string(output))
+		if err != nil || commit == "" {
+			// Without git, fall back to the last snapshot taken by then.
+			for i := len(snapshots) - 1; i >= 0; i-- {
+				if !snapshots[i].Timestamp.After(date) {
+					return snapshotSide(ref, snapshots[i]), nil
+				}
+			}
+			return nil, fmt.Errorf("%w: no history before %s", ErrRefNotFound, ref)
+		}
+	} else {
+		output, err := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}").Output()
+		commit = strings.TrimSpace(string(output))
+		if err != nil || commit == "" {
+			return nil, fmt.Errorf("%w: %s", ErrRefNotFound, ref)
+		}
+	}
+
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].Commit == commit {
+			return snapshotSide(ref, snapshots[i]), nil
+		}
+	}
+
+	return pt.scanCompareSide(ref, commit, settings)
+}
+
+func (pt *HistoryService) scanCompareSide(ref, commit string, settings *SettingsService) (*CompareSide, error) {
+	output, err := exec.Command("git", "log", "-1", "--format=%H%x1f%h%x1f%an%x1f%ct%x1f%s", commit).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRefNotFound, ref)
+	}
+
+	fields := strings.SplitN(strings.TrimSpace(string(output)), "\x1f", 5)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("unexpected commit format for %s", ref)
+	}
+	unix, _ := strconv.ParseInt(fields[3], 10, 64)
+	info := backfillCommit{
+		Hash:      fields[0],
+		Short:     fields[1],
+		Author:    fields[2],
+		Timestamp: time.Unix(unix, 0),
+		Message:   fields[4],
+	}
+
+	blobs, err := newBlobReader()
+	if err != nil {
+		return nil, err
+	}
+	defer blobs.Close()
+
+	currentSettings, err := settings.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := newItemScanner(currentSettings)
+	items, err := scanCommitItems(scanner, blobs, make(map[string][]*entities.Item), info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompareSide{
+		Ref:         ref,
+		Commit:      info.Hash,
+		CommitShort: info.Short,
+		Message:     info.Message,
+		Timestamp:   info.Timestamp,
+		Source:      "scan",
+		History:     pt.generateItemStats(items, currentSettings),
+	}, nil
+}
+
+func snapshotSide(ref string, snapshot entities.BranchSnapshot) *CompareSide {
+	return &CompareSide{
+		Ref:         ref,
+		Commit:      snapshot.Commit,
+		CommitShort: snapshot.CommitShort,
+		Message:     snapshot.CommitMessage,
+		Branch:      snapshot.Branch,
+		Timestamp:   snapshot.Timestamp,
+		Source:      "snapshot",
+		History:     snapshot.History,
+	}
+}
+
+// parseCompareDate accepts a date, meaning the end of that day, or an
+// RFC 3339 timestamp.
+func parseCompareDate(value string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t.Add(24*time.Hour - time.Second), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+func TestCompareRefs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	config := newTestProject(t, map[string]string{
+		"a.go": "package a\n\n// TODO: kept\nfunc a() {}\n\n// TODO: dropped\nfunc b() {}\n",
+	})
+	runGit(t, "init", "-q")
+	runGit(t, "add", "a.go")
+	runGit(t, "commit", "-qm", "first")
+	first := runGit(t, "rev-parse", "HEAD")
+
+	writeTestFile(t, "a.go", "package a\n\n// TODO: kept\nfunc a() {}\n\n// FIXME: added\nfunc c() {}\n\n// TODO: also added\nfunc d() {}\n")
+	runGit(t, "commit", "-qam", "second")
+
+	scanner := newTestScanner(t, config)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := scanner.historyService.Compare(scanner.settings, "", "HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, to := result["from"].(*CompareSide), result["to"].(*CompareSide)
+	if from.Commit != first || from.Source != "scan" {
+		t.Errorf("from = %s from %s, want %s scanned from git", from.Commit, from.Source, first)
+	}
+	if to.Source != "snapshot" {
+		t.Errorf("to came from %s, want the stored snapshot of HEAD", to.Source)
+	}
+
+	titles := func(items []entities.TaskItem) map[string]bool {
+		set := map[string]bool{}
+		for _, item := range items {
+			set[item.Title] = true
+		}
+		return set
+	}
+	added, removed := titles(result["added"].([]entities.TaskItem)), titles(result["removed"].([]entities.TaskItem))
+	if len(added) != 2 || !added["added"] || !added["also added"] {
+		t.Errorf("added = %v, want the two new items", added)
+	}
+	if len(removed) != 1 || !removed["dropped"] {
+		t.Errorf("removed = %v, want the dropped item", removed)
+	}
+
+	columns := result["columns"].([]ColumnDelta)
+	if columns[0].ID != "todo" || columns[0].From != 2 || columns[0].To != 3 || columns[0].Delta != 1 {
+		t.Errorf("todo column = %+v, want 2 -> 3", columns[0])
+	}
+
+	if _, err := scanner.historyService.Compare(scanner.settings, "", "no-such-ref", "HEAD"); !errors.Is(err, ErrRefNotFound) {
+		t.Errorf("comparing against a missing ref returned %v, want ErrRefNotFound", err)
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"go.uber.org/zap"
+)
+
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|h[1-6]|pre|blockquote)>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]*>`)
+)
+
+type ConvertService struct {
+	logger         *zap.Logger
+	noteService    *NoteService
+	scannerService *ScannerService
+	linkService    *LinkService
+}
+
+func NewConvertService(logger *zap.Logger, noteService *NoteService, scannerService *ScannerService, linkService *LinkService) *ConvertService {
+	return &ConvertService{
+		logger:         logger,
+		noteService:    noteService,
+		scannerService: scannerService,
+		linkService:    linkService,
+	}
+}
+
+func (s *ConvertService) NoteToItem(noteID int, file string, line int, itemType entities.ItemType) (*entities.Item, error) {
+	note := s.noteService.getNoteByID(noteID)
+	if note == nil {
+		return nil, errors.New("note not found")
+	}
+
+	if itemType == "" {
+		itemType = "TODO"
+	}
+
+	description := strings.Split(noteContentToText(note.Content), "\n")
+	if err := s.scannerService.InsertItemComment(file, line, itemType, note.Title, description); err != nil {
+		return nil, err
+	}
+
+	if err := s.scannerService.Rescan(); err != nil {
+		return nil, fmt.Errorf("failed to rescan after conversion: %v", err)
+	}
+
+	item := s.scannerService.FindItemAt(file, line)
+	if item == nil {
+		return nil, fmt.Errorf("inserted item not found at %s:%d, check the auto assign patterns", file, line)
+	}
+
+	if _, err := s.linkService.CreateLink(note.ID, item, entities.LinkReferences); err != nil {
+		return item, fmt.Errorf("failed to link note to item: %v", err)
+	}
+
+	changes := map[string]interface{}{
+		"converted_to": map[string]interface{}{
+			"item_key": item.Key,
+			"type":     item.Type,
+			"file":     item.File,
+			"line":     item.Line,
+		},
+	}
+	if err := s.noteService.RecordNoteAction(note.ID, entities.ActionConverted, changes, "Note converted to code item"); err != nil {
+		s.logger.Warn("Failed to record note conversion", zap.Int("note_id", note.ID), zap.Error(err))
+	}
+
+	return item, nil
+}
+
+func (s *ConvertService) ItemToNote(item *entities.Item, folderID *int, deleteSource bool) (*entities.Note, error) {
+	tags := []string{strings.ToLower(string(item.Type))}
+	if item.Priority != "" {
+		tags = append(tags, strings.ToLower(string(item.Priority)))
+	}
+
+	note, err := s.noteService.CreateNoteWithHistory(item.Title, itemToNoteContent(item), tags, "development", folderID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.linkService.CreateLink(note.ID, item, entities.LinkDocuments); err != nil {
+		return note, fmt.Errorf("failed to link item to note: %v", err)
+	}
+
+	if deleteSource {
+		if err := s.scannerService.RemoveItemComment(item); err != nil {
+			return note, fmt.Errorf("failed to delete source comment: %v", err)
+		}
+		if err := s.scannerService.Rescan(); err != nil {
+			return note, fmt.Errorf("failed to rescan after conversion: %v", err)
+		}
+	}
+
+	changes := map[string]interface{}{
+		"converted_from": map[string]interface{}{
+			"item_key":       item.Key,
+			"type":           item.Type,
+			"file":           item.File,
+			"line":           item.Line,
+			"source_deleted": deleteSource,
+		},
+	}
+	if err := s.noteService.RecordNoteAction(note.ID, entities.ActionConverted, changes, "Code item converted to note"); err != nil {
+		s.logger.Warn("Failed to record item conversion", zap.Int("note_id", note.ID), zap.Error(err))
+	}
+
+	return note, nil
+}
+
+func itemToNoteContent(item *entities.Item) string {
+	var body strings.Builder
+
+	for _, line := range strings.Split(item.Description, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			body.WriteString(fmt.Sprintf("<p>%s</p>", html.EscapeString(line)))
+		}
+	}
+
+	body.WriteString(fmt.Sprintf("<p><strong>Source:</strong> <code>%s:%d</code> (%s, %s)</p>",
+		html.EscapeString(item.File), item.Line, html.EscapeString(string(item.Type)), html.EscapeString(string(item.Priority))))
+
+	if len(item.History) > 0 {
+		body.WriteString("<p><strong>History:</strong></p><ul>")
+		for _, h := range item.History {
+			body.WriteString(fmt.Sprintf("<li><p>%s — %s by %s</p></li>",
+				html.EscapeString(string(h.Status)),
+				h.Timestamp.Format("2006-01-02 15:04"),
+				html.EscapeString(h.User)))
+		}
+		body.WriteString("</ul>")
+	}
+
+	return body.String()
+}
+
+func noteContentToText(content string) string {
+	text := htmlBreakPattern.ReplaceAllString(content, "\n")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
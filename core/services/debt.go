@@ -0,0 +1,303 @@
+package services
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"go.uber.org/zap"
+)
+
+type DebtOptions struct {
+	// Path limits the report to a directory and everything below it.
+	Path  string
+	Depth int
+	Since time.Time
+}
+
+func itemDebt(item entities.TaskItem, age time.Duration, model entities.DebtModel) float64 {
+	typeWeight, ok := model.TypeWeights[strings.ToUpper(string(item.Type))]
+	if !ok {
+		typeWeight = 1
+	}
+	priorityWeight, ok := model.PriorityWeights[strings.ToUpper(string(item.Priority))]
+	if !ok {
+		priorityWeight = 1
+	}
+
+	months := max(0, age.Hours()/(24*30))
+	return typeWeight * priorityWeight * (1 + model.AgePerMonth*months)
+}
+
+func isTaskItemDone(item entities.TaskItem, settings *entities.Settings) bool {
+	if item.IsDone {
+		return true
+	}
+
+	board := settings.DefaultBoard()
+	for _, id := range item.Boards {
+		if b, ok := settings.GetBoard(id); ok {
+			board = *b
+			break
+		}
+	}
+
+	return len(board.Columns) > 0 && string(board.StatusFor(item.Status)) == board.Columns[len(board.Columns)-1].ID
+}
+
+// debtDirs returns the directories an item's debt rolls up into, starting
+// with "." for the whole project.
+func debtDirs(file string) []string {
+	return append([]string{"."}, parentDirs(path.Clean(filepath.ToSlash(file)), 0)...)
+}
+
+// snapshotDebt rolls the debt of the open items up through the directory
+// tree. Items missing from firstSeen are taken to be new at the given time.
+func snapshotDebt(items []entities.TaskItem, firstSeen map[string]time.Time, at time.Time, settings *entities.Settings) map[string]float64 {
+	debt := map[string]float64{".": 0}
+	for _, item := range items {
+		if isTaskItemDone(item, settings) {
+			continue
+		}
+
+		seen, ok := firstSeen[item.GetKey()]
+		if !ok || seen.After(at) {
+			seen = at
+		}
+
+		points := itemDebt(item, at.Sub(seen), settings.DebtModel)
+		for _, dir := range debtDirs(item.File) {
+			debt[dir] += points
+		}
+	}
+
+	for dir, points := range debt {
+		debt[dir] = math.Round(points*100) / 100
+	}
+	return debt
+}
+
+func lifecycleFirstSeen(lifecycles []entities.ItemLifecycle) map[string]time.Time {
+	firstSeen := make(map[string]time.Time, len(lifecycles))
+	for _, lifecycle := range lifecycles {
+		firstSeen[lifecycle.Key] = lifecycle.FirstSeenAt
+	}
+	return firstSeen
+}
+
+// debtOf returns the stored debt of a snapshot. Full snapshots saved before
+// debt was tracked are scored on the fly; older rollups have none.
+func debtOf(snapshot entities.BranchSnapshot, firstSeen map[string]time.Time, settings *entities.Settings) map[string]float64 {
+	if snapshot.Debt != nil || snapshot.Rollup != "" {
+		return snapshot.Debt
+	}
+	return snapshotDebt(snapshot.History.Items, firstSeen, snapshot.Timestamp, settings)
+}
+
+// debtBaseline returns the debt of the last snapshot at or before the given
+// time, or of the oldest snapshot when history does not reach back that far.
+func debtBaseline(snapshots []entities.BranchSnapshot, at time.Time, firstSeen map[string]time.Time, settings *entities.Settings) map[string]float64 {
+	var baseline map[string]float64
+	for _, snapshot := range snapshots {
+		debt := debtOf(snapshot, firstSeen, settings)
+		if debt == nil {
+			continue
+		}
+		if baseline != nil && snapshot.Timestamp.After(at) {
+			break
+		}
+		baseline = debt
+	}
+	return baseline
+}
+
+func debtRegressions(current, baseline map[string]float64, threshold float64) map[string]float64 {
+	regressions := make(map[string]float64)
+	if threshold <= 0 {
+		return regressions
+	}
+	for dir, points := range current {
+		if delta := points - baseline[dir]; delta >= threshold {
+			regressions[dir] = delta
+		}
+	}
+	return regressions
+}
+
+// alertDebtRegressions logs the directories that crossed the regression
+// threshold with the new snapshot.
+func (pt *HistoryService) alertDebtRegressions(previous []entities.BranchSnapshot, snapshot entities.BranchSnapshot, firstSeen map[string]time.Time, settings *entities.Settings) {
+	if len(previous) == 0 {
+		return
+	}
+
+	snapshots := slices.Clone(previous)
+	slices.SortStableFunc(snapshots, func(a, b entities.BranchSnapshot) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	model := settings.DebtModel
+	baseline := debtBaseline(snapshots, snapshot.Timestamp.AddDate(0, 0, -model.AlertWindowDays), firstSeen, settings)
+	if baseline == nil {
+		return
+	}
+
+	before := debtRegressions(debtOf(snapshots[len(snapshots)-1], firstSeen, settings), baseline, model.AlertThreshold)
+	for dir, delta := range debtRegressions(snapshot.Debt, baseline, model.AlertThreshold) {
+		if _, ok := before[dir]; ok {
+			continue
+		}
+		pt.logger.Warn("Debt regression",
+			zap.String("directory", dir),
+			zap.Float64("from", baseline[dir]),
+			zap.Float64("to", snapshot.Debt[dir]),
+			zap.Float64("delta", delta),
+			zap.Int("window_days", model.AlertWindowDays))
+	}
+}
+
+// GetDebtReport scores every directory with open items and compares it with
+// the debt at Since, which defaults to the start of the alert window.
+func (pt *HistoryService) GetDebtReport(settings *SettingsService, opts DebtOptions) (*entities.DebtReport, error) {
+	history, err := pt.LoadStats()
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		return nil, errors.New("no history available")
+	}
+
+	currentSettings, err := settings.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	model := currentSettings.DebtModel
+
+	now := time.Now()
+	if opts.Since.IsZero() {
+		opts.Since = now.AddDate(0, 0, -model.AlertWindowDays)
+	}
+	opts.Path = strings.Trim(path.Clean("/"+opts.Path), "/")
+	if opts.Path == "" {
+		opts.Path = "."
+	}
+
+	firstSeen := lifecycleFirstSeen(history.Lifecycles)
+	current := snapshotDebt(history.CurrentItems, firstSeen, now, currentSettings)
+
+	snapshots := slices.Clone(history.BranchHistory)
+	slices.SortStableFunc(snapshots, func(a, b entities.BranchSnapshot) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	baseline := debtBaseline(snapshots, opts.Since, firstSeen, currentSettings)
+	if baseline == nil {
+		baseline = current
+	}
+
+	counts := make(map[string]int)
+	for _, item := range history.CurrentItems {
+		if isTaskItemDone(item, currentSettings) {
+			continue
+		}
+		for _, dir := range debtDirs(item.File) {
+			counts[dir]++
+		}
+	}
+
+	dirs := make(map[string]bool)
+	for dir := range current {
+		dirs[dir] = true
+	}
+	for dir := range baseline {
+		dirs[dir] = true
+	}
+
+	report := &entities.DebtReport{
+		Total:       current["."],
+		Since:       opts.Since,
+		Threshold:   model.AlertThreshold,
+		Directories: []entities.DirectoryDebt{},
+		Regressions: []entities.DirectoryDebt{},
+	}
+
+	regressions := debtRegressions(current, baseline, model.AlertThreshold)
+	for dir := range dirs {
+		if dir == "." || (opts.Path != "." && dir != opts.Path && !strings.HasPrefix(dir, opts.Path+"/")) {
+			continue
+		}
+		if opts.Depth > 0 && strings.Count(dir, "/")+1 > opts.Depth {
+			continue
+		}
+
+		entry := entities.DirectoryDebt{
+			Path:     dir,
+			Items:    counts[dir],
+			Score:    current[dir],
+			Previous: baseline[dir],
+			Delta:    math.Round((current[dir]-baseline[dir])*100) / 100,
+		}
+		report.Directories = append(report.Directories, entry)
+		if _, ok := regressions[dir]; ok {
+			report.Regressions = append(report.Regressions, entry)
+		}
+	}
+
+	slices.SortFunc(report.Directories, func(a, b entities.DirectoryDebt) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	slices.SortFunc(report.Regressions, func(a, b entities.DirectoryDebt) int {
+		if c := cmp.Compare(b.Delta, a.Delta); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return report, nil
+}
+
+// GetDebtHistory returns the debt of one directory per snapshot, oldest
+// first. An empty path is the whole project.
+func (pt *HistoryService) GetDebtHistory(settings *SettingsService, dir string) []entities.DebtPoint {
+	history, err := pt.LoadStats()
+	if err != nil {
+		pt.logger.Error("Failed to load debt history", zap.Error(err))
+		return []entities.DebtPoint{}
+	}
+	if history == nil {
+		return []entities.DebtPoint{}
+	}
+
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+	if dir == "" {
+		dir = "."
+	}
+
+	currentSettings := settings.currentSettings()
+	firstSeen := lifecycleFirstSeen(history.Lifecycles)
+
+	snapshots := slices.Clone(history.BranchHistory)
+	slices.SortStableFunc(snapshots, func(a, b entities.BranchSnapshot) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	points := []entities.DebtPoint{}
+	for _, snapshot := range snapshots {
+		debt := debtOf(snapshot, firstSeen, currentSettings)
+		if debt == nil {
+			continue
+		}
+		points = append(points, entities.DebtPoint{
+			Timestamp: snapshot.Timestamp,
+			Commit:    snapshot.CommitShort,
+			Score:     debt[dir],
+		})
+	}
+
+	return points
+}
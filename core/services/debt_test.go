@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+func TestSnapshotDebtRollsUpDirectories(t *testing.T) {
+	settings := testSettings()
+	settings.DebtModel = entities.DebtModel{
+		TypeWeights:     map[string]float64{"FIXME": 2},
+		PriorityWeights: map[string]float64{"HIGH": 3},
+		AgePerMonth:     0.5,
+	}
+
+	now := time.Now()
+	items := []entities.TaskItem{
+		{Key: "old-fixme", Type: "FIXME", Priority: "HIGH", File: "src/a.go", Status: "todo"},
+		{Key: "new-todo", Type: "TODO", File: "src/sub/b.go", Status: "todo"},
+		{Key: "done", Type: "FIXME", Priority: "HIGH", File: "src/c.go", Status: "done"},
+		{Key: "month-old", Type: "note", File: "d.go", Status: "in_progress"},
+		{Key: "unseen", Type: "TODO", File: "d.go", Status: "todo"},
+	}
+	firstSeen := map[string]time.Time{
+		"old-fixme": now.AddDate(0, 0, -60),
+		"new-todo":  now.Add(time.Hour),
+		"done":      now.AddDate(-1, 0, 0),
+		"month-old": now.AddDate(0, 0, -30),
+	}
+
+	debt := snapshotDebt(items, firstSeen, now, settings)
+
+	// FIXME x HIGH x two months of age, a new TODO, a month old item of an
+	// unweighted type, and an item that was never seen and so is new.
+	want := map[string]float64{
+		".":       12 + 1 + 1.5 + 1,
+		"src":     12 + 1,
+		"src/sub": 1,
+	}
+	if len(debt) != len(want) {
+		t.Errorf("debt = %v, want %v", debt, want)
+	}
+	for dir, points := range want {
+		if debt[dir] != points {
+			t.Errorf("debt of %s = %v, want %v", dir, debt[dir], points)
+		}
+	}
+}
+
+func TestDebtRegressions(t *testing.T) {
+	current := map[string]float64{".": 20, "src": 15, "lib": 5}
+	baseline := map[string]float64{".": 12, "src": 6, "lib": 6}
+
+	regressions := debtRegressions(current, baseline, 8)
+	if len(regressions) != 2 || regressions["."] != 8 || regressions["src"] != 9 {
+		t.Errorf("regressions = %v, want . and src", regressions)
+	}
+	if regressions := debtRegressions(current, baseline, 0); len(regressions) != 0 {
+		t.Errorf("a zero threshold reported %v", regressions)
+	}
+}
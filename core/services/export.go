@@ -0,0 +1,322 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+const (
+	ExportCSV    = "csv"
+	ExportNDJSON = "ndjson"
+)
+
+// The column schemas of the export datasets. Columns are only ever appended
+// so that existing imports keep working.
+var exportColumns = map[string][]string{
+	"items": {
+		"id", "key", "type", "title", "description", "file", "line", "status", "priority",
+		"is_done", "done_at", "done_by", "issue_number", "stale", "boards", "first_seen_at",
+	},
+	"transitions": {
+		"key", "type", "title", "file", "from_status", "to_status", "timestamp", "user",
+	},
+	"snapshots": {
+		"timestamp", "branch", "commit", "commit_short", "commit_message", "backfilled", "rollup",
+		"total", "done", "open", "project_debt",
+	},
+	"snapshot_counts": {
+		"timestamp", "commit", "dimension", "value", "count",
+	},
+}
+
+var ExportDatasets = []string{"items", "transitions", "snapshots", "snapshot_counts"}
+
+type ExportOptions struct {
+	Dataset string
+	Format  string
+	BoardID string
+}
+
+func (o ExportOptions) Validate() error {
+	if _, ok := exportColumns[o.Dataset]; !ok {
+		return fmt.Errorf("unknown dataset %q, expected one of %s", o.Dataset, strings.Join(ExportDatasets, ", "))
+	}
+	if o.Format != ExportCSV && o.Format != ExportNDJSON {
+		return fmt.Errorf("unknown format %q, expected csv or ndjson", o.Format)
+	}
+	return nil
+}
+
+// Export streams one dataset to w. Items and transitions come from the
+// given items, snapshots from the stored branch history, oldest first.
+func (pt *HistoryService) Export(w io.Writer, settings *SettingsService, items []*entities.Item, opts ExportOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	board, err := pt.resolveBoard(settings, opts.BoardID)
+	if err != nil {
+		return err
+	}
+
+	var out exportWriter
+	if opts.Format == ExportCSV {
+		out = newCSVExportWriter(w)
+	} else {
+		out = &ndjsonExportWriter{w: w}
+	}
+	if err := out.Header(exportColumns[opts.Dataset]); err != nil {
+		return err
+	}
+
+	history, err := pt.LoadStats()
+	if err != nil {
+		return err
+	}
+	if history == nil {
+		history = &entities.ItemsHistory{}
+	}
+
+	switch opts.Dataset {
+	case "items":
+		err = exportItems(out, items, board, lifecycleFirstSeen(history.Lifecycles))
+	case "transitions":
+		err = exportTransitions(out, items, board)
+	case "snapshots", "snapshot_counts":
+		snapshots := slices.Clone(history.BranchHistory)
+		slices.SortStableFunc(snapshots, func(a, b entities.BranchSnapshot) int {
+			return a.Timestamp.Compare(b.Timestamp)
+		})
+		if opts.Dataset == "snapshots" {
+			var currentSettings *entities.Settings
+			if currentSettings, err = settings.LoadSettings(); err == nil {
+				err = exportSnapshots(out, snapshots, board, lifecycleFirstSeen(history.Lifecycles), currentSettings)
+			}
+		} else {
+			err = exportSnapshotCounts(out, snapshots, board)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return out.Flush()
+}
+
+func exportItems(out exportWriter, items []*entities.Item, board *entities.Board, firstSeen map[string]time.Time) error {
+	for _, item := range items {
+		if !item.InBoard(board.ID) {
+			continue
+		}
+
+		var seen any
+		if t, ok := firstSeen[item.Key]; ok {
+			seen = t
+		}
+		var issue any
+		if item.IssueNumber != nil {
+			issue = *item.IssueNumber
+		}
+		var doneBy any
+		if item.DoneBy != nil {
+			doneBy = *item.DoneBy
+		}
+		var doneAt any
+		if item.DoneAt != nil {
+			doneAt = *item.DoneAt
+		}
+
+		if err := out.Row([]any{
+			item.ID, item.Key, string(item.Type), item.Title, item.Description, item.File, item.Line,
+			string(board.StatusFor(item.Status)), string(item.Priority), item.IsDone, doneAt, doneBy,
+			issue, item.Stale, item.Boards, seen,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportTransitions writes the status changes recorded in the items' status
+// comments, oldest first. The entry the scanner adds for items without one
+// is not a transition and is skipped.
+func exportTransitions(out exportWriter, items []*entities.Item, board *entities.Board) error {
+	type transition struct {
+		item *entities.Item
+		from string
+		to   entities.StatusHistory
+	}
+
+	var transitions []transition
+	for _, item := range items {
+		if !item.InBoard(board.ID) {
+			continue
+		}
+
+		from := ""
+		for _, h := range item.History {
+			if !h.Timestamp.Before(item.CreatedAt) {
+				continue
+			}
+			transitions = append(transitions, transition{item: item, from: from, to: h})
+			from = string(board.StatusFor(h.Status))
+		}
+	}
+
+	slices.SortStableFunc(transitions, func(a, b transition) int {
+		return a.to.Timestamp.Compare(b.to.Timestamp)
+	})
+
+	for _, t := range transitions {
+		if err := out.Row([]any{
+			t.item.Key, string(t.item.Type), t.item.Title, t.item.File,
+			t.from, string(board.StatusFor(t.to.Status)), t.to.Timestamp, t.to.User,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportSnapshots(out exportWriter, snapshots []entities.BranchSnapshot, board *entities.Board, firstSeen map[string]time.Time, settings *entities.Settings) error {
+	for _, snapshot := range snapshots {
+		stats := snapshotBoardStats(snapshot, board)
+
+		var debt any
+		if d := debtOf(snapshot, firstSeen, settings); d != nil {
+			debt = d["."]
+		}
+
+		if err := out.Row([]any{
+			snapshot.Timestamp, snapshot.Branch, snapshot.Commit, snapshot.CommitShort, snapshot.CommitMessage,
+			snapshot.Backfilled, string(snapshot.Rollup), stats.Total, stats.Done, stats.Total - stats.Done, debt,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportSnapshotCounts writes the per status, type and priority counts of
+// each snapshot in long form, one row per snapshot and value.
+func exportSnapshotCounts(out exportWriter, snapshots []entities.BranchSnapshot, board *entities.Board) error {
+	for _, snapshot := range snapshots {
+		stats := snapshotBoardStats(snapshot, board)
+
+		for _, dimension := range []struct {
+			name   string
+			counts map[string]int
+		}{{"status", stats.ByStatus}, {"type", stats.ByType}, {"priority", stats.ByPriority}} {
+			values := make([]string, 0, len(dimension.counts))
+			for value := range dimension.counts {
+				values = append(values, value)
+			}
+			slices.Sort(values)
+
+			for _, value := range values {
+				if err := out.Row([]any{
+					snapshot.Timestamp, snapshot.Commit, dimension.name, value, dimension.counts[value],
+				}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type exportWriter interface {
+	Header(columns []string) error
+	Row(values []any) error
+	Flush() error
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer) *csvExportWriter {
+	return &csvExportWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvExportWriter) Header(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvExportWriter) Row(values []any) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			record[i] = v
+		case time.Time:
+			record[i] = v.UTC().Format(time.RFC3339)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case []string:
+			record[i] = strings.Join(v, ";")
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvExportWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonExportWriter struct {
+	w       io.Writer
+	columns []string
+}
+
+func (n *ndjsonExportWriter) Header(columns []string) error {
+	n.columns = columns
+	return nil
+}
+
+// Row writes one JSON object with the keys in column order.
+func (n *ndjsonExportWriter) Row(values []any) error {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format(time.RFC3339)
+		}
+		if v, ok := value.([]string); ok && v == nil {
+			value = []string{}
+		}
+
+		key, _ := json.Marshal(n.columns[i])
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(data)
+	}
+	buf.WriteString("}\n")
+
+	_, err := n.w.Write(buf.Bytes())
+	return err
+}
+
+func (n *ndjsonExportWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+func exportTestItems() []*entities.Item {
+	doneAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	doneBy := "tester"
+	issue := 7
+	return []*entities.Item{
+		{
+			ID: 1, Key: "a.go:todo:fix", Type: "TODO", Title: `fix, "this"`, File: "a.go", Line: 3,
+			Status: "todo", Priority: "HIGH", Stale: true,
+		},
+		{
+			ID: 2, Key: "b.go:fixme:done", Type: "FIXME", Title: "done", File: "b.go", Line: 9,
+			Status: "done", IsDone: true, DoneAt: &doneAt, DoneBy: &doneBy, IssueNumber: &issue,
+		},
+		{
+			ID: 3, Key: "c.go:todo:other", Type: "TODO", Title: "other board", File: "c.go", Line: 1,
+			Status: "todo", Boards: []string{"other"},
+		},
+	}
+}
+
+func TestExportItemsCSV(t *testing.T) {
+	config := newTestProject(t, nil)
+	scanner := newTestScanner(t, config)
+
+	var out bytes.Buffer
+	err := scanner.historyService.Export(&out, scanner.settings, exportTestItems(), ExportOptions{Dataset: "items", Format: ExportCSV})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want a header and the two items of the default board", len(records))
+	}
+	if got := strings.Join(records[0], ","); got != strings.Join(exportColumns["items"], ",") {
+		t.Errorf("header = %s", got)
+	}
+
+	want := [][]string{
+		{"1", "a.go:todo:fix", "TODO", `fix, "this"`, "", "a.go", "3", "todo", "HIGH", "false", "", "", "", "true", "", ""},
+		{"2", "b.go:fixme:done", "FIXME", "done", "", "b.go", "9", "done", "", "true", "2024-05-02T10:00:00Z", "tester", "7", "false", "", ""},
+	}
+	for i, row := range want {
+		if got := records[i+1]; strings.Join(got, "|") != strings.Join(row, "|") {
+			t.Errorf("row %d = %q, want %q", i+1, got, row)
+		}
+	}
+}
+
+func TestExportItemsNDJSON(t *testing.T) {
+	config := newTestProject(t, nil)
+	scanner := newTestScanner(t, config)
+
+	var out bytes.Buffer
+	err := scanner.historyService.Export(&out, scanner.settings, exportTestItems(), ExportOptions{Dataset: "items", Format: ExportNDJSON, BoardID: entities.DefaultBoardID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want one per item of the board:\n%s", len(lines), out.String())
+	}
+
+	// Keys come in column order, and empty values are null.
+	if !strings.HasPrefix(lines[0], `{"id":1,"key":"a.go:todo:fix","type":"TODO",`) {
+		t.Errorf("first line = %s", lines[0])
+	}
+
+	var row map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &row); err != nil {
+		t.Fatal(err)
+	}
+	if row["done_at"] != "2024-05-02T10:00:00Z" || row["issue_number"] != float64(7) || row["is_done"] != true {
+		t.Errorf("second row = %v", row)
+	}
+	if boards, ok := row["boards"].([]any); !ok || len(boards) != 0 {
+		t.Errorf("boards = %v, want an empty list", row["boards"])
+	}
+	if row["first_seen_at"] != nil {
+		t.Errorf("first_seen_at = %v, want null without a lifecycle", row["first_seen_at"])
+	}
+}
+
+func TestExportOptionsValidate(t *testing.T) {
+	if err := (ExportOptions{Dataset: "notes", Format: ExportCSV}).Validate(); err == nil {
+		t.Error("accepted an unknown dataset")
+	}
+	if err := (ExportOptions{Dataset: "items", Format: "xml"}).Validate(); err == nil {
+		t.Error("accepted an unknown format")
+	}
+}
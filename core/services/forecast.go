@@ -0,0 +1,257 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+const (
+	defaultForecastWindow = 90
+	defaultForecastTrials = 10000
+	maxForecastTrials     = 100000
+	maxForecastDays       = 10 * 365
+)
+
+var forecastPercentiles = []int{50, 85, 95}
+
+type ForecastOptions struct {
+	BoardID string
+	// Branch picks the snapshots completions are taken from; it defaults
+	// to the checked out branch.
+	Branch string
+	Query  *ItemQuery
+	// Column limits the remaining work to one column; by default every
+	// item that is not done counts.
+	Column string
+	Until  time.Time
+	Window int
+	Trials int
+	Seed   uint64
+}
+
+// Forecast runs a Monte Carlo simulation over the daily throughput of the
+// last Window days: how long until the remaining items are done, and how
+// many items will be done by Until.
+func (c *ChartService) Forecast(opts ForecastOptions, items []*entities.Item) (*entities.Forecast, error) {
+	board, err := c.historyService.resolveBoard(c.settings, opts.BoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Column != "" && board.GetColumn(opts.Column) == nil {
+		return nil, errors.New("column not found")
+	}
+	if opts.Window <= 0 {
+		opts.Window = defaultForecastWindow
+	}
+	if opts.Trials <= 0 {
+		opts.Trials = defaultForecastTrials
+	}
+	opts.Trials = min(opts.Trials, maxForecastTrials)
+	opts.Query = opts.Query.WithFirstSeen(c.historyService.GetFirstSeen())
+	opts.Branch = c.historyService.resolveBranch(opts.Branch)
+
+	today := startOfDay(time.Now())
+	if !opts.Until.IsZero() && startOfDay(opts.Until).Before(today) {
+		return nil, errors.New("until must not be in the past")
+	}
+	// The volume simulation costs days times trials.
+	if !opts.Until.IsZero() && chartDaysBetween(today, startOfDay(opts.Until)) > maxForecastDays {
+		return nil, fmt.Errorf("until must be within %d days", maxForecastDays)
+	}
+
+	doneColumnID := board.Columns[len(board.Columns)-1].ID
+
+	forecast := &entities.Forecast{
+		Board:      board.ID,
+		Branch:     opts.Branch,
+		Column:     opts.Column,
+		Trials:     opts.Trials,
+		Throughput: []entities.ThroughputSample{},
+	}
+	if opts.Query != nil {
+		forecast.Query = opts.Query.Raw
+	}
+
+	for _, item := range items {
+		if !item.InBoard(board.ID) || !opts.Query.Match(item) {
+			continue
+		}
+		status := string(board.StatusFor(item.Status))
+		if opts.Column != "" && status != opts.Column {
+			continue
+		}
+		if opts.Column == "" && (status == doneColumnID || item.IsDone) {
+			continue
+		}
+		forecast.Remaining++
+	}
+
+	samples := c.dailyThroughput(board, opts, items, today)
+	forecast.WindowDays = len(samples)
+
+	total := 0
+	for i, completed := range samples {
+		total += completed
+		forecast.Throughput = append(forecast.Throughput, entities.ThroughputSample{
+			Date:      today.AddDate(0, 0, i-len(samples)+1).Format("2006-01-02"),
+			Completed: completed,
+		})
+	}
+	if len(samples) > 0 {
+		forecast.AverageThroughput = float64(total) / float64(len(samples))
+	}
+
+	if total == 0 {
+		forecast.Message = "no items were completed in the throughput window"
+		return forecast, nil
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	forecast.Completion = simulateCompletion(rng, samples, forecast.Remaining, opts.Trials, today)
+
+	if !opts.Until.IsZero() {
+		until := startOfDay(opts.Until)
+		forecast.Until = until.Format("2006-01-02")
+		forecast.ItemsByDate = simulateVolume(rng, samples, chartDaysBetween(today, until), opts.Trials)
+	}
+
+	return forecast, nil
+}
+
+// dailyThroughput counts completions per day, oldest first. A completion is
+// an item entering the last column, taken from its status history, from
+// snapshots of opts.Branch, or from its removal from code; each item counts
+// once. The window never starts before the first snapshot of the branch.
+func (c *ChartService) dailyThroughput(board *entities.Board, opts ForecastOptions, items []*entities.Item, today time.Time) []int {
+	doneColumnID := board.Columns[len(board.Columns)-1].ID
+	completions := make(map[string]time.Time)
+
+	for _, item := range items {
+		if !item.InBoard(board.ID) || !opts.Query.Match(item) {
+			continue
+		}
+		for _, h := range item.History {
+			if string(board.StatusFor(h.Status)) == doneColumnID {
+				completions[item.Key] = h.Timestamp
+			}
+		}
+	}
+
+	lifecycles := c.historyService.GetLifecycles()
+	firstSeen := make(map[string]time.Time, len(lifecycles))
+	for _, lifecycle := range lifecycles {
+		firstSeen[lifecycle.Key] = lifecycle.FirstSeenAt
+		if !lifecycle.IsRemoved() || !lifecycle.InBoard(board.ID) {
+			continue
+		}
+		if _, ok := completions[lifecycle.Key]; ok || !opts.Query.Match(lifecycleQueryItem(lifecycle)) {
+			continue
+		}
+		completions[lifecycle.Key] = *lifecycle.RemovedAt
+	}
+
+	branchSnapshots := c.historyService.branchSnapshots(opts.Branch)
+	snapshots := make([]entities.BranchSnapshot, 0, len(branchSnapshots))
+	for _, snapshot := range branchSnapshots {
+		if snapshot.Rollup == "" {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	for i := 1; i < len(snapshots); i++ {
+		previousDone := make(map[string]bool)
+		for _, item := range boardTaskItems(snapshots[i-1].History.Items, board) {
+			previousDone[item.GetKey()] = string(item.Status) == doneColumnID || item.IsDone
+		}
+		for _, item := range boardTaskItems(snapshots[i].History.Items, board) {
+			key := item.GetKey()
+			wasDone, existed := previousDone[key]
+			if !existed || wasDone || (string(item.Status) != doneColumnID && !item.IsDone) {
+				continue
+			}
+			if _, ok := completions[key]; ok || !opts.Query.Match(taskQueryItem(item, firstSeen[key])) {
+				continue
+			}
+			completions[key] = snapshots[i].Timestamp
+		}
+	}
+
+	window := opts.Window
+	if len(branchSnapshots) > 0 {
+		earliest := branchSnapshots[0].Timestamp
+		window = max(1, min(window, chartDaysBetween(startOfDay(earliest), today)+1))
+	}
+
+	samples := make([]int, window)
+	start := today.AddDate(0, 0, -window+1)
+	for _, completedAt := range completions {
+		day := chartDaysBetween(start, startOfDay(completedAt))
+		if day >= 0 && day < window {
+			samples[day]++
+		}
+	}
+
+	return samples
+}
+
+func simulateCompletion(rng *rand.Rand, samples []int, remaining, trials int, today time.Time) []entities.CompletionPercentile {
+	outcomes := make([]int, trials)
+	for t := range outcomes {
+		days, done := 0, 0
+		for done < remaining && days < maxForecastDays {
+			done += samples[rng.IntN(len(samples))]
+			days++
+		}
+		outcomes[t] = days
+	}
+	slices.Sort(outcomes)
+
+	result := []entities.CompletionPercentile{}
+	for _, p := range forecastPercentiles {
+		days := outcomes[percentileIndex(len(outcomes), float64(p))]
+		result = append(result, entities.CompletionPercentile{
+			Percentile: p,
+			Days:       days,
+			Date:       today.AddDate(0, 0, days),
+		})
+	}
+	return result
+}
+
+func simulateVolume(rng *rand.Rand, samples []int, days, trials int) []entities.VolumePercentile {
+	outcomes := make([]int, trials)
+	for t := range outcomes {
+		done := 0
+		for d := 0; d < days; d++ {
+			done += samples[rng.IntN(len(samples))]
+		}
+		outcomes[t] = done
+	}
+	slices.Sort(outcomes)
+
+	// "At least n items with p% probability" reads from the low end.
+	result := []entities.VolumePercentile{}
+	for _, p := range forecastPercentiles {
+		result = append(result, entities.VolumePercentile{
+			Percentile: p,
+			Items:      outcomes[percentileIndex(len(outcomes), float64(100-p))],
+		})
+	}
+	return result
+}
+
+func percentileIndex(n int, p float64) int {
+	i := int(math.Ceil(p/100*float64(n))) - 1
+	return max(0, min(n-1, i))
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"go.uber.org/zap"
+)
+
+func TestForecastLimitsHorizon(t *testing.T) {
+	config := newTestProject(t, nil)
+	scanner := newTestScanner(t, config)
+	charts := NewChartService(zap.NewNop(), scanner.settings, scanner.historyService, nil)
+
+	today := startOfDay(time.Now())
+	_, err := charts.Forecast(ForecastOptions{Until: today.AddDate(0, 0, maxForecastDays+1)}, nil)
+	if err == nil {
+		t.Fatal("accepted a horizon beyond maxForecastDays")
+	}
+
+	if _, err := charts.Forecast(ForecastOptions{Until: today.AddDate(0, 0, 30)}, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDailyThroughputStaysOnBranch(t *testing.T) {
+	config := newTestProject(t, nil)
+	scanner := newTestScanner(t, config)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+	charts := NewChartService(zap.NewNop(), scanner.settings, scanner.historyService, nil)
+
+	settings, err := scanner.settings.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := settings.GetBoards()[0]
+	doneColumnID := board.Columns[len(board.Columns)-1].ID
+
+	// The item is done on main and open on the feature branch, so
+	// switching between them must not count as a completion.
+	today := startOfDay(time.Now())
+	open := testSnapshot("feature", "a", today.Add(time.Hour))
+	done := testSnapshot("main", "b", today.Add(2*time.Hour))
+	done.History.Items[0].Key = "a"
+	done.History.Items[0].Status = entities.ItemStatus(doneColumnID)
+	done.History.Items[0].IsDone = true
+	snapshots := []entities.BranchSnapshot{open, done}
+	for i := range snapshots {
+		snapshots[i].Backfilled = true
+	}
+	if _, _, _, err := scanner.historyService.MergeBackfill(snapshots, scanner.settings); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, branch := range []string{"main", "feature"} {
+		samples := charts.dailyThroughput(&board, ForecastOptions{Branch: branch, Window: 7}, nil, today)
+		total := 0
+		for _, n := range samples {
+			total += n
+		}
+		if total != 0 {
+			t.Errorf("branch %s counts %d completions, want none", branch, total)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+const (
+	defaultHotspotWindow = 90
+	defaultHotspotLimit  = 20
+)
+
+type HotspotOptions struct {
+	BoardID string
+	Window  int
+	Limit   int
+	// Depth limits directories to that many path segments; zero keeps
+	// every level.
+	Depth int
+}
+
+type fileChurn struct {
+	commits map[string]bool
+	lines   int
+}
+
+// GetHotspots ranks files and directories by open items times the number of
+// commits that touched them in the window.
+func (pt *HistoryService) GetHotspots(settings *SettingsService, opts HotspotOptions) (*entities.HotspotReport, error) {
+	history, err := pt.LoadStats()
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		return nil, errors.New("no history available")
+	}
+
+	board, err := pt.resolveBoard(settings, opts.BoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Window <= 0 {
+		opts.Window = defaultHotspotWindow
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultHotspotLimit
+	}
+
+	since := startOfDay(time.Now()).AddDate(0, 0, -opts.Window)
+	churn, commits, err := gitChurn(since)
+	if err != nil {
+		return nil, err
+	}
+
+	currentSettings, err := settings.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	highPriority := entities.ItemPriority(currentSettings.PriorityPatterns.High)
+	doneColumnID := board.Columns[len(board.Columns)-1].ID
+
+	files := make(map[string]*entities.Hotspot)
+	dirs := make(map[string]*entities.Hotspot)
+	dirCommits := make(map[string]map[string]bool)
+
+	for _, item := range boardTaskItems(history.CurrentItems, board) {
+		if string(item.Status) == doneColumnID || item.IsDone {
+			continue
+		}
+
+		file := filepath.ToSlash(item.File)
+		hotspot, ok := files[file]
+		if !ok {
+			hotspot = &entities.Hotspot{Path: file}
+			if c, ok := churn[file]; ok {
+				hotspot.Commits = len(c.commits)
+				hotspot.LinesChanged = c.lines
+			}
+			files[file] = hotspot
+
+			for _, dir := range parentDirs(file, opts.Depth) {
+				if _, ok := dirs[dir]; !ok {
+					dirs[dir] = &entities.Hotspot{Path: dir}
+					dirCommits[dir] = make(map[string]bool)
+				}
+			}
+		}
+
+		hotspot.Items++
+		if item.Priority == highPriority {
+			hotspot.HighPriority++
+		}
+		for _, dir := range parentDirs(file, opts.Depth) {
+			dirs[dir].Items++
+			if item.Priority == highPriority {
+				dirs[dir].HighPriority++
+			}
+		}
+	}
+
+	// Directories count every commit under them once, including commits to
+	// files without items.
+	for file, c := range churn {
+		for _, dir := range parentDirs(file, opts.Depth) {
+			hotspot, ok := dirs[dir]
+			if !ok {
+				continue
+			}
+			hotspot.LinesChanged += c.lines
+			for hash := range c.commits {
+				dirCommits[dir][hash] = true
+			}
+		}
+	}
+	for dir, hashes := range dirCommits {
+		dirs[dir].Commits = len(hashes)
+	}
+
+	return &entities.HotspotReport{
+		Since:       since,
+		WindowDays:  opts.Window,
+		Commits:     commits,
+		Files:       rankHotspots(files, opts.Limit),
+		Directories: rankHotspots(dirs, opts.Limit),
+	}, nil
+}
+
+func rankHotspots(hotspots map[string]*entities.Hotspot, limit int) []entities.Hotspot {
+	ranked := make([]entities.Hotspot, 0, len(hotspots))
+	for _, hotspot := range hotspots {
+		hotspot.Score = hotspot.Items * hotspot.Commits
+		ranked = append(ranked, *hotspot)
+	}
+
+	slices.SortFunc(ranked, func(a, b entities.Hotspot) int {
+		if a.Score != b.Score {
+			return b.Score - a.Score
+		}
+		if a.Items != b.Items {
+			return b.Items - a.Items
+		}
+		if a.Commits != b.Commits {
+			return b.Commits - a.Commits
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// parentDirs returns the directories containing file, outermost first,
+// leaving out the project root.
+func parentDirs(file string, depth int) []string {
+	var dirs []string
+	for dir := path.Dir(file); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+	slices.Reverse(dirs)
+
+	if depth > 0 && len(dirs) > depth {
+		dirs = dirs[:depth]
+	}
+	return dirs
+}
+
+// gitChurn collects the commits and changed lines per file since the given
+// time, with paths relative to the working directory.
+func gitChurn(since time.Time) (map[string]*fileChurn, int, error) {
+	out, err := exec.Command("git", "log", "--no-merges", "--no-renames", "--relative",
+		"--since="+since.Format(time.RFC3339), "--format=%x1e%H", "--numstat").Output()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read git log: %v", err)
+	}
+
+	churn := make(map[string]*fileChurn)
+	commits := 0
+	for _, record := range strings.Split(string(out), "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		if len(lines) == 0 || lines[0] == "" {
+			continue
+		}
+
+		hash := lines[0]
+		commits++
+		for _, line := range lines[1:] {
+			fields := strings.SplitN(line, "\t", 3)
+			if len(fields) != 3 {
+				continue
+			}
+
+			c, ok := churn[fields[2]]
+			if !ok {
+				c = &fileChurn{commits: make(map[string]bool)}
+				churn[fields[2]] = c
+			}
+			c.commits[hash] = true
+
+			// Binary files report "-" for both counts.
+			added, _ := strconv.Atoi(fields[0])
+			deleted, _ := strconv.Atoi(fields[1])
+			c.lines += added + deleted
+		}
+	}
+
+	return churn, commits, nil
+}
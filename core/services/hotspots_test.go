@@ -0,0 +1,76 @@
+package services
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestHotspotsRankByItemsTimesCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	config := newTestProject(t, map[string]string{
+		"pkg/a.go": "package pkg\n\n// TODO: one\nvar x = 1\n\n// TODO: two\nfunc a() {}\n",
+		"pkg/b.go": "package pkg\n\n// TODO: three\nfunc b() {}\n",
+		"lib/c.go": "package lib\n\n// TODO: four\nvar x = 1\n\n// TODO: five\nvar y = 2\n\n// TODO: six\nfunc c() {}\n",
+	})
+	runGit(t, "init", "-q")
+	runGit(t, "add", ".")
+	runGit(t, "commit", "-qm", "add items")
+
+	// a.go churns, and pkg gets a commit to a file without items.
+	for _, body := range []string{"func a() { _ = 1 }\n", "func a() { _ = 2 }\n"} {
+		writeTestFile(t, "pkg/a.go", "package pkg\n\n// TODO: one\nvar x = 1\n\n// TODO: two\n"+body)
+		runGit(t, "commit", "-qam", "edit a")
+	}
+	writeTestFile(t, "pkg/plain.go", "package pkg\n")
+	runGit(t, "add", "pkg/plain.go")
+	runGit(t, "commit", "-qm", "add plain")
+
+	scanner := newTestScanner(t, config)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := scanner.historyService.GetHotspots(scanner.settings, HotspotOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Commits != 4 {
+		t.Errorf("report counts %d commits, want 4", report.Commits)
+	}
+
+	wantFiles := []struct {
+		path                   string
+		items, commits, scored int
+	}{
+		{"pkg/a.go", 2, 3, 6},
+		{"lib/c.go", 3, 1, 3},
+		{"pkg/b.go", 1, 1, 1},
+	}
+	if len(report.Files) != len(wantFiles) {
+		t.Fatalf("got %d file hotspots, want %d", len(report.Files), len(wantFiles))
+	}
+	for i, want := range wantFiles {
+		got := report.Files[i]
+		if got.Path != want.path || got.Items != want.items || got.Commits != want.commits || got.Score != want.scored {
+			t.Errorf("file %d = %+v, want %s with %d items x %d commits", i, got, want.path, want.items, want.commits)
+		}
+	}
+
+	if len(report.Directories) != 2 {
+		t.Fatalf("got %d directory hotspots, want 2", len(report.Directories))
+	}
+	if pkg := report.Directories[0]; pkg.Path != "pkg" || pkg.Items != 3 || pkg.Commits != 4 || pkg.Score != 12 {
+		t.Errorf("top directory = %+v, want pkg with 3 items x 4 commits", pkg)
+	}
+
+	limited, err := scanner.historyService.GetHotspots(scanner.settings, HotspotOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited.Files) != 1 || limited.Files[0].Path != "pkg/a.go" {
+		t.Errorf("limited files = %+v, want only pkg/a.go", limited.Files)
+	}
+}
@@ -1,35 +1,42 @@
 package services
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
 	"go.uber.org/zap"
 )
 
+var errNoHistory = errors.New("no history available")
+
 type HistoryService struct {
-	config    *entities.Config
-	logger    *zap.Logger
-	kodoDir   string
-	statsFile string
+	config  *entities.Config
+	logger  *zap.Logger
+	kodoDir string
+	store   storage.Store
+
+	checkingRemovals atomic.Bool
+	removalChecks    sync.WaitGroup
 }
 
-func NewHistoryService(config *entities.Config, logger *zap.Logger) *HistoryService {
+func NewHistoryService(config *entities.Config, logger *zap.Logger, store storage.Store) *HistoryService {
 	wd, _ := os.Getwd()
-	kodoDir := filepath.Join(wd, config.Flags.Config)
 
 	return &HistoryService{
-		config:    config,
-		logger:    logger,
-		kodoDir:   kodoDir,
-		statsFile: filepath.Join(kodoDir, "items.json"),
+		config:  config,
+		logger:  logger,
+		kodoDir: filepath.Join(wd, config.Flags.Config),
+		store:   store,
 	}
 }
 
@@ -45,10 +52,18 @@ func (pt *HistoryService) Initialize() error {
 # Kodo temporary files
 *.tmp
 *.log
+*.bak
+.lock
+.*.lock
 
 # Keep the history but ignore temporary data
 !notes.json
 !items.json
+!comments.json
+!links.json
+!item_issues.json
+!sprints.json
+!automation.json
 		`
 		if err := os.WriteFile(gitignoreFile, []byte(strings.TrimSpace(gitignoreContent)), 0644); err != nil {
 			pt.logger.Error("Failed to create .gitignore", zap.Error(err))
@@ -58,25 +73,25 @@ func (pt *HistoryService) Initialize() error {
 	return nil
 }
 
-func (pt *HistoryService) generateItemHash(item *entities.Item) string {
-
-	content := fmt.Sprintf("%s:%d:%s:%s", item.File, item.Line, item.Type, item.Title)
-	hash := 0
-	for _, char := range content {
-		hash = hash*31 + int(char)
-	}
-	return fmt.Sprintf("%x", hash)
-}
-
 func (pt *HistoryService) GetTaskItemsAnalysis(settings *SettingsService) map[string]interface{} {
-	history := pt.LoadStats()
+	history, err := pt.LoadStats()
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
 	if history == nil {
 		return map[string]interface{}{
 			"error": "No history available",
 		}
 	}
 
-	currentSettings := settings.LoadSettings()
+	currentSettings, err := settings.LoadSettings()
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
 
 	highPriorityKey := entities.ItemPriority(currentSettings.PriorityPatterns.High)
 
@@ -133,67 +148,133 @@ func (pt *HistoryService) GetRecentItemChanges() map[string]interface{} {
 		}
 	}
 
-	current := history[len(history)-1]
-	previous := history[len(history)-2]
+	current := history[0]
+	previous := history[1]
 
-	currentItems := make(map[string]entities.TaskItem)
-	previousItems := make(map[string]entities.TaskItem)
+	diff := diffTaskItems(previous.History.Items, current.History.Items)
 
-	for _, item := range current.History.Items {
-		currentItems[item.Hash] = item
+	return map[string]interface{}{
+		"added":          diff.Added,
+		"removed":        diff.Removed,
+		"changed":        diff.Changed,
+		"status_changed": diff.StatusChanged,
+		"summary":        diff.Summary(),
 	}
+}
+
+type itemDiff struct {
+	Added         []entities.TaskItem
+	Removed       []entities.TaskItem
+	Changed       []map[string]interface{}
+	StatusChanged []map[string]interface{}
+}
 
-	for _, item := range previous.History.Items {
-		previousItems[item.Hash] = item
+func (d *itemDiff) Summary() map[string]int {
+	return map[string]int{
+		"added":          len(d.Added),
+		"removed":        len(d.Removed),
+		"changed":        len(d.Changed),
+		"status_changed": len(d.StatusChanged),
 	}
+}
 
-	var added []entities.TaskItem
-	var removed []entities.TaskItem
-	var statusChanged []map[string]interface{}
+// diffTaskItems pairs items by key first and then by similarity, so moved
+// and reworded items are reported as changes; items with the same key are
+// compared by content. Hash is not used since older snapshots hashed the
+// content into it.
+func diffTaskItems(previousItems, currentItems []entities.TaskItem) *itemDiff {
+	diff := &itemDiff{
+		Added:         []entities.TaskItem{},
+		Removed:       []entities.TaskItem{},
+		Changed:       []map[string]interface{}{},
+		StatusChanged: []map[string]interface{}{},
+	}
 
-	for hash, item := range currentItems {
-		if _, exists := previousItems[hash]; !exists {
-			added = append(added, item)
-		} else {
+	previousByKey := make(map[string]entities.TaskItem)
+	for _, item := range previousItems {
+		previousByKey[item.GetKey()] = item
+	}
 
-			prevItem := previousItems[hash]
-			if item.Status != prevItem.Status {
-				statusChanged = append(statusChanged, map[string]interface{}{
-					"item":       item,
-					"old_status": prevItem.Status,
-					"new_status": item.Status,
-				})
-			}
+	currentKeys := make(map[string]bool)
+	for _, item := range currentItems {
+		currentKeys[item.GetKey()] = true
+	}
+
+	var added, removed []entities.TaskItem
+	for _, item := range currentItems {
+		prevItem, exists := previousByKey[item.GetKey()]
+		if !exists {
+			added = append(added, item)
+			continue
+		}
+		if item.Status != prevItem.Status {
+			diff.StatusChanged = append(diff.StatusChanged, map[string]interface{}{
+				"item":       item,
+				"old_status": prevItem.Status,
+				"new_status": item.Status,
+			})
+		}
+		match := itemMatch{Previous: prevItem, Current: item, Similarity: 1}
+		if changes := match.Changes(); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, map[string]interface{}{
+				"item":       item,
+				"previous":   prevItem,
+				"changes":    changes,
+				"similarity": match.Similarity,
+			})
 		}
 	}
 
-	for hash, item := range previousItems {
-		if _, exists := currentItems[hash]; !exists {
+	for _, item := range previousItems {
+		if !currentKeys[item.GetKey()] {
 			removed = append(removed, item)
 		}
 	}
 
-	return map[string]interface{}{
-		"added":          added,
-		"removed":        removed,
-		"status_changed": statusChanged,
-		"summary": map[string]int{
-			"added":          len(added),
-			"removed":        len(removed),
-			"status_changed": len(statusChanged),
-		},
+	matches, removed, added := matchItems(removed, added)
+	for _, match := range matches {
+		if match.Current.Status != match.Previous.Status {
+			diff.StatusChanged = append(diff.StatusChanged, map[string]interface{}{
+				"item":       match.Current,
+				"old_status": match.Previous.Status,
+				"new_status": match.Current.Status,
+			})
+		}
+		if changes := match.Changes(); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, map[string]interface{}{
+				"item":       match.Current,
+				"previous":   match.Previous,
+				"changes":    changes,
+				"similarity": match.Similarity,
+			})
+		}
 	}
+
+	diff.Added = append(diff.Added, added...)
+	diff.Removed = append(diff.Removed, removed...)
+
+	return diff
 }
 
 func (pt *HistoryService) GetItemsByFile(settings *SettingsService) map[string]interface{} {
-	history := pt.LoadStats()
+	history, err := pt.LoadStats()
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
 	if history == nil {
 		return map[string]interface{}{
 			"error": "No history available",
 		}
 	}
 
-	currentSettings := settings.LoadSettings()
+	currentSettings, err := settings.LoadSettings()
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
 
 	statusKeys := make(map[entities.ItemStatus]string)
 	for _, col := range currentSettings.KanbanColumns {
@@ -242,7 +323,7 @@ func (pt *HistoryService) GetItemsByFile(settings *SettingsService) map[string]i
 	}
 }
 
-func (pt *HistoryService) GetItemTrends(settings *SettingsService) map[string]interface{} {
+func (pt *HistoryService) GetItemTrends(settings *SettingsService, boardID string) map[string]interface{} {
 	history := pt.GetBranchHistory()
 	if len(history) < 2 {
 		return map[string]interface{}{
@@ -250,15 +331,14 @@ func (pt *HistoryService) GetItemTrends(settings *SettingsService) map[string]in
 		}
 	}
 
-	currentSettings := settings.LoadSettings()
-	kanbanCols := currentSettings.KanbanColumns
-	if len(kanbanCols) == 0 {
+	board, err := pt.resolveBoard(settings, boardID)
+	if err != nil {
 		return map[string]interface{}{
-			"error": "No Kanban columns configured",
+			"error": err.Error(),
 		}
 	}
-
-	doneColumnID := kanbanCols[len(kanbanCols)-1].ID
+	kanbanCols := board.Columns
+	lifecycles := pt.GetLifecycles()
 
 	statusKeys := make(map[string]string)
 	for _, col := range kanbanCols {
@@ -272,37 +352,31 @@ func (pt *HistoryService) GetItemTrends(settings *SettingsService) map[string]in
 	}
 
 	for _, snapshot := range history {
+		stats := snapshotBoardStats(snapshot, board)
+		snapshotTotal := stats.Total
+
 		timelineEntry := map[string]interface{}{
 			"timestamp": snapshot.Timestamp,
 			"commit":    snapshot.CommitShort,
 			"branch":    snapshot.Branch,
-			"total":     snapshot.History.Total,
+			"total":     snapshotTotal,
+			"rollup":    snapshot.Rollup,
 		}
 
 		for statusID, name := range statusKeys {
-			count := 0
-			for _, item := range snapshot.History.Items {
-				if string(item.Status) == statusID {
-					count++
-				} else if statusID == doneColumnID && item.IsDone {
-					count++
-				}
-			}
-			timelineEntry[name] = count
+			timelineEntry[name] = stats.ByStatus[statusID]
 		}
 
 		trends["timeline"] = append(trends["timeline"].([]map[string]interface{}), timelineEntry)
 
-		doneCount := 0
-		for _, item := range snapshot.History.Items {
-			if item.IsDone {
-				doneCount++
-			}
-		}
+		doneCount := stats.Done
+
+		removedCount := countCompletedRemovals(lifecycles, board.ID, snapshot.Timestamp)
+		doneCount += removedCount
 
 		completionRate := 0.0
-		if snapshot.History.Total > 0 {
-			completionRate = float64(doneCount) / float64(snapshot.History.Total) * 100
+		if snapshotTotal+removedCount > 0 {
+			completionRate = float64(doneCount) / float64(snapshotTotal+removedCount) * 100
 		}
 
 		trends["completion_rate"] = append(trends["completion_rate"].([]map[string]interface{}), map[string]interface{}{
@@ -311,7 +385,7 @@ func (pt *HistoryService) GetItemTrends(settings *SettingsService) map[string]in
 			"rate":      completionRate,
 		})
 
-		for itemType, count := range snapshot.History.ByType {
+		for itemType, count := range stats.ByType {
 			if trends["type_trends"].(map[string][]map[string]interface{})[itemType] == nil {
 				trends["type_trends"].(map[string][]map[string]interface{})[itemType] = []map[string]interface{}{}
 			}
@@ -341,44 +415,88 @@ func (pt *HistoryService) SaveStats(items []*entities.Item, settings *SettingsSe
 		return fmt.Errorf("failed to generate history: %v", err)
 	}
 
-	existingStats := pt.LoadStats()
-	if existingStats != nil {
-		history.CreatedAt = existingStats.CreatedAt
-		history.BranchHistory = existingStats.BranchHistory
-	} else {
-		history.CreatedAt = time.Now()
+	currentSettings, err := settings.LoadSettings()
+	if err != nil {
+		return err
 	}
 
-	if existingStats == nil || existingStats.GitCommit != history.GitCommit {
-		snapshot := entities.BranchSnapshot{
-			Branch:        history.GitBranch,
-			Commit:        history.GitCommit,
-			CommitShort:   history.GitCommitShort,
-			CommitMessage: pt.getCommitMessage(history.GitCommit),
-			Timestamp:     time.Now(),
-			History:       pt.generateItemStats(items, settings),
+	// Only the records that change are written: the stats, the lifecycles
+	// and, when the commit moved on, the snapshots. Snapshots are compacted
+	// as a new one is added.
+	var unchecked []entities.ItemLifecycle
+	err = pt.store.PatchStats(func(tx storage.StatsTx) error {
+		existing, err := tx.Stats()
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			history.CreatedAt = existing.CreatedAt
+		} else {
+			history.CreatedAt = time.Now()
 		}
 
-		history.BranchHistory = append(history.BranchHistory, snapshot)
+		if history.Lifecycles, err = tx.Lifecycles(); err != nil {
+			return err
+		}
+
+		if existing == nil || existing.GitCommit != history.GitCommit {
+			if history.BranchHistory, err = tx.Snapshots(); err != nil {
+				return err
+			}
+
+			snapshot := entities.BranchSnapshot{
+				Branch:        history.GitBranch,
+				Commit:        history.GitCommit,
+				CommitShort:   history.GitCommitShort,
+				CommitMessage: pt.getCommitMessage(history.GitCommit),
+				Timestamp:     time.Now(),
+				History:       pt.generateItemStats(items, currentSettings),
+				Boards:        pt.generateBoardStats(items, currentSettings),
+			}
 
-		if len(history.BranchHistory) > 50 {
-			history.BranchHistory = history.BranchHistory[len(history.BranchHistory)-50:]
+			firstSeen := lifecycleFirstSeen(history.Lifecycles)
+			snapshot.Debt = snapshotDebt(snapshot.History.Items, firstSeen, snapshot.Timestamp, currentSettings)
+			pt.alertDebtRegressions(history.BranchHistory, snapshot, firstSeen, currentSettings)
+
+			history.BranchHistory = append(history.BranchHistory, snapshot)
+			history.BranchHistory = compactSnapshots(history.BranchHistory, currentSettings.HistoryRetention, currentSettings, time.Now())
+			if err := tx.ReplaceSnapshots(history.BranchHistory); err != nil {
+				return err
+			}
+		}
+
+		removed := make(map[string]bool, len(history.Lifecycles))
+		for _, lifecycle := range history.Lifecycles {
+			removed[lifecycle.Key] = true
 		}
-	}
 
-	history.UpdatedAt = time.Now()
+		pt.updateLifecycles(history, items, currentSettings)
+		unchecked = uncheckedRemovals(history)
 
-	data, err := json.MarshalIndent(history, "", "  ")
+		for _, lifecycle := range history.Lifecycles {
+			delete(removed, lifecycle.Key)
+			if err := tx.PutLifecycle(lifecycle); err != nil {
+				return err
+			}
+		}
+		// Lifecycles of items that moved are stored under their new key.
+		for key := range removed {
+			if err := tx.DeleteLifecycle(key); err != nil {
+				return err
+			}
+		}
+
+		history.UpdatedAt = time.Now()
+		return tx.PutStats(history)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal history: %v", err)
+		return err
 	}
 
-	if err := os.WriteFile(pt.statsFile, data, 0644); err != nil {
-		return fmt.Errorf("failed to write history file: %v", err)
-	}
+	pt.checkRemovals(unchecked, history.GitCommit, currentSettings)
 
 	pt.logger.Info("Project history saved",
-		zap.String("file", pt.statsFile),
+		zap.String("storage", pt.store.Backend()),
 		zap.Int("total_items", history.TotalItems),
 		zap.String("branch", history.GitBranch),
 		zap.String("commit", history.GitCommitShort))
@@ -386,22 +504,14 @@ func (pt *HistoryService) SaveStats(items []*entities.Item, settings *SettingsSe
 	return nil
 }
 
-func (pt *HistoryService) LoadStats() *entities.ItemsHistory {
-	data, err := os.ReadFile(pt.statsFile)
+// LoadStats returns nil without an error when no history was saved yet.
+func (pt *HistoryService) LoadStats() (*entities.ItemsHistory, error) {
+	history, err := pt.store.LoadStats()
 	if err != nil {
-		if !os.IsNotExist(err) {
-			pt.logger.Warn("Failed to read history file", zap.Error(err))
-		}
-		return nil
+		return nil, fmt.Errorf("failed to load history: %v", err)
 	}
 
-	var history entities.ItemsHistory
-	if err := json.Unmarshal(data, &history); err != nil {
-		pt.logger.Error("Failed to unmarshal history", zap.Error(err))
-		return nil
-	}
-
-	return &history
+	return history, nil
 }
 
 func (pt *HistoryService) generateStats(items []*entities.Item) (*entities.ItemsHistory, error) {
@@ -429,16 +539,18 @@ func (pt *HistoryService) generateStats(items []*entities.Item) (*entities.Items
 
 		taskItem := entities.TaskItem{
 			ID:       item.ID,
+			Key:      item.Key,
 			Type:     item.Type,
 			Title:    item.Title,
 			File:     item.File,
 			Line:     item.Line,
 			Status:   item.Status,
 			Priority: item.Priority,
-			Hash:     pt.generateItemHash(item),
+			Hash:     item.Key,
 			IsDone:   item.IsDone,
 			DoneAt:   item.DoneAt,
 			DoneBy:   item.DoneBy,
+			Boards:   item.Boards,
 		}
 		taskItems = append(taskItems, taskItem)
 	}
@@ -457,11 +569,9 @@ func (pt *HistoryService) generateStats(items []*entities.Item) (*entities.Items
 	}, nil
 }
 
-func (pt *HistoryService) generateItemStats(items []*entities.Item, settings *SettingsService) entities.ItemStats {
-	currentSettings := settings.LoadSettings()
-
+func (pt *HistoryService) generateItemStats(items []*entities.Item, settings *entities.Settings) entities.ItemStats {
 	statusKeys := make(map[entities.ItemStatus]struct{})
-	for _, col := range currentSettings.KanbanColumns {
+	for _, col := range settings.KanbanColumns {
 		statusKeys[entities.ItemStatus(col.Name)] = struct{}{}
 	}
 
@@ -488,16 +598,18 @@ func (pt *HistoryService) generateItemStats(items []*entities.Item, settings *Se
 
 		taskItem := entities.TaskItem{
 			ID:       item.ID,
+			Key:      item.Key,
 			Type:     item.Type,
 			Title:    item.Title,
 			File:     item.File,
 			Line:     item.Line,
 			Status:   item.Status,
 			Priority: item.Priority,
-			Hash:     pt.generateItemHash(item),
+			Hash:     item.Key,
 			IsDone:   item.IsDone,
 			DoneAt:   item.DoneAt,
 			DoneBy:   item.DoneBy,
+			Boards:   item.Boards,
 		}
 		history.Items = append(history.Items, taskItem)
 	}
@@ -508,39 +620,120 @@ func (pt *HistoryService) generateItemStats(items []*entities.Item, settings *Se
 	return history
 }
 
-func (pt *HistoryService) GetProjectStats(settings *SettingsService) map[string]interface{} {
-	history := pt.LoadStats()
+func (pt *HistoryService) generateBoardStats(items []*entities.Item, settings *entities.Settings) map[string]entities.BoardStats {
+	boards := make(map[string]entities.BoardStats)
+
+	for _, board := range settings.GetBoards() {
+		stats := entities.BoardStats{
+			ByStatus:   make(map[string]int),
+			ByType:     make(map[string]int),
+			ByPriority: make(map[string]int),
+		}
+		for _, col := range board.Columns {
+			stats.ByStatus[col.ID] = 0
+		}
+
+		for _, item := range items {
+			if !item.InBoard(board.ID) {
+				continue
+			}
+			stats.Total++
+			stats.ByStatus[string(board.StatusFor(item.Status))]++
+			if item.IsDone {
+				stats.Done++
+			}
+			stats.ByType[string(item.Type)]++
+			stats.ByPriority[string(item.Priority)]++
+		}
+
+		boards[board.ID] = stats
+	}
+
+	return boards
+}
+
+func (pt *HistoryService) resolveBoard(settings *SettingsService, boardID string) (*entities.Board, error) {
+	board, ok := settings.GetBoard(boardID)
+	if !ok {
+		return nil, fmt.Errorf("board %q not found", boardID)
+	}
+	if len(board.Columns) == 0 {
+		return nil, fmt.Errorf("no Kanban columns configured")
+	}
+	return board, nil
+}
+
+func boardTaskItems(items []entities.TaskItem, board *entities.Board) []entities.TaskItem {
+	filtered := make([]entities.TaskItem, 0, len(items))
+	for _, item := range items {
+		if item.InBoard(board.ID) {
+			item.Status = board.StatusFor(item.Status)
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+func (pt *HistoryService) GetProjectStats(settings *SettingsService, boardID string) map[string]interface{} {
+	history, err := pt.LoadStats()
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
 	if history == nil {
 		return map[string]interface{}{
 			"error": "No history available",
 		}
 	}
 
-	currentSettings := settings.LoadSettings()
+	board, err := pt.resolveBoard(settings, boardID)
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
 
 	itemsByStatus := make(map[string]int)
-	for _, col := range currentSettings.KanbanColumns {
+	for _, col := range board.Columns {
 		itemsByStatus[col.ID] = 0
 	}
 
-	total := history.TotalItems
-	lastStatusID := currentSettings.KanbanColumns[len(currentSettings.KanbanColumns)-1].ID
+	currentItems := boardTaskItems(history.CurrentItems, board)
+	total := len(currentItems)
+	lastStatusID := board.Columns[len(board.Columns)-1].ID
 
-	for _, item := range history.CurrentItems {
+	itemsByType := make(map[string]int)
+	itemsByFile := make(map[string]int)
+	for _, item := range currentItems {
 		if item.IsDone {
 			itemsByStatus[lastStatusID]++
 		} else {
-			statusID := fmt.Sprint(item.ID)
-			itemsByStatus[statusID]++
+			itemsByStatus[string(item.Status)]++
 		}
+		itemsByType[string(item.Type)]++
+		itemsByFile[item.File]++
 	}
 
+	// Items removed from code count as completed; the archive splits them
+	// by how they were removed.
+	resolved := countRemovals(history.Lifecycles, board.ID, entities.RemovalResolved)
+	discarded := countRemovals(history.Lifecycles, board.ID, entities.RemovalDiscarded)
+	removed := countCompletedRemovals(history.Lifecycles, board.ID, time.Time{})
+	completed := itemsByStatus[lastStatusID] + removed
+
 	progressPercent := 0.0
-	if total > 0 {
-		progressPercent = float64(itemsByStatus[lastStatusID]) / float64(total) * 100
+	if total+removed > 0 {
+		progressPercent = float64(completed) / float64(total+removed) * 100
 	}
 
 	return map[string]interface{}{
+		"board":           board.ID,
+		"completed_items": completed,
+		"archived_items": map[string]int{
+			string(entities.RemovalResolved):  resolved,
+			string(entities.RemovalDiscarded): discarded,
+		},
 		"project_path":     history.ProjectPath,
 		"last_scan":        history.LastScanAt,
 		"git_branch":       history.GitBranch,
@@ -548,8 +741,8 @@ func (pt *HistoryService) GetProjectStats(settings *SettingsService) map[string]
 		"total_items":      total,
 		"items_by_status":  itemsByStatus,
 		"progress_percent": progressPercent,
-		"items_by_type":    history.ItemsByType,
-		"items_by_file":    history.ItemsByFile,
+		"items_by_type":    itemsByType,
+		"items_by_file":    itemsByFile,
 		"history_count":    len(history.BranchHistory),
 		"created_at":       history.CreatedAt,
 		"updated_at":       history.UpdatedAt,
@@ -557,7 +750,11 @@ func (pt *HistoryService) GetProjectStats(settings *SettingsService) map[string]
 }
 
 func (pt *HistoryService) GetBranchHistory() []entities.BranchSnapshot {
-	history := pt.LoadStats()
+	history, err := pt.LoadStats()
+	if err != nil {
+		pt.logger.Error("Failed to load branch history", zap.Error(err))
+		return nil
+	}
 	if history == nil {
 		return nil
 	}
@@ -575,6 +772,28 @@ func (pt *HistoryService) GetBranchHistory() []entities.BranchSnapshot {
 	return history.BranchHistory
 }
 
+// branchSnapshots returns the snapshots taken on one branch, oldest first.
+func (pt *HistoryService) branchSnapshots(branch string) []entities.BranchSnapshot {
+	snapshots := []entities.BranchSnapshot{}
+	for _, snapshot := range pt.GetBranchHistory() {
+		if snapshot.Branch == branch {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	slices.SortFunc(snapshots, func(a, b entities.BranchSnapshot) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+	return snapshots
+}
+
+// resolveBranch defaults an empty branch to the checked out one.
+func (pt *HistoryService) resolveBranch(branch string) string {
+	if branch != "" {
+		return branch
+	}
+	return pt.GetGitBranch()
+}
+
 func (pt *HistoryService) GetGitBranch() string {
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.Output()
@@ -625,104 +844,34 @@ func (pt *HistoryService) getCommitMessage(commit string) string {
 	return message
 }
 
-func (pt *HistoryService) CompareWithPreviousCommit(settings *SettingsService) map[string]interface{} {
-	history := pt.GetBranchHistory()
-	if len(history) < 2 {
-		return map[string]interface{}{
-			"error": "Not enough history for comparison",
-		}
-	}
-
-	current := history[len(history)-1]
-	previous := history[len(history)-2]
-
-	currentSettings := settings.LoadSettings()
-	kanbanCols := currentSettings.KanbanColumns
-	if len(kanbanCols) == 0 {
-		return map[string]interface{}{
-			"error": "No Kanban columns configured",
-		}
+func (pt *HistoryService) CleanupOldStats(settings *SettingsService) error {
+	currentSettings, err := settings.LoadSettings()
+	if err != nil {
+		return err
 	}
 
-	doneColumnID := kanbanCols[len(kanbanCols)-1].ID
-
-	changes := make(map[string]int)
-	for _, col := range kanbanCols {
-		currentCount := 0
-		previousCount := 0
-
-		for _, item := range current.History.Items {
-			if string(item.Status) == col.ID {
-				currentCount++
-			} else if col.ID == doneColumnID && item.IsDone {
-				currentCount++
-			}
-		}
-
-		for _, item := range previous.History.Items {
-			if string(item.Status) == col.ID {
-				previousCount++
-			} else if col.ID == doneColumnID && item.IsDone {
-				previousCount++
-			}
+	before, after := 0, 0
+	err = pt.store.UpdateStats(func(history *entities.ItemsHistory) error {
+		if history.CreatedAt.IsZero() {
+			return errNoHistory
 		}
 
-		changes[col.Name] = currentCount - previousCount
-	}
-
-	return map[string]interface{}{
-		"current": map[string]interface{}{
-			"commit":    current.CommitShort,
-			"branch":    current.Branch,
-			"timestamp": current.Timestamp,
-			"history":   current.History,
-		},
-		"previous": map[string]interface{}{
-			"commit":    previous.CommitShort,
-			"branch":    previous.Branch,
-			"timestamp": previous.Timestamp,
-			"history":   previous.History,
-		},
-		"changes": changes,
-	}
-}
-
-func (pt *HistoryService) CleanupOldStats() error {
-	history := pt.LoadStats()
-	if history == nil {
+		before = len(history.BranchHistory)
+		history.BranchHistory = compactSnapshots(history.BranchHistory, currentSettings.HistoryRetention, currentSettings, time.Now())
+		history.UpdatedAt = time.Now()
+		after = len(history.BranchHistory)
+		return nil
+	})
+	if errors.Is(err, errNoHistory) {
 		return nil
 	}
-
-	cutoff := time.Now().AddDate(0, 0, -30)
-	var filteredHistory []entities.BranchSnapshot
-
-	for _, snapshot := range history.BranchHistory {
-		if snapshot.Timestamp.After(cutoff) {
-			filteredHistory = append(filteredHistory, snapshot)
-		}
+	if err != nil {
+		return err
 	}
 
-	if len(filteredHistory) != len(history.BranchHistory) {
-		history.BranchHistory = filteredHistory
-		history.UpdatedAt = time.Now()
-
-		data, err := json.MarshalIndent(history, "", "  ")
-		if err != nil {
-			return err
-		}
-
-		if err := os.WriteFile(pt.statsFile, data, 0644); err != nil {
-			return err
-		}
-
-		pt.logger.Info("Cleaned up old history",
-			zap.Int("removed", len(history.BranchHistory)-len(filteredHistory)),
-			zap.Int("remaining", len(filteredHistory)))
-	}
+	pt.logger.Info("Compacted old history",
+		zap.Int("removed", before-after),
+		zap.Int("remaining", after))
 
 	return nil
 }
-
-func (s *HistoryService) CompareWithPrevious(settings *SettingsService) map[string]interface{} {
-	return s.CompareWithPreviousCommit(settings)
-}
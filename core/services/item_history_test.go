@@ -0,0 +1,69 @@
+package services
+
+import "testing"
+
+func TestDiffTaskItems(t *testing.T) {
+	scanner := newTestScanner(t, newTestProject(t, nil))
+	history := scanner.historyService
+
+	before := history.generateItemStats(scanSource(t, "a.go", `package a
+
+// TODO: fix this
+// first
+func one() {}
+
+// TODO: fix this
+// second
+func two() {}
+`), testSettings()).Items
+
+	edited := history.generateItemStats(scanSource(t, "a.go", `package a
+
+import "fmt"
+
+// TODO: fix this
+// first, reworded
+func one() {}
+
+// TODO: fix this
+// second
+func two() {}
+`), testSettings()).Items
+
+	diff := diffTaskItems(before, edited)
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 || len(diff.Changed) != 0 {
+		t.Errorf("editing a description: added %d, removed %d, changed %d, want no changes",
+			len(diff.Added), len(diff.Removed), len(diff.Changed))
+	}
+
+	removed := history.generateItemStats(scanSource(t, "a.go", `package a
+
+// TODO: fix this
+// first
+func one() {}
+`), testSettings()).Items
+
+	diff = diffTaskItems(before, removed)
+	if len(diff.Removed) != 1 || len(diff.Added) != 0 {
+		t.Errorf("removing one of two identical items: removed %d, added %d, want 1 and 0",
+			len(diff.Removed), len(diff.Added))
+	}
+
+	priority := history.generateItemStats(scanSource(t, "a.go", `package a
+
+// TODO: fix this
+// first
+func one() {}
+
+// TODO: fix this
+// HIGH
+// second
+func two() {}
+`), testSettings()).Items
+
+	diff = diffTaskItems(before, priority)
+	if len(diff.Changed) != 1 || len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Errorf("changing a priority: changed %d, added %d, removed %d, want 1, 0 and 0",
+			len(diff.Changed), len(diff.Added), len(diff.Removed))
+	}
+}
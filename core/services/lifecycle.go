@@ -0,0 +1,333 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
+	"go.uber.org/zap"
+)
+
+var resolvedCommitPattern = regexp.MustCompile(`(?i)\b(fix(es|ed)?|resolve[sd]?|close[sd]?|done|implement(s|ed)?|complete[sd]?)\b`)
+
+func (pt *HistoryService) updateLifecycles(history *entities.ItemsHistory, items []*entities.Item, currentSettings *entities.Settings) {
+	now := time.Now()
+
+	lifecycles := pt.indexLifecycles(history)
+
+	seen := make(map[string]bool)
+	for _, item := range items {
+		seen[item.Key] = true
+	}
+
+	// Items whose key changed (moved file, edited whitespace) keep their
+	// lifecycle instead of being recorded as removed and re-added.
+	takenKeys := make(map[string]bool)
+	for key := range lifecycles {
+		takenKeys[key] = true
+	}
+	for key, lifecycle := range lifecycles {
+		if seen[key] || lifecycle.IsRemoved() {
+			continue
+		}
+		if moved := findMovedItem(lifecycle.Type, lifecycle.Title, items, takenKeys); moved != nil {
+			delete(lifecycles, key)
+			lifecycle.Key = moved.Key
+			lifecycles[moved.Key] = lifecycle
+			takenKeys[moved.Key] = true
+		}
+	}
+
+	for _, item := range items {
+		if _, ok := lifecycles[item.Key]; ok {
+			continue
+		}
+		history.Lifecycles = append(history.Lifecycles, entities.ItemLifecycle{
+			Key:             item.Key,
+			FirstSeenAt:     itemFirstSeen(item),
+			FirstSeenCommit: history.GitCommit,
+		})
+		lifecycles[item.Key] = nil
+	}
+	lifecycles = pt.indexLifecycles(history)
+
+	for _, item := range items {
+		lifecycle := lifecycles[item.Key]
+
+		if lifecycle.IsRemoved() {
+			pt.logger.Info("Item reappeared in code", zap.String("key", item.Key), zap.String("file", item.File))
+			lifecycle.RemovedAt = nil
+			lifecycle.RemovedCommit = ""
+			lifecycle.RemovedMessage = ""
+			lifecycle.Removal = ""
+			lifecycle.ManualRemoval = false
+			lifecycle.RemovalCheckedCommit = ""
+		}
+
+		lifecycle.Type = item.Type
+		lifecycle.Title = item.Title
+		lifecycle.File = item.File
+		lifecycle.Line = item.Line
+		lifecycle.Priority = item.Priority
+		lifecycle.LastStatus = item.Status
+		lifecycle.Boards = item.Boards
+		lifecycle.LastSeenAt = now
+		lifecycle.LastSeenCommit = history.GitCommit
+		lifecycle.LastSeenBranch = history.GitBranch
+	}
+
+	for i := range history.Lifecycles {
+		lifecycle := &history.Lifecycles[i]
+		if seen[lifecycle.Key] {
+			continue
+		}
+
+		if !lifecycle.IsRemoved() {
+			// An item last seen on another branch is only missing because
+			// that branch is not checked out; it is not removed here.
+			if lifecycle.LastSeenBranch != "" && lifecycle.LastSeenBranch != history.GitBranch {
+				continue
+			}
+
+			removedAt := now
+			lifecycle.RemovedAt = &removedAt
+			pt.logger.Info("Item removed from code", zap.String("key", lifecycle.Key), zap.String("file", lifecycle.File))
+		}
+
+		if !lifecycle.ManualRemoval {
+			lifecycle.Removal = pt.classifyRemoval(lifecycle, currentSettings)
+		}
+	}
+}
+
+// uncheckedRemovals returns the removed items whose removing commit was not
+// looked up at the current commit yet.
+func uncheckedRemovals(history *entities.ItemsHistory) []entities.ItemLifecycle {
+	unchecked := []entities.ItemLifecycle{}
+	for _, lifecycle := range history.Lifecycles {
+		if lifecycle.IsRemoved() && lifecycle.RemovedCommit == "" && lifecycle.RemovalCheckedCommit != history.GitCommit {
+			unchecked = append(unchecked, lifecycle)
+		}
+	}
+	return unchecked
+}
+
+// checkRemovals looks up the commits that removed items in the background,
+// since git log -S walks the history of every file. The commits are stored
+// on the lifecycles, which are classified again. A check that is still
+// running makes this a no-op; the next save picks the items up.
+func (pt *HistoryService) checkRemovals(unchecked []entities.ItemLifecycle, commit string, settings *entities.Settings) {
+	if len(unchecked) == 0 || !pt.checkingRemovals.CompareAndSwap(false, true) {
+		return
+	}
+
+	pt.removalChecks.Add(1)
+	go func() {
+		defer pt.removalChecks.Done()
+		defer pt.checkingRemovals.Store(false)
+
+		found := make(map[string][2]string, len(unchecked))
+		for i := range unchecked {
+			removedCommit, message := pt.findRemovingCommit(&unchecked[i])
+			found[unchecked[i].Key] = [2]string{removedCommit, message}
+		}
+
+		err := pt.store.PatchStats(func(tx storage.StatsTx) error {
+			lifecycles, err := tx.Lifecycles()
+			if err != nil {
+				return err
+			}
+
+			for _, lifecycle := range lifecycles {
+				removal, ok := found[lifecycle.Key]
+				if !ok || !lifecycle.IsRemoved() || lifecycle.RemovedCommit != "" {
+					continue
+				}
+
+				lifecycle.RemovedCommit, lifecycle.RemovedMessage = removal[0], removal[1]
+				lifecycle.RemovalCheckedCommit = commit
+				if !lifecycle.ManualRemoval {
+					lifecycle.Removal = pt.classifyRemoval(&lifecycle, settings)
+				}
+				if err := tx.PutLifecycle(lifecycle); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			pt.logger.Error("Failed to save removing commits", zap.Error(err))
+		}
+	}()
+}
+
+func (pt *HistoryService) indexLifecycles(history *entities.ItemsHistory) map[string]*entities.ItemLifecycle {
+	lifecycles := make(map[string]*entities.ItemLifecycle, len(history.Lifecycles))
+	for i := range history.Lifecycles {
+		lifecycles[history.Lifecycles[i].Key] = &history.Lifecycles[i]
+	}
+	return lifecycles
+}
+
+func (pt *HistoryService) classifyRemoval(lifecycle *entities.ItemLifecycle, settings *entities.Settings) entities.ItemRemoval {
+	board := settings.DefaultBoard()
+	for _, id := range lifecycle.Boards {
+		if b, ok := settings.GetBoard(id); ok {
+			board = *b
+			break
+		}
+	}
+
+	if len(board.Columns) > 0 && string(board.StatusFor(lifecycle.LastStatus)) == board.Columns[len(board.Columns)-1].ID {
+		return entities.RemovalResolved
+	}
+
+	if lifecycle.RemovedMessage != "" && resolvedCommitPattern.MatchString(lifecycle.RemovedMessage) {
+		return entities.RemovalResolved
+	}
+
+	return entities.RemovalDiscarded
+}
+
+// findRemovingCommit returns the commit that deleted the comment, or an empty
+// string while the deletion is still uncommitted.
+func (pt *HistoryService) findRemovingCommit(lifecycle *entities.ItemLifecycle) (string, string) {
+	if lifecycle.Title == "" || lifecycle.File == "" {
+		return "", ""
+	}
+
+	file := filepath.ToSlash(lifecycle.File)
+	output, err := exec.Command("git", "log", "-n", "1", "--format=%H%x00%s", "-S", lifecycle.Title, "--", file).Output()
+	if err != nil {
+		pt.logger.Debug("Failed to find removing commit", zap.String("file", file), zap.Error(err))
+		return "", ""
+	}
+
+	commit, message, _ := strings.Cut(strings.TrimSpace(string(output)), "\x00")
+	if commit == "" {
+		return "", ""
+	}
+
+	content, err := exec.Command("git", "show", fmt.Sprintf("%s:%s", commit, file)).Output()
+	if err == nil && strings.Contains(string(content), lifecycle.Title) {
+		return "", ""
+	}
+
+	return commit, message
+}
+
+func (pt *HistoryService) GetLifecycles() []entities.ItemLifecycle {
+	history, err := pt.LoadStats()
+	if err != nil {
+		pt.logger.Error("Failed to load lifecycles", zap.Error(err))
+		return []entities.ItemLifecycle{}
+	}
+	if history == nil {
+		return []entities.ItemLifecycle{}
+	}
+	return history.Lifecycles
+}
+
+// GetFirstSeen returns when each item was first seen, by key.
+func (pt *HistoryService) GetFirstSeen() map[string]time.Time {
+	return lifecycleFirstSeen(pt.GetLifecycles())
+}
+
+func (pt *HistoryService) GetItemLifecycle(key string) *entities.ItemLifecycle {
+	for _, lifecycle := range pt.GetLifecycles() {
+		if lifecycle.Key == key {
+			return &lifecycle
+		}
+	}
+	return nil
+}
+
+func (pt *HistoryService) GetArchivedItems(boardID string, removal entities.ItemRemoval) []entities.ItemLifecycle {
+	archived := []entities.ItemLifecycle{}
+	for _, lifecycle := range pt.GetLifecycles() {
+		if !lifecycle.IsRemoved() {
+			continue
+		}
+		if boardID != "" && !lifecycle.InBoard(boardID) {
+			continue
+		}
+		if removal != "" && lifecycle.Removal != removal {
+			continue
+		}
+		archived = append(archived, lifecycle)
+	}
+
+	slices.SortFunc(archived, func(a, b entities.ItemLifecycle) int {
+		return b.RemovedAt.Compare(*a.RemovedAt)
+	})
+
+	return archived
+}
+
+func (pt *HistoryService) SetItemRemoval(key string, removal entities.ItemRemoval) (*entities.ItemLifecycle, error) {
+	if !removal.IsValid() {
+		return nil, errors.New("invalid removal type")
+	}
+
+	var updated entities.ItemLifecycle
+	err := pt.store.PatchStats(func(tx storage.StatsTx) error {
+		lifecycles, err := tx.Lifecycles()
+		if err != nil {
+			return err
+		}
+
+		for _, lifecycle := range lifecycles {
+			if lifecycle.Key != key {
+				continue
+			}
+			if !lifecycle.IsRemoved() {
+				return errors.New("item is still in code")
+			}
+
+			lifecycle.Removal = removal
+			lifecycle.ManualRemoval = true
+			updated = lifecycle
+			return tx.PutLifecycle(lifecycle)
+		}
+		return errors.New("item not found")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// countCompletedRemovals counts the items of a board removed from code up
+// to before. Every removal completes an item, whether it was resolved or
+// discarded.
+func countCompletedRemovals(lifecycles []entities.ItemLifecycle, boardID string, before time.Time) int {
+	count := 0
+	for _, lifecycle := range lifecycles {
+		if !lifecycle.IsRemoved() || !lifecycle.InBoard(boardID) {
+			continue
+		}
+		if !before.IsZero() && lifecycle.RemovedAt.After(before) {
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+func countRemovals(lifecycles []entities.ItemLifecycle, boardID string, removal entities.ItemRemoval) int {
+	count := 0
+	for _, lifecycle := range lifecycles {
+		if lifecycle.IsRemoved() && lifecycle.Removal == removal && lifecycle.InBoard(boardID) {
+			count++
+		}
+	}
+	return count
+}
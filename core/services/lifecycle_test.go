@@ -0,0 +1,135 @@
+package services
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+func runGit(t *testing.T, args ...string) string {
+	t.Helper()
+	args = append([]string{"-c", "user.name=tester", "-c", "user.email=tester@example.com", "-c", "commit.gpgsign=false"}, args...)
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestRemovingCommitIsFoundAfterSave(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	config := newTestProject(t, map[string]string{
+		"a.go": "package a\n\n// TODO: drop me\nfunc a() {}\n",
+	})
+	runGit(t, "init", "-q")
+	runGit(t, "add", "a.go")
+	runGit(t, "commit", "-qm", "add item")
+
+	scanner := newTestScanner(t, config)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+
+	writeTestFile(t, "a.go", "package a\n\nfunc a() {}\n")
+	runGit(t, "commit", "-qam", "fix: drop the item")
+	removedCommit := runGit(t, "rev-parse", "HEAD")
+
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+	scanner.historyService.removalChecks.Wait()
+
+	lifecycles := scanner.historyService.GetLifecycles()
+	if len(lifecycles) != 1 || !lifecycles[0].IsRemoved() {
+		t.Fatalf("lifecycles = %+v, want one removed item", lifecycles)
+	}
+	lifecycle := lifecycles[0]
+	if lifecycle.RemovedCommit != removedCommit || lifecycle.RemovalCheckedCommit != removedCommit {
+		t.Errorf("removed commit = %q, checked at %q, want %q", lifecycle.RemovedCommit, lifecycle.RemovalCheckedCommit, removedCommit)
+	}
+	if lifecycle.Removal != entities.RemovalResolved {
+		t.Errorf("removal = %q, want it classified from the commit message", lifecycle.Removal)
+	}
+}
+
+func TestEveryRemovalCountsAsCompletion(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	lifecycles := []entities.ItemLifecycle{
+		{Key: "resolved", RemovedAt: &earlier, Removal: entities.RemovalResolved},
+		{Key: "discarded", RemovedAt: &earlier, Removal: entities.RemovalDiscarded},
+		{Key: "later", RemovedAt: &now, Removal: entities.RemovalDiscarded},
+		{Key: "in code"},
+		{Key: "other board", RemovedAt: &earlier, Boards: []string{"other"}},
+	}
+
+	if got := countCompletedRemovals(lifecycles, entities.DefaultBoardID, time.Time{}); got != 3 {
+		t.Errorf("completed = %d, want 3", got)
+	}
+	if got := countCompletedRemovals(lifecycles, entities.DefaultBoardID, earlier); got != 2 {
+		t.Errorf("completed before the last removal = %d, want 2", got)
+	}
+}
+
+func TestCheckoutDoesNotRemoveItems(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	config := newTestProject(t, map[string]string{
+		"a.go": "package a\n\n// TODO: on every branch\nfunc a() {}\n",
+	})
+	runGit(t, "init", "-q")
+	runGit(t, "add", "a.go")
+	runGit(t, "commit", "-qm", "add item")
+	base := runGit(t, "rev-parse", "--abbrev-ref", "HEAD")
+
+	runGit(t, "checkout", "-qb", "feature")
+	writeTestFile(t, "b.go", "package a\n\n// TODO: only on feature\nfunc b() {}\n")
+	runGit(t, "add", "b.go")
+	runGit(t, "commit", "-qm", "add feature item")
+
+	scanner := newTestScanner(t, config)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, "checkout", "-q", base)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+	scanner.historyService.removalChecks.Wait()
+
+	for _, lifecycle := range scanner.historyService.GetLifecycles() {
+		if lifecycle.IsRemoved() {
+			t.Errorf("item %q was removed by checking out %s", lifecycle.Title, base)
+		}
+	}
+
+	runGit(t, "checkout", "-q", "feature")
+	writeTestFile(t, "b.go", "package a\n\nfunc b() {}\n")
+	runGit(t, "commit", "-qam", "drop feature item")
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+	scanner.historyService.removalChecks.Wait()
+
+	removed := 0
+	for _, lifecycle := range scanner.historyService.GetLifecycles() {
+		if lifecycle.IsRemoved() {
+			removed++
+			if lifecycle.Title != "only on feature" {
+				t.Errorf("item %q was removed, want only the feature item", lifecycle.Title)
+			}
+		}
+	}
+	if removed != 1 {
+		t.Errorf("removed %d items, want the one deleted on feature", removed)
+	}
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
+	"go.uber.org/zap"
+)
+
+type LinkService struct {
+	config      *entities.Config
+	logger      *zap.Logger
+	noteService *NoteService
+}
+
+func NewLinkService(config *entities.Config, logger *zap.Logger, noteService *NoteService) *LinkService {
+	return &LinkService{
+		config:      config,
+		logger:      logger,
+		noteService: noteService,
+	}
+}
+
+func (s *LinkService) getLinksFilePath() string {
+	wd, _ := os.Getwd()
+	return filepath.Join(wd, s.config.Flags.Config, storage.LinksFile)
+}
+
+func (s *LinkService) loadLinkStorage() (*entities.LinkStorage, error) {
+	data, err := os.ReadFile(s.getLinksFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &entities.LinkStorage{
+				Links:  []entities.Link{},
+				NextID: 1,
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to read links file: %v", err)
+	}
+
+	var storage entities.LinkStorage
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal links: %v", err)
+	}
+
+	if storage.NextID < 1 {
+		storage.NextID = 1
+	}
+
+	return &storage, nil
+}
+
+func (s *LinkService) saveLinkStorage(links *entities.LinkStorage) error {
+	data, err := json.MarshalIndent(links, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal links: %v", err)
+	}
+
+	if err := storage.WriteFile(s.getLinksFilePath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write links file: %v", err)
+	}
+
+	return nil
+}
+
+// updateLinkStorage runs fn on the links while holding the lock of
+// links.json and saves them, unless fn returns an error or errUnchanged.
+func (s *LinkService) updateLinkStorage(fn func(links *entities.LinkStorage) error) error {
+	return storage.WithFileLock(s.getLinksFilePath(), func() error {
+		links, err := s.loadLinkStorage()
+		if err != nil {
+			return err
+		}
+
+		if err := fn(links); err != nil {
+			if errors.Is(err, errUnchanged) {
+				return nil
+			}
+			return err
+		}
+		return s.saveLinkStorage(links)
+	})
+}
+
+func (s *LinkService) GetLinks(noteID *int, itemKey string) ([]entities.Link, error) {
+	storage, err := s.loadLinkStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	links := []entities.Link{}
+	for _, link := range storage.Links {
+		if noteID != nil && link.NoteID != *noteID {
+			continue
+		}
+		if itemKey != "" && link.ItemKey != itemKey {
+			continue
+		}
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+func (s *LinkService) CreateLink(noteID int, item *entities.Item, linkType entities.LinkType) (*entities.Link, error) {
+	if !linkType.IsValid() {
+		return nil, errors.New("invalid link type")
+	}
+
+	if s.noteService.getNoteByID(noteID) == nil {
+		return nil, errors.New("note not found")
+	}
+
+	var link entities.Link
+	err := s.updateLinkStorage(func(links *entities.LinkStorage) error {
+		for _, existing := range links.Links {
+			if existing.NoteID == noteID && existing.ItemKey == item.Key && existing.Type == linkType {
+				link = existing
+				return errUnchanged
+			}
+		}
+
+		now := time.Now()
+		link = entities.Link{
+			ID:        links.NextID,
+			NoteID:    noteID,
+			ItemKey:   item.Key,
+			Type:      linkType,
+			File:      item.File,
+			Line:      item.Line,
+			ItemType:  item.Type,
+			ItemTitle: item.Title,
+			Author:    getGitAuthor(),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		links.Links = append(links.Links, link)
+		links.NextID++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+func (s *LinkService) DeleteLink(id int) error {
+	return s.updateLinkStorage(func(links *entities.LinkStorage) error {
+		for i, link := range links.Links {
+			if link.ID == id {
+				links.Links = append(links.Links[:i], links.Links[i+1:]...)
+				return nil
+			}
+		}
+		return errors.New("link not found")
+	})
+}
+
+// DeleteNoteLinks removes the links of deleted notes.
+func (s *LinkService) DeleteNoteLinks(noteIDs []int) error {
+	return s.updateLinkStorage(func(links *entities.LinkStorage) error {
+		kept := links.Links[:0]
+		for _, link := range links.Links {
+			if !slices.Contains(noteIDs, link.NoteID) {
+				kept = append(kept, link)
+			}
+		}
+		if len(kept) == len(links.Links) {
+			return errUnchanged
+		}
+		links.Links = kept
+		return nil
+	})
+}
+
+func (s *LinkService) ResolveLinks(links []entities.Link, items []*entities.Item) ([]entities.ResolvedLink, error) {
+	notes, err := s.noteService.GetNotes("", "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	notesByID := make(map[int]entities.Note)
+	for _, note := range notes {
+		notesByID[note.ID] = note
+	}
+
+	itemsByKey := make(map[string]*entities.Item)
+	for _, item := range items {
+		itemsByKey[item.Key] = item
+	}
+
+	resolved := make([]entities.ResolvedLink, 0, len(links))
+	for _, link := range links {
+		r := entities.ResolvedLink{Link: link}
+		if note, ok := notesByID[link.NoteID]; ok {
+			r.Note = &note
+		}
+		if item, ok := itemsByKey[link.ItemKey]; ok {
+			r.Item = item
+		}
+		resolved = append(resolved, r)
+	}
+
+	return resolved, nil
+}
+
+func (s *LinkService) Reconcile(items []*entities.Item) error {
+	return s.updateLinkStorage(func(links *entities.LinkStorage) error {
+		if len(links.Links) == 0 {
+			return errUnchanged
+		}
+
+		itemsByKey := make(map[string]*entities.Item)
+		for _, item := range items {
+			itemsByKey[item.Key] = item
+		}
+
+		linkedKeys := make(map[string]bool)
+		for _, link := range links.Links {
+			linkedKeys[link.ItemKey] = true
+		}
+
+		movedKeys := make(map[string]string)
+		changed := false
+		for i := range links.Links {
+			link := &links.Links[i]
+
+			item, ok := itemsByKey[link.ItemKey]
+			if !ok {
+				if newKey, moved := movedKeys[link.ItemKey]; moved {
+					item = itemsByKey[newKey]
+				} else if item = findMovedItem(link.ItemType, link.ItemTitle, items, linkedKeys); item != nil {
+					movedKeys[link.ItemKey] = item.Key
+					linkedKeys[item.Key] = true
+				}
+				if item == nil {
+					continue
+				}
+
+				s.logger.Info("Re-anchored item link",
+					zap.Int("link_id", link.ID),
+					zap.String("old_key", link.ItemKey),
+					zap.String("new_key", item.Key))
+
+				link.ItemKey = item.Key
+				changed = true
+			}
+
+			if link.File != item.File || link.Line != item.Line || link.ItemTitle != item.Title {
+				link.File = item.File
+				link.Line = item.Line
+				link.ItemType = item.Type
+				link.ItemTitle = item.Title
+				link.UpdatedAt = time.Now()
+				changed = true
+			}
+		}
+
+		if !changed {
+			return errUnchanged
+		}
+		return nil
+	})
+}
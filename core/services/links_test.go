@@ -0,0 +1,178 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
+	"go.uber.org/zap"
+)
+
+func newTestLinks(t *testing.T) (*LinkService, *NoteService) {
+	t.Helper()
+	config := newTestProject(t, nil)
+	store, err := storage.Open(config.Flags.Config, storage.BackendJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	notes := NewNoteService(config, zap.NewNop(), store)
+	links := NewLinkService(config, zap.NewNop(), notes)
+	notes.OnDelete(links.DeleteNoteLinks)
+	return links, notes
+}
+
+func testItem(file string, line int, title string) *entities.Item {
+	return &entities.Item{
+		Key:   entities.ItemKey(file, "TODO", title, 0),
+		Type:  "TODO",
+		Title: title,
+		File:  file,
+		Line:  line,
+	}
+}
+
+func createTestNote(t *testing.T, notes *NoteService, title string) *entities.Note {
+	t.Helper()
+	author := "tester"
+	note, err := notes.CreateNoteWithHistory(title, "", nil, "", nil, &author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return note
+}
+
+func TestLinkCRUD(t *testing.T) {
+	links, notes := newTestLinks(t)
+	note := createTestNote(t, notes, "design")
+	item := testItem("a.go", 3, "fix this")
+
+	if _, err := links.CreateLink(note.ID, item, "depends"); err == nil {
+		t.Error("created a link with an invalid type")
+	}
+	if _, err := links.CreateLink(note.ID+1, item, entities.LinkReferences); err == nil {
+		t.Error("created a link to a missing note")
+	}
+
+	link, err := links.CreateLink(note.ID, item, entities.LinkReferences)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := links.CreateLink(note.ID, item, entities.LinkReferences)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != link.ID {
+		t.Errorf("linking twice created link %d next to %d", again.ID, link.ID)
+	}
+	blocks, err := links.CreateLink(note.ID, item, entities.LinkBlocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byNote, err := links.GetLinks(&note.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byNote) != 2 {
+		t.Fatalf("got %d links of the note, want 2", len(byNote))
+	}
+	byItem, err := links.GetLinks(nil, "b.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byItem) != 0 {
+		t.Errorf("got %d links for an unlinked item, want none", len(byItem))
+	}
+
+	if err := links.DeleteLink(blocks.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := links.DeleteLink(blocks.ID); err == nil {
+		t.Error("deleted a missing link")
+	}
+	remaining, err := links.GetLinks(nil, item.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != link.ID {
+		t.Errorf("links after delete = %+v, want only link %d", remaining, link.ID)
+	}
+}
+
+func TestReconcileReanchorsLinks(t *testing.T) {
+	links, notes := newTestLinks(t)
+	note := createTestNote(t, notes, "design")
+	item := testItem("a.go", 3, "fix this")
+	if _, err := links.CreateLink(note.ID, item, entities.LinkReferences); err != nil {
+		t.Fatal(err)
+	}
+
+	// The item moved to another file, so its key changed.
+	moved := testItem("b.go", 10, "fix this")
+	if err := links.Reconcile([]*entities.Item{moved, testItem("c.go", 1, "other")}); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := links.GetLinks(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("got %d links, want 1", len(all))
+	}
+	if all[0].ItemKey != moved.Key || all[0].File != "b.go" || all[0].Line != 10 {
+		t.Errorf("link = %+v, want it anchored to %s at b.go:10", all[0], moved.Key)
+	}
+
+	// Two candidates with the same title are ambiguous; the link stays.
+	if err := links.Reconcile([]*entities.Item{testItem("d.go", 1, "fix this"), testItem("e.go", 1, "fix this")}); err != nil {
+		t.Fatal(err)
+	}
+	all, err = links.GetLinks(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all[0].ItemKey != moved.Key {
+		t.Errorf("link moved to %s between two candidates", all[0].ItemKey)
+	}
+}
+
+func TestDeletingNotesRemovesTheirLinks(t *testing.T) {
+	links, notes := newTestLinks(t)
+	deleted := createTestNote(t, notes, "deleted")
+	kept := createTestNote(t, notes, "kept")
+
+	folder, err := notes.CreateFolder("old", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	author := "tester"
+	inFolder, err := notes.CreateNoteWithHistory("in folder", "", nil, "", &folder.ID, &author)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item := testItem("a.go", 3, "fix this")
+	for _, note := range []*entities.Note{deleted, kept, inFolder} {
+		if _, err := links.CreateLink(note.ID, item, entities.LinkReferences); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := notes.DeleteNoteWithHistory(deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := notes.DeleteFolder(folder.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := links.GetLinks(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || all[0].NoteID != kept.ID {
+		t.Errorf("links = %+v, want only the link of note %d", all, kept.ID)
+	}
+}
@@ -1,104 +1,69 @@
 package services
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"os/exec"
-	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
 	"go.uber.org/zap"
 )
 
-type NoteService struct {
-	Notes   []entities.Note   `json:"notes"`
-	Folders []entities.Folder `json:"folders"`
-	NextID  int               `json:"next_id"`
+// NoteDeleteHook runs after notes were deleted, with their IDs.
+type NoteDeleteHook func(ids []int) error
 
+type NoteService struct {
 	logger *zap.Logger
 	config *entities.Config
+	store  storage.Store
+	// mu queues the note changes of this process, so they wait for each
+	// other instead of polling the store lock until it times out.
+	mu          sync.Mutex
+	deleteHooks []NoteDeleteHook
 }
 
-func NewNoteService(config *entities.Config, logger *zap.Logger) *NoteService {
+func NewNoteService(config *entities.Config, logger *zap.Logger, store storage.Store) *NoteService {
 	return &NoteService{
-		Notes:   []entities.Note{},
-		Folders: []entities.Folder{},
-		NextID:  1,
-		logger:  logger,
-		config:  config,
+		logger: logger,
+		config: config,
+		store:  store,
 	}
 }
 
-func (s *NoteService) ensureNotesDir() error {
-	wd, _ := os.Getwd()
-	notesDir := filepath.Join(wd, s.config.Flags.Config)
+// updateNotes is the one path that changes notes.
+func (s *NoteService) updateNotes(fn func(tx storage.NoteTx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if err := os.MkdirAll(notesDir, 0755); err != nil {
-		return fmt.Errorf("failed to create notes directory: %v", err)
-	}
-
-	return nil
+	return s.store.UpdateNotes(fn)
 }
 
-func (s *NoteService) getNotesFilePath() string {
-	wd, _ := os.Getwd()
-	return filepath.Join(wd, s.config.Flags.Config, "notes.json")
+func (s *NoteService) OnDelete(hook NoteDeleteHook) {
+	s.deleteHooks = append(s.deleteHooks, hook)
 }
 
-func (s *NoteService) loadNoteStorage() (*NoteService, error) {
-	if err := s.ensureNotesDir(); err != nil {
-		return nil, err
+func (s *NoteService) runDeleteHooks(ids []int) {
+	if len(ids) == 0 {
+		return
 	}
-
-	filePath := s.getNotesFilePath()
-
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		storage := &NoteService{
-			Notes:   []entities.Note{},
-			Folders: []entities.Folder{},
-			NextID:  1,
+	for _, hook := range s.deleteHooks {
+		if err := hook(ids); err != nil {
+			s.logger.Error("Note delete hook failed", zap.Ints("note_ids", ids), zap.Error(err))
 		}
-		return storage, nil
-	}
-
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read notes file: %v", err)
 	}
-
-	var storage NoteService
-	if err := json.Unmarshal(data, &storage); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal notes: %v", err)
-	}
-
-	return &storage, nil
 }
 
-func (s *NoteService) SaveNoteStorage(storage *NoteService) error {
-	if err := s.ensureNotesDir(); err != nil {
-		return err
-	}
-
-	data, err := json.MarshalIndent(storage, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal notes: %v", err)
-	}
-
-	filePath := s.getNotesFilePath()
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write notes file: %v", err)
-	}
-
-	return nil
+func (s *NoteService) getGitAuthor() string {
+	return getGitAuthor()
 }
 
-func (s *NoteService) getGitAuthor() string {
+func getGitAuthor() string {
 	cmd := exec.Command("git", "config", "--get", "user.name")
 	nameOutput, nameErr := cmd.Output()
 
@@ -146,86 +111,71 @@ func (s *NoteService) getGitInfo() (string, string) {
 }
 
 func (s *NoteService) GetNotes(category, tag, folderId string) ([]entities.Note, error) {
-	storage, err := s.loadNoteStorage()
+	query := storage.NoteQuery{Category: category, Tag: tag}
+	if folderId != "" {
+		id, err := strconv.Atoi(folderId)
+		if err != nil {
+			return nil, nil
+		}
+		query.FolderID = &id
+	}
+
+	var notes []entities.Note
+	err := s.store.ViewNotes(func(tx storage.NoteTx) error {
+		var err error
+		notes, err = tx.Notes(query)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var filteredNotes []entities.Note
-
-	for _, note := range storage.Notes {
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].UpdatedAt.After(notes[j].UpdatedAt)
+	})
 
-		if category != "" && note.Category != category {
-			continue
-		}
+	return notes, nil
+}
 
-		if tag != "" {
-			hasTag := false
-			for _, noteTag := range note.Tags {
-				if noteTag == tag {
-					hasTag = true
-					break
-				}
-			}
-			if !hasTag {
-				continue
-			}
+func (s *NoteService) CreateFolder(name string, parentId *int) (*entities.Folder, error) {
+	var folder entities.Folder
+	err := s.updateNotes(func(tx storage.NoteTx) error {
+		id, err := tx.NextID()
+		if err != nil {
+			return err
 		}
 
-		if folderId != "" {
-			folderIdInt, err := strconv.Atoi(folderId)
-			if err != nil {
-				continue
-			}
-			if note.FolderID == nil || *note.FolderID != folderIdInt {
-				continue
-			}
+		folder = entities.Folder{
+			ID:       id,
+			Name:     name,
+			ParentID: parentId,
+			Expanded: true,
 		}
-
-		filteredNotes = append(filteredNotes, note)
-	}
-
-	sort.Slice(filteredNotes, func(i, j int) bool {
-		return filteredNotes[i].UpdatedAt.After(filteredNotes[j].UpdatedAt)
+		return tx.PutFolder(folder)
 	})
-
-	return filteredNotes, nil
-}
-
-func (s *NoteService) CreateFolder(name string, parentId *int) (*entities.Folder, error) {
-	storage, err := s.loadNoteStorage()
 	if err != nil {
 		return nil, err
 	}
 
-	folder := entities.Folder{
-		ID:       storage.NextID,
-		Name:     name,
-		ParentID: parentId,
-		Expanded: true,
-	}
-
-	storage.Folders = append(storage.Folders, folder)
-	storage.NextID++
-
-	if err := s.SaveNoteStorage(storage); err != nil {
-		return nil, err
-	}
-
 	return &folder, nil
 }
 
 func (s *NoteService) GetFolders() ([]entities.Folder, error) {
-	storage, err := s.loadNoteStorage()
+	var folders []entities.Folder
+	err := s.store.ViewNotes(func(tx storage.NoteTx) error {
+		var err error
+		folders, err = tx.Folders()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	sort.Slice(storage.Folders, func(i, j int) bool {
-		return storage.Folders[i].Name < storage.Folders[j].Name
+	sort.Slice(folders, func(i, j int) bool {
+		return folders[i].Name < folders[j].Name
 	})
 
-	return storage.Folders, nil
+	return folders, nil
 }
 
 func (s *NoteService) GetCategories() []entities.Category {
@@ -244,14 +194,23 @@ func (s *NoteService) GetCategories() []entities.Category {
 }
 
 func (s *NoteService) GetNoteStats() (map[string]interface{}, error) {
-	storage, err := s.loadNoteStorage()
+	var notes []entities.Note
+	var folders []entities.Folder
+	err := s.store.ViewNotes(func(tx storage.NoteTx) error {
+		var err error
+		if notes, err = tx.Notes(storage.NoteQuery{}); err != nil {
+			return err
+		}
+		folders, err = tx.Folders()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	history := map[string]interface{}{
-		"total_notes":   len(storage.Notes),
-		"total_folders": len(storage.Folders),
+		"total_notes":   len(notes),
+		"total_folders": len(folders),
 		"by_category":   make(map[string]int),
 		"by_author":     make(map[string]int),
 		"by_month":      make(map[string]int),
@@ -265,7 +224,7 @@ func (s *NoteService) GetNoteStats() (map[string]interface{}, error) {
 	var recentNotes []entities.Note
 	cutoff := time.Now().AddDate(0, 0, -7)
 
-	for _, note := range storage.Notes {
+	for _, note := range notes {
 
 		if note.Category != "" {
 			categoryCount[note.Category]++
@@ -299,103 +258,109 @@ func (s *NoteService) GetNoteStats() (map[string]interface{}, error) {
 	return history, nil
 }
 
-func (s *NoteService) deleteFolderRecursive(storage *NoteService, id int) error {
-
-	folderIndex := -1
-	for i, folder := range storage.Folders {
-		if folder.ID == id {
-			folderIndex = i
-			break
+func (s *NoteService) deleteFolderRecursive(tx storage.NoteTx, id int, deleted *[]int) error {
+	if _, err := tx.Folder(id); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return errors.New("folder not found")
 		}
-	}
-	if folderIndex == -1 {
-		return errors.New("folder not found")
+		return err
 	}
 
-	newNotes := storage.Notes[:0]
-	for _, note := range storage.Notes {
-		if note.FolderID == nil || *note.FolderID != id {
-			newNotes = append(newNotes, note)
+	notes, err := tx.Notes(storage.NoteQuery{FolderID: &id})
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		if err := tx.DeleteNote(note.ID); err != nil {
+			return err
 		}
+		*deleted = append(*deleted, note.ID)
 	}
-	storage.Notes = newNotes
 
-	for _, folder := range storage.Folders {
+	folders, err := tx.Folders()
+	if err != nil {
+		return err
+	}
+	for _, folder := range folders {
 		if folder.ParentID != nil && *folder.ParentID == id {
-			if err := s.deleteFolderRecursive(storage, folder.ID); err != nil {
+			if err := s.deleteFolderRecursive(tx, folder.ID, deleted); err != nil {
 				return err
 			}
 		}
 	}
 
-	storage.Folders = append(storage.Folders[:folderIndex], storage.Folders[folderIndex+1:]...)
-
-	return nil
+	return tx.DeleteFolder(id)
 }
 
 func (s *NoteService) UpdateFolder(id int, name string, parentId *int, expanded *bool) (*entities.Folder, error) {
-	storage, err := s.loadNoteStorage()
-	if err != nil {
-		return nil, err
-	}
-
-	folderIndex := -1
-	for i, folder := range storage.Folders {
-		if folder.ID == id {
-			folderIndex = i
-			break
+	var folder entities.Folder
+	err := s.updateNotes(func(tx storage.NoteTx) error {
+		var err error
+		folder, err = tx.Folder(id)
+		if errors.Is(err, storage.ErrNotFound) {
+			return errors.New("folder not found")
+		}
+		if err != nil {
+			return err
 		}
-	}
-
-	if folderIndex == -1 {
-		return nil, errors.New("folder not found")
-	}
 
-	if name != "" {
-		storage.Folders[folderIndex].Name = name
-	}
-	if expanded != nil {
-		storage.Folders[folderIndex].Expanded = *expanded
-	}
-	storage.Folders[folderIndex].ParentID = parentId
+		if name != "" {
+			folder.Name = name
+		}
+		if expanded != nil {
+			folder.Expanded = *expanded
+		}
+		folder.ParentID = parentId
 
-	if err := s.SaveNoteStorage(storage); err != nil {
+		return tx.PutFolder(folder)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &storage.Folders[folderIndex], nil
+	return &folder, nil
 }
 
 func (s *NoteService) DeleteFolder(id int) error {
-	storage, err := s.loadNoteStorage()
+	var deleted []int
+	err := s.updateNotes(func(tx storage.NoteTx) error {
+		deleted = deleted[:0]
+		return s.deleteFolderRecursive(tx, id, &deleted)
+	})
 	if err != nil {
 		return err
 	}
 
-	if err := s.deleteFolderRecursive(storage, id); err != nil {
-		return err
-	}
-
-	return s.SaveNoteStorage(storage)
+	s.runDeleteHooks(deleted)
+	return nil
 }
 
 func (s *NoteService) GetFolderTree() ([]map[string]interface{}, error) {
-	storage, err := s.loadNoteStorage()
+	var notes []entities.Note
+	var folders []entities.Folder
+	err := s.store.ViewNotes(func(tx storage.NoteTx) error {
+		var err error
+		if notes, err = tx.Notes(storage.NoteQuery{}); err != nil {
+			return err
+		}
+		folders, err = tx.Folders()
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	folderMap := make(map[int]*entities.Folder)
-	for i := range storage.Folders {
-		folderMap[storage.Folders[i].ID] = &storage.Folders[i]
+	for i := range folders {
+		folderMap[folders[i].ID] = &folders[i]
 	}
 
 	var rootFolders []map[string]interface{}
 
-	for _, folder := range storage.Folders {
+	for _, folder := range folders {
 		if folder.ParentID == nil {
 
-			rootFolders = append(rootFolders, s.buildFolderNode(folder, folderMap, storage.Notes))
+			rootFolders = append(rootFolders, s.buildFolderNode(folder, folderMap, notes))
 		}
 	}
 
@@ -448,7 +413,12 @@ func (s *NoteService) SearchNotes(query string, category string, folderId *int)
 		return s.GetNotes(category, "", "")
 	}
 
-	storage, err := s.loadNoteStorage()
+	var notes []entities.Note
+	err := s.store.ViewNotes(func(tx storage.NoteTx) error {
+		var err error
+		notes, err = tx.Notes(storage.NoteQuery{FolderID: folderId, Category: category})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -456,17 +426,7 @@ func (s *NoteService) SearchNotes(query string, category string, folderId *int)
 	query = strings.ToLower(query)
 	var matchingNotes []entities.Note
 
-	for _, note := range storage.Notes {
-
-		if category != "" && note.Category != category {
-			continue
-		}
-
-		if folderId != nil {
-			if note.FolderID == nil || *note.FolderID != *folderId {
-				continue
-			}
-		}
+	for _, note := range notes {
 
 		if strings.Contains(strings.ToLower(note.Title), query) {
 			matchingNotes = append(matchingNotes, note)
@@ -538,13 +498,13 @@ func (s *NoteService) ExportNotes(folderId *int, category string) (map[string]in
 }
 
 func (s *NoteService) GetAllTags() ([]string, error) {
-	storage, err := s.loadNoteStorage()
+	notes, err := s.GetNotes("", "", "")
 	if err != nil {
 		return nil, err
 	}
 
 	tagSet := make(map[string]bool)
-	for _, note := range storage.Notes {
+	for _, note := range notes {
 		for _, tag := range note.Tags {
 			if tag != "" {
 				tagSet[tag] = true
@@ -562,74 +522,11 @@ func (s *NoteService) GetAllTags() ([]string, error) {
 	return tags, nil
 }
 
-func (s *NoteService) LoadEnhancedNoteStorage() (*entities.EnhancedNoteStorage, error) {
-	if err := s.ensureNotesDir(); err != nil {
-		return nil, err
-	}
-
-	filePath := s.getNotesFilePath()
-
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		storage := &entities.EnhancedNoteStorage{
-			Notes:         []entities.Note{},
-			Folders:       []entities.Folder{},
-			History:       []entities.NoteHistoryEntry{},
-			NextID:        1,
-			NextHistoryID: 1,
-		}
-		return storage, nil
-	}
-
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read notes file: %v", err)
-	}
-
-	var enhancedStorage entities.EnhancedNoteStorage
-	if err := json.Unmarshal(data, &enhancedStorage); err == nil && enhancedStorage.NextHistoryID > 0 {
-		return &enhancedStorage, nil
-	}
-
-	var oldStorage NoteService
-	if err := json.Unmarshal(data, &oldStorage); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal notes: %v", err)
-	}
-
-	enhancedStorage = entities.EnhancedNoteStorage{
-		Notes:         oldStorage.Notes,
-		Folders:       oldStorage.Folders,
-		History:       []entities.NoteHistoryEntry{},
-		NextID:        oldStorage.NextID,
-		NextHistoryID: 1,
-	}
-
-	return &enhancedStorage, nil
-}
-
-func (s *NoteService) SaveEnhancedNoteStorage(storage *entities.EnhancedNoteStorage) error {
-	if err := s.ensureNotesDir(); err != nil {
-		return err
-	}
-
-	data, err := json.MarshalIndent(storage, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal notes: %v", err)
-	}
-
-	filePath := s.getNotesFilePath()
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write notes file: %v", err)
-	}
-
-	return nil
-}
-
-func (s *NoteService) addNoteHistoryEntry(storage *entities.EnhancedNoteStorage, noteID int, action entities.NoteHistoryAction, changes map[string]interface{}, oldValue, newValue interface{}, message string) {
+func (s *NoteService) addNoteHistoryEntry(tx storage.NoteTx, noteID int, action entities.NoteHistoryAction, changes map[string]interface{}, oldValue, newValue interface{}, message string) error {
 	author := s.getGitAuthor()
 	branch, commit := s.getGitInfo()
 
 	entry := entities.NoteHistoryEntry{
-		ID:        storage.NextHistoryID,
 		NoteID:    noteID,
 		Action:    action,
 		Author:    author,
@@ -643,16 +540,11 @@ func (s *NoteService) addNoteHistoryEntry(storage *entities.EnhancedNoteStorage,
 		Metadata:  make(map[string]interface{}),
 	}
 
-	storage.History = append(storage.History, entry)
-	storage.NextHistoryID++
+	_, err := tx.AddHistory(entry)
+	return err
 }
 
 func (s *NoteService) CreateNoteWithHistory(title, content string, tags []string, category string, folderId *int, author *string) (*entities.Note, error) {
-	storage, err := s.LoadEnhancedNoteStorage()
-	if err != nil {
-		return nil, err
-	}
-
 	now := time.Now()
 	if author == nil {
 		gitUser := s.getGitAuthor()
@@ -660,222 +552,216 @@ func (s *NoteService) CreateNoteWithHistory(title, content string, tags []string
 	}
 	branch, commit := s.getGitInfo()
 
-	status := "open"
-	note := entities.Note{
-		ID:        storage.NextID,
-		Title:     title,
-		Content:   content,
-		Author:    *author,
-		CreatedAt: now,
-		UpdatedAt: now,
-		Tags:      tags,
-		Category:  category,
-		FolderID:  folderId,
-		GitBranch: &branch,
-		GitCommit: &commit,
-		Pinned:    false,
-		Status:    &status,
-	}
-
-	storage.Notes = append(storage.Notes, note)
-	storage.NextID++
-
-	changes := map[string]interface{}{
-		"title":     title,
-		"category":  category,
-		"tags":      tags,
-		"folder_id": folderId,
-	}
-	s.addNoteHistoryEntry(storage, note.ID, entities.ActionCreated, changes, nil, note, "Note created")
-
-	if err := s.SaveEnhancedNoteStorage(storage); err != nil {
+	var note entities.Note
+	err := s.updateNotes(func(tx storage.NoteTx) error {
+		id, err := tx.NextID()
+		if err != nil {
+			return err
+		}
+
+		status := "open"
+		note = entities.Note{
+			ID:        id,
+			Title:     title,
+			Content:   content,
+			Author:    *author,
+			CreatedAt: now,
+			UpdatedAt: now,
+			Tags:      tags,
+			Category:  category,
+			FolderID:  folderId,
+			GitBranch: &branch,
+			GitCommit: &commit,
+			Pinned:    false,
+			Status:    &status,
+		}
+
+		if err := tx.PutNote(note); err != nil {
+			return err
+		}
+
+		changes := map[string]interface{}{
+			"title":     title,
+			"category":  category,
+			"tags":      tags,
+			"folder_id": folderId,
+		}
+		return s.addNoteHistoryEntry(tx, note.ID, entities.ActionCreated, changes, nil, note, "Note created")
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return &note, nil
 }
 
-func (s *NoteService) UpdateNoteWithHistory(id int, title, content string, tags []string, category string, pinned bool, folderId *int) (*entities.Note, error) {
-	storage, err := s.LoadEnhancedNoteStorage()
-	if err != nil {
-		return nil, err
-	}
-
-	noteIndex := -1
-	for i, note := range storage.Notes {
-		if note.ID == id {
-			noteIndex = i
-			break
+func (s *NoteService) RecordNoteAction(noteID int, action entities.NoteHistoryAction, changes map[string]interface{}, message string) error {
+	return s.updateNotes(func(tx storage.NoteTx) error {
+		if _, err := tx.Note(noteID); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("note not found")
+			}
+			return err
 		}
-	}
 
-	if noteIndex == -1 {
-		return nil, fmt.Errorf("note not found")
-	}
+		return s.addNoteHistoryEntry(tx, noteID, action, changes, nil, nil, message)
+	})
+}
 
-	oldNote := storage.Notes[noteIndex]
+func (s *NoteService) UpdateNoteWithHistory(id int, title, content string, tags []string, category string, pinned bool, folderId *int) (*entities.Note, error) {
+	var note entities.Note
+	err := s.updateNotes(func(tx storage.NoteTx) error {
+		oldNote, err := tx.Note(id)
+		if errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("note not found")
+		}
+		if err != nil {
+			return err
+		}
 
-	changes := make(map[string]interface{})
-	if oldNote.Title != title {
-		changes["title"] = map[string]interface{}{"from": oldNote.Title, "to": title}
-	}
-	if oldNote.Content != content {
-		changes["content"] = map[string]interface{}{"from": len(oldNote.Content), "to": len(content)}
-	}
-	if oldNote.Category != category {
-		changes["category"] = map[string]interface{}{"from": oldNote.Category, "to": category}
-	}
-	if oldNote.Pinned != pinned {
-		changes["pinned"] = map[string]interface{}{"from": oldNote.Pinned, "to": pinned}
-	}
-	if !equalSlices(oldNote.Tags, tags) {
-		changes["tags"] = map[string]interface{}{"from": oldNote.Tags, "to": tags}
-	}
-	if !equalFolderID(oldNote.FolderID, folderId) {
-		changes["folder_id"] = map[string]interface{}{"from": oldNote.FolderID, "to": folderId}
-	}
+		changes := make(map[string]interface{})
+		if oldNote.Title != title {
+			changes["title"] = map[string]interface{}{"from": oldNote.Title, "to": title}
+		}
+		if oldNote.Content != content {
+			changes["content"] = map[string]interface{}{"from": len(oldNote.Content), "to": len(content)}
+		}
+		if oldNote.Category != category {
+			changes["category"] = map[string]interface{}{"from": oldNote.Category, "to": category}
+		}
+		if oldNote.Pinned != pinned {
+			changes["pinned"] = map[string]interface{}{"from": oldNote.Pinned, "to": pinned}
+		}
+		if !equalSlices(oldNote.Tags, tags) {
+			changes["tags"] = map[string]interface{}{"from": oldNote.Tags, "to": tags}
+		}
+		if !equalFolderID(oldNote.FolderID, folderId) {
+			changes["folder_id"] = map[string]interface{}{"from": oldNote.FolderID, "to": folderId}
+		}
 
-	branch, commit := s.getGitInfo()
-	storage.Notes[noteIndex].Title = title
-	storage.Notes[noteIndex].Content = content
-	storage.Notes[noteIndex].Tags = tags
-	storage.Notes[noteIndex].Category = category
-	storage.Notes[noteIndex].Pinned = pinned
-	storage.Notes[noteIndex].FolderID = folderId
-	storage.Notes[noteIndex].UpdatedAt = time.Now()
-	storage.Notes[noteIndex].GitBranch = &branch
-	storage.Notes[noteIndex].GitCommit = &commit
+		branch, commit := s.getGitInfo()
+		note = oldNote
+		note.Title = title
+		note.Content = content
+		note.Tags = tags
+		note.Category = category
+		note.Pinned = pinned
+		note.FolderID = folderId
+		note.UpdatedAt = time.Now()
+		note.GitBranch = &branch
+		note.GitCommit = &commit
 
-	if len(changes) > 0 {
-		s.addNoteHistoryEntry(storage, id, entities.ActionUpdated, changes, oldNote, storage.Notes[noteIndex], "Note updated")
-	}
+		if err := tx.PutNote(note); err != nil {
+			return err
+		}
 
-	if err := s.SaveEnhancedNoteStorage(storage); err != nil {
+		if len(changes) > 0 {
+			return s.addNoteHistoryEntry(tx, id, entities.ActionUpdated, changes, oldNote, note, "Note updated")
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &storage.Notes[noteIndex], nil
+	return &note, nil
 }
 
 func (s *NoteService) DeleteNoteWithHistory(id int) error {
-	storage, err := s.LoadEnhancedNoteStorage()
-	if err != nil {
-		return err
-	}
-
-	noteIndex := -1
-	var deletedNote entities.Note
-	for i, note := range storage.Notes {
-		if note.ID == id {
-			noteIndex = i
-			deletedNote = note
-			break
+	err := s.updateNotes(func(tx storage.NoteTx) error {
+		deletedNote, err := tx.Note(id)
+		if errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("note not found")
+		}
+		if err != nil {
+			return err
 		}
-	}
 
-	if noteIndex == -1 {
-		return fmt.Errorf("note not found")
-	}
+		changes := map[string]interface{}{
+			"deleted_note": map[string]interface{}{
+				"title":    deletedNote.Title,
+				"category": deletedNote.Category,
+				"tags":     deletedNote.Tags,
+			},
+		}
+		if err := s.addNoteHistoryEntry(tx, id, entities.ActionDeleted, changes, deletedNote, nil, "Note deleted"); err != nil {
+			return err
+		}
 
-	changes := map[string]interface{}{
-		"deleted_note": map[string]interface{}{
-			"title":    deletedNote.Title,
-			"category": deletedNote.Category,
-			"tags":     deletedNote.Tags,
-		},
+		return tx.DeleteNote(id)
+	})
+	if err != nil {
+		return err
 	}
-	s.addNoteHistoryEntry(storage, id, entities.ActionDeleted, changes, deletedNote, nil, "Note deleted")
 
-	storage.Notes = append(storage.Notes[:noteIndex], storage.Notes[noteIndex+1:]...)
-
-	return s.SaveEnhancedNoteStorage(storage)
+	s.runDeleteHooks([]int{id})
+	return nil
 }
 
 func (s *NoteService) MoveNotesToFolderWithHistory(noteIds []int, targetFolderId *int) error {
-	storage, err := s.LoadEnhancedNoteStorage()
-	if err != nil {
-		return err
-	}
-
-	if targetFolderId != nil {
-		folderExists := false
-		for _, folder := range storage.Folders {
-			if folder.ID == *targetFolderId {
-				folderExists = true
-				break
+	return s.updateNotes(func(tx storage.NoteTx) error {
+		if targetFolderId != nil {
+			if _, err := tx.Folder(*targetFolderId); err != nil {
+				if errors.Is(err, storage.ErrNotFound) {
+					return fmt.Errorf("target folder not found")
+				}
+				return err
 			}
 		}
-		if !folderExists {
-			return fmt.Errorf("target folder not found")
-		}
-	}
 
-	updatedCount := 0
-	branch, commit := s.getGitInfo()
+		updatedCount := 0
+		branch, commit := s.getGitInfo()
 
-	for i := range storage.Notes {
 		for _, noteId := range noteIds {
-			if storage.Notes[i].ID == noteId {
-				oldFolderId := storage.Notes[i].FolderID
-
-				storage.Notes[i].FolderID = targetFolderId
-				storage.Notes[i].UpdatedAt = time.Now()
-				storage.Notes[i].GitBranch = &branch
-				storage.Notes[i].GitCommit = &commit
-
-				changes := map[string]interface{}{
-					"folder_id": map[string]interface{}{
-						"from": oldFolderId,
-						"to":   targetFolderId,
-					},
-				}
-				s.addNoteHistoryEntry(storage, noteId, entities.ActionMoved, changes, oldFolderId, targetFolderId, "Note moved to different folder")
+			note, err := tx.Note(noteId)
+			if errors.Is(err, storage.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
 
-				updatedCount++
-				break
+			oldFolderId := note.FolderID
+
+			note.FolderID = targetFolderId
+			note.UpdatedAt = time.Now()
+			note.GitBranch = &branch
+			note.GitCommit = &commit
+			if err := tx.PutNote(note); err != nil {
+				return err
 			}
-		}
-	}
 
-	if updatedCount == 0 {
-		return fmt.Errorf("no notes found to move")
-	}
+			changes := map[string]interface{}{
+				"folder_id": map[string]interface{}{
+					"from": oldFolderId,
+					"to":   targetFolderId,
+				},
+			}
+			if err := s.addNoteHistoryEntry(tx, noteId, entities.ActionMoved, changes, oldFolderId, targetFolderId, "Note moved to different folder"); err != nil {
+				return err
+			}
 
-	return s.SaveEnhancedNoteStorage(storage)
+			updatedCount++
+		}
+
+		if updatedCount == 0 {
+			return fmt.Errorf("no notes found to move")
+		}
+		return nil
+	})
 }
 
 func (s *NoteService) GetNoteHistory(filter entities.NoteHistoryFilter) ([]entities.NoteHistoryEntry, error) {
-	storage, err := s.LoadEnhancedNoteStorage()
+	var filteredHistory []entities.NoteHistoryEntry
+	err := s.store.ViewNotes(func(tx storage.NoteTx) error {
+		var err error
+		filteredHistory, err = tx.History(filter)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var filteredHistory []entities.NoteHistoryEntry
-
-	for _, entry := range storage.History {
-
-		if filter.NoteID != nil && entry.NoteID != *filter.NoteID {
-			continue
-		}
-		if filter.Action != nil && entry.Action != *filter.Action {
-			continue
-		}
-		if filter.Author != nil && entry.Author != *filter.Author {
-			continue
-		}
-		if filter.GitBranch != nil && (entry.GitBranch == nil || *entry.GitBranch != *filter.GitBranch) {
-			continue
-		}
-		if filter.Since != nil && entry.Timestamp.Before(*filter.Since) {
-			continue
-		}
-		if filter.Until != nil && entry.Timestamp.After(*filter.Until) {
-			continue
-		}
-
-		filteredHistory = append(filteredHistory, entry)
-	}
-
 	sort.Slice(filteredHistory, func(i, j int) bool {
 		return filteredHistory[i].Timestamp.After(filteredHistory[j].Timestamp)
 	})
@@ -895,13 +781,22 @@ func (s *NoteService) GetNoteHistory(filter entities.NoteHistoryFilter) ([]entit
 }
 
 func (s *NoteService) GetNoteHistoryStats() (*entities.NoteHistoryStats, error) {
-	storage, err := s.LoadEnhancedNoteStorage()
+	var notes []entities.Note
+	var entries []entities.NoteHistoryEntry
+	err := s.store.ViewNotes(func(tx storage.NoteTx) error {
+		var err error
+		if notes, err = tx.Notes(storage.NoteQuery{}); err != nil {
+			return err
+		}
+		entries, err = tx.History(entities.NoteHistoryFilter{})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	history := &entities.NoteHistoryStats{
-		TotalEntries: len(storage.History),
+		TotalEntries: len(entries),
 		ByAction:     make(map[entities.NoteHistoryAction]int),
 		ByAuthor:     make(map[string]int),
 		ByBranch:     make(map[string]int),
@@ -910,7 +805,7 @@ func (s *NoteService) GetNoteHistoryStats() (*entities.NoteHistoryStats, error)
 
 	noteActivity := make(map[int]*entities.NoteActivitySummary)
 
-	for _, entry := range storage.History {
+	for _, entry := range entries {
 
 		history.ByAction[entry.Action]++
 
@@ -942,7 +837,7 @@ func (s *NoteService) GetNoteHistoryStats() (*entities.NoteHistoryStats, error)
 		} else {
 
 			noteTitle := "Unknown"
-			for _, note := range storage.Notes {
+			for _, note := range notes {
 				if note.ID == entry.NoteID {
 					noteTitle = note.Title
 					break
@@ -971,12 +866,12 @@ func (s *NoteService) GetNoteHistoryStats() (*entities.NoteHistoryStats, error)
 	}
 
 	recentCount := 20
-	if len(storage.History) < recentCount {
-		recentCount = len(storage.History)
+	if len(entries) < recentCount {
+		recentCount = len(entries)
 	}
 
-	sortedHistory := make([]entities.NoteHistoryEntry, len(storage.History))
-	copy(sortedHistory, storage.History)
+	sortedHistory := make([]entities.NoteHistoryEntry, len(entries))
+	copy(sortedHistory, entries)
 	sort.Slice(sortedHistory, func(i, j int) bool {
 		return sortedHistory[i].Timestamp.After(sortedHistory[j].Timestamp)
 	})
@@ -987,14 +882,73 @@ func (s *NoteService) GetNoteHistoryStats() (*entities.NoteHistoryStats, error)
 }
 
 func (s *NoteService) getNoteByID(noteID int) *entities.Note {
-	notes, _ := s.GetNotes("", "", "")
-	for _, note := range notes {
-		if note.ID == noteID {
-			return &note
+	note, err := s.GetNote(noteID)
+	if err != nil {
+		return nil
+	}
+
+	return note
+}
+
+func (s *NoteService) GetNote(id int) (*entities.Note, error) {
+	var note entities.Note
+	err := s.store.ViewNotes(func(tx storage.NoteTx) error {
+		var err error
+		note, err = tx.Note(id)
+		return err
+	})
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, errors.New("note not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &note, nil
+}
+
+// CleanupHistory drops the history entries older than the cutoff, keeping
+// at least keepMinimum of the most recent entries of every note.
+func (s *NoteService) CleanupHistory(olderThanDays, keepMinimum int) (int, int, error) {
+	removed, remaining := 0, 0
+	err := s.updateNotes(func(tx storage.NoteTx) error {
+		entries, err := tx.History(entities.NoteHistoryFilter{})
+		if err != nil {
+			return err
 		}
+
+		cutoff := time.Now().AddDate(0, 0, -olderThanDays)
+
+		noteHistory := make(map[int][]entities.NoteHistoryEntry)
+		for _, entry := range entries {
+			noteHistory[entry.NoteID] = append(noteHistory[entry.NoteID], entry)
+		}
+
+		for _, entries := range noteHistory {
+			sort.Slice(entries, func(i, j int) bool {
+				return entries[i].Timestamp.After(entries[j].Timestamp)
+			})
+
+			keptCount := 0
+			for _, entry := range entries {
+				if keptCount < keepMinimum || entry.Timestamp.After(cutoff) {
+					keptCount++
+					remaining++
+					continue
+				}
+				if err := tx.DeleteHistory(entry.ID); err != nil {
+					return err
+				}
+				removed++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
 	}
 
-	return nil
+	return removed, remaining, nil
 }
 
 func equalSlices(a, b []string) bool {
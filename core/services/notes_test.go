@@ -0,0 +1,42 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/prodemmi/kodo/core/storage"
+	"go.uber.org/zap"
+)
+
+func TestConcurrentNoteChangesKeepEveryWrite(t *testing.T) {
+	config := newTestProject(t, nil)
+	store, err := storage.Open(config.Flags.Config, storage.BackendJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	notes := NewNoteService(config, zap.NewNop(), store)
+
+	author := "tester"
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := notes.CreateNoteWithHistory(fmt.Sprintf("note %d", i), "", nil, "", nil, &author); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	all, err := notes.GetNotes("", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != writers {
+		t.Fatalf("got %d notes, want %d", len(all), writers)
+	}
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+type QueryOperator string
+
+const (
+	QueryOpEqual        QueryOperator = "="
+	QueryOpGreater      QueryOperator = ">"
+	QueryOpGreaterEqual QueryOperator = ">="
+	QueryOpLess         QueryOperator = "<"
+	QueryOpLessEqual    QueryOperator = "<="
+)
+
+type QueryTerm struct {
+	Field    string        `json:"field"`
+	Negate   bool          `json:"negate"`
+	Operator QueryOperator `json:"operator"`
+	Values   []string      `json:"values"`
+
+	duration time.Duration
+}
+
+type ItemQuery struct {
+	Raw   string      `json:"raw"`
+	Terms []QueryTerm `json:"terms"`
+
+	// firstSeen holds when items were first seen by key; age falls back to
+	// the item's own dates for items it does not have.
+	firstSeen map[string]time.Time
+}
+
+var queryFields = map[string]struct{}{
+	"type":     {},
+	"priority": {},
+	"status":   {},
+	"path":     {},
+	"file":     {},
+	"age":      {},
+	"text":     {},
+	"id":       {},
+}
+
+func ParseItemQuery(raw string) (*ItemQuery, error) {
+	query := &ItemQuery{Raw: raw}
+
+	tokens, err := tokenizeQuery(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		term, err := parseQueryTerm(token)
+		if err != nil {
+			return nil, err
+		}
+		query.Terms = append(query.Terms, term)
+	}
+
+	return query, nil
+}
+
+func tokenizeQuery(raw string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in query: %s", raw)
+	}
+
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, nil
+}
+
+func parseQueryTerm(token string) (QueryTerm, error) {
+	term := QueryTerm{Operator: QueryOpEqual}
+
+	if strings.HasPrefix(token, "-") && len(token) > 1 {
+		term.Negate = true
+		token = token[1:]
+	}
+
+	field, value, hasField := strings.Cut(token, ":")
+	if !hasField || strings.HasPrefix(field, `"`) {
+		term.Field = "text"
+		term.Values = []string{unquote(token)}
+		return term, nil
+	}
+
+	field = strings.ToLower(field)
+	if _, ok := queryFields[field]; !ok {
+		return term, fmt.Errorf("unknown query field %q", field)
+	}
+	term.Field = field
+
+	if strings.HasPrefix(value, "!") {
+		term.Negate = !term.Negate
+		value = value[1:]
+	}
+
+	for _, op := range []QueryOperator{QueryOpGreaterEqual, QueryOpLessEqual, QueryOpGreater, QueryOpLess, QueryOpEqual} {
+		if strings.HasPrefix(value, string(op)) {
+			term.Operator = op
+			value = value[len(op):]
+			break
+		}
+	}
+
+	if term.Operator != QueryOpEqual && field != "age" && field != "id" {
+		return term, fmt.Errorf("operator %s is not supported for field %q", term.Operator, field)
+	}
+
+	value = unquote(value)
+	if value == "" {
+		return term, fmt.Errorf("missing value for field %q", field)
+	}
+
+	if field == "text" {
+		term.Values = []string{value}
+	} else {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				term.Values = append(term.Values, v)
+			}
+		}
+	}
+
+	switch field {
+	case "age":
+		if len(term.Values) != 1 {
+			return term, fmt.Errorf("age accepts a single duration")
+		}
+		duration, err := parseQueryDuration(term.Values[0])
+		if err != nil {
+			return term, err
+		}
+		term.duration = duration
+	case "id":
+		for _, v := range term.Values {
+			if _, err := strconv.Atoi(v); err != nil {
+				return term, fmt.Errorf("invalid id %q", v)
+			}
+		}
+		if term.Operator != QueryOpEqual && len(term.Values) != 1 {
+			return term, fmt.Errorf("id comparison accepts a single value")
+		}
+	}
+
+	return term, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return strings.Trim(value, `"`)
+}
+
+func parseQueryDuration(value string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"y": 365 * 24 * time.Hour,
+	}
+
+	unit := time.Duration(24 * time.Hour)
+	number := value
+	if last := value[len(value)-1:]; unicode.IsLetter(rune(last[0])) {
+		u, ok := units[strings.ToLower(last)]
+		if !ok {
+			return 0, fmt.Errorf("unknown duration unit %q", last)
+		}
+		unit = u
+		number = value[:len(value)-1]
+	}
+
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+// WithFirstSeen returns a copy of the query that measures age from the
+// first seen times of the item lifecycles. The scanner stamps items with
+// the scan time, so without them every item is about as old as the scan.
+func (q *ItemQuery) WithFirstSeen(firstSeen map[string]time.Time) *ItemQuery {
+	if q == nil {
+		return nil
+	}
+	query := *q
+	query.firstSeen = firstSeen
+	return &query
+}
+
+func (q *ItemQuery) IsEmpty() bool {
+	return q == nil || len(q.Terms) == 0
+}
+
+func (q *ItemQuery) Match(item *entities.Item) bool {
+	if q == nil {
+		return true
+	}
+
+	for _, term := range q.Terms {
+		if term.match(item, q.firstSeen) == term.Negate {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (q *ItemQuery) Filter(items []*entities.Item) []*entities.Item {
+	filtered := []*entities.Item{}
+	for _, item := range items {
+		if q.Match(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+func (t QueryTerm) match(item *entities.Item, firstSeen map[string]time.Time) bool {
+	switch t.Field {
+	case "type":
+		return t.matchAny(func(v string) bool {
+			return strings.EqualFold(string(item.Type), v)
+		})
+	case "priority":
+		return t.matchAny(func(v string) bool {
+			return strings.EqualFold(string(item.Priority), v)
+		})
+	case "status":
+		status := normalizeStatus(string(item.Status))
+		return t.matchAny(func(v string) bool {
+			return status == normalizeStatus(v)
+		})
+	case "path", "file":
+		path := filepath.ToSlash(item.File)
+		return t.matchAny(func(v string) bool {
+			if matched, err := doublestar.Match(v, path); err == nil && matched {
+				return true
+			}
+			return strings.HasPrefix(path, strings.TrimSuffix(v, "/")+"/") || path == v
+		})
+	case "text":
+		haystack := strings.ToLower(item.Title + "\n" + item.Description)
+		return t.matchAny(func(v string) bool {
+			return strings.Contains(haystack, strings.ToLower(v))
+		})
+	case "id":
+		return t.matchAny(func(v string) bool {
+			id, _ := strconv.Atoi(v)
+			return compareInt(item.ID, id, t.Operator)
+		})
+	case "age":
+		seen, ok := firstSeen[item.Key]
+		if !ok || seen.IsZero() {
+			seen = itemFirstSeen(item)
+		}
+		age := time.Since(seen)
+		return compareInt(int(age/time.Minute), int(t.duration/time.Minute), t.Operator)
+	}
+
+	return false
+}
+
+func (t QueryTerm) matchAny(fn func(v string) bool) bool {
+	for _, v := range t.Values {
+		if fn(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareInt(a, b int, op QueryOperator) bool {
+	switch op {
+	case QueryOpGreater:
+		return a > b
+	case QueryOpGreaterEqual:
+		return a >= b
+	case QueryOpLess:
+		return a < b
+	case QueryOpLessEqual:
+		return a <= b
+	default:
+		return a == b
+	}
+}
+
+func normalizeStatus(status string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(status), " ", "_"))
+}
+
+func itemFirstSeen(item *entities.Item) time.Time {
+	firstSeen := item.CreatedAt
+	for _, h := range item.History {
+		if !h.Timestamp.IsZero() && h.Timestamp.Before(firstSeen) {
+			firstSeen = h.Timestamp
+		}
+	}
+	return firstSeen
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+func TestTokenizeQuery(t *testing.T) {
+	tokens, err := tokenizeQuery(`type:TODO  text:"fix the bug" -path:core/ "loose words"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"type:TODO", `text:"fix the bug"`, "-path:core/", `"loose words"`}
+	if !slices.Equal(tokens, want) {
+		t.Errorf("got %q, want %q", tokens, want)
+	}
+}
+
+func TestParseItemQueryErrors(t *testing.T) {
+	for _, raw := range []string{
+		`text:"open`,
+		"owner:me",
+		"type:",
+		"type:>TODO",
+		"age:3x",
+		"age:-3d",
+		"age:3d,4d",
+		"id:abc",
+		"id:>1,2",
+	} {
+		if _, err := ParseItemQuery(raw); err == nil {
+			t.Errorf("%q: parsed without an error", raw)
+		}
+	}
+}
+
+func queryItem(id int, itemType entities.ItemType, priority entities.ItemPriority, file, title string) *entities.Item {
+	return &entities.Item{
+		ID:        id,
+		Key:       entities.ItemKey(file, itemType, title, 0),
+		Type:      itemType,
+		Priority:  priority,
+		Status:    "IN PROGRESS",
+		File:      file,
+		Title:     title,
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestItemQueryMatch(t *testing.T) {
+	items := []*entities.Item{
+		queryItem(1, "TODO", "HIGH", "core/a.go", "fix the parser"),
+		queryItem(2, "FIXME", "LOW", "core/b.go", "remove hack"),
+		queryItem(3, "TODO", "", "web/c.ts", "add tests"),
+	}
+
+	for raw, want := range map[string][]int{
+		"type:todo":              {1, 3},
+		"-type:TODO":             {2},
+		"type:!TODO":             {2},
+		"-type:!TODO":            {1, 3},
+		"type:TODO,FIXME":        {1, 2, 3},
+		"priority:high":          {1},
+		"status:in_progress":     {1, 2, 3},
+		"path:core/":             {1, 2},
+		"file:**/*.ts":           {3},
+		`"the parser"`:           {1},
+		`text:"remove hack"`:     {2},
+		"id:2":                   {2},
+		"id:>2":                  {3},
+		"id:>=2":                 {2, 3},
+		"id:<2":                  {1},
+		"id:<=2":                 {1, 2},
+		"id:=3":                  {3},
+		"type:TODO -path:web/":   {1},
+		"type:TODO priority:LOW": {},
+	} {
+		query, err := ParseItemQuery(raw)
+		if err != nil {
+			t.Errorf("%q: %v", raw, err)
+			continue
+		}
+		got := []int{}
+		for _, item := range query.Filter(items) {
+			got = append(got, item.ID)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%q: got %v, want %v", raw, got, want)
+		}
+	}
+}
+
+func TestItemQueryAge(t *testing.T) {
+	old := queryItem(1, "TODO", "", "a.go", "old")
+	recent := queryItem(2, "TODO", "", "a.go", "recent")
+	unknown := queryItem(3, "TODO", "", "a.go", "unknown")
+	unknown.History = []entities.StatusHistory{{Timestamp: time.Now().AddDate(0, 0, -40)}}
+	items := []*entities.Item{old, recent, unknown}
+
+	firstSeen := map[string]time.Time{
+		old.Key:    time.Now().AddDate(0, 0, -60),
+		recent.Key: time.Now().AddDate(0, 0, -2),
+	}
+
+	for raw, want := range map[string][]int{
+		"age:>30d":  {1, 3},
+		"age:>=8w":  {1},
+		"age:<1d":   {},
+		"age:<=3d":  {2},
+		"-age:>30d": {2},
+	} {
+		query, err := ParseItemQuery(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []int{}
+		for _, item := range query.WithFirstSeen(firstSeen).Filter(items) {
+			got = append(got, item.ID)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%q: got %v, want %v", raw, got, want)
+		}
+	}
+
+	query, err := ParseItemQuery("age:>30d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Match(old) {
+		t.Error("age without first seen times matched an item stamped at scan time")
+	}
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+// ReleaseNotes lists the items resolved and introduced between two refs,
+// grouped by type and directory, with the commits in the range that touched
+// each of them.
+func (pt *HistoryService) ReleaseNotes(settings *SettingsService, from, to string) (*entities.ReleaseNotes, error) {
+	if from == "" {
+		return nil, errors.New("from ref is required")
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+
+	snapshots := pt.fullSnapshots()
+	fromSide, err := pt.resolveCompareSide(from, snapshots, settings)
+	if err != nil {
+		return nil, err
+	}
+	toSide, err := pt.resolveCompareSide(to, snapshots, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	currentSettings, err := settings.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	diff := diffTaskItems(fromSide.History.Items, toSide.History.Items)
+	commitURL := githubCommitURL()
+	commitRange := fromSide.Commit + ".." + toSide.Commit
+
+	var resolved, introduced []entities.ReleaseItem
+	for _, item := range diff.Removed {
+		if isTaskItemDone(item, currentSettings) {
+			continue
+		}
+		resolved = append(resolved, releaseItem(item, "removed", commitRange, commitURL))
+	}
+	for _, change := range diff.StatusChanged {
+		item := change["item"].(entities.TaskItem)
+		previous := item
+		previous.Status = change["old_status"].(entities.ItemStatus)
+		if isTaskItemDone(item, currentSettings) && !isTaskItemDone(previous, currentSettings) {
+			resolved = append(resolved, releaseItem(item, "done", commitRange, commitURL))
+		}
+	}
+	for _, item := range diff.Added {
+		if isTaskItemDone(item, currentSettings) {
+			continue
+		}
+		introduced = append(introduced, releaseItem(item, "", commitRange, commitURL))
+	}
+
+	return &entities.ReleaseNotes{
+		From:       releaseRef(fromSide),
+		To:         releaseRef(toSide),
+		Resolved:   groupReleaseItems(resolved),
+		Introduced: groupReleaseItems(introduced),
+		Summary: map[string]int{
+			"resolved":   len(resolved),
+			"introduced": len(introduced),
+		},
+	}, nil
+}
+
+func releaseRef(side *CompareSide) entities.ReleaseRef {
+	return entities.ReleaseRef{
+		Ref:         side.Ref,
+		Commit:      side.Commit,
+		CommitShort: side.CommitShort,
+		Timestamp:   side.Timestamp,
+	}
+}
+
+func releaseItem(item entities.TaskItem, resolution, commitRange string, commitURL func(string) string) entities.ReleaseItem {
+	return entities.ReleaseItem{
+		Type:       item.Type,
+		Title:      item.Title,
+		File:       filepath.ToSlash(item.File),
+		Line:       item.Line,
+		Priority:   item.Priority,
+		Resolution: resolution,
+		Commits:    itemCommits(item, commitRange, commitURL),
+	}
+}
+
+// itemCommits returns the commits in the range that added or removed the
+// item's title in its file, oldest first.
+func itemCommits(item entities.TaskItem, commitRange string, commitURL func(string) string) []entities.ReleaseCommit {
+	commits := []entities.ReleaseCommit{}
+	if item.Title == "" {
+		return commits
+	}
+
+	output, err := exec.Command("git", "log", "--reverse", "--format=%H%x1f%h%x1f%an%x1f%ct%x1f%s",
+		"-S", item.Title, commitRange, "--", filepath.ToSlash(item.File)).Output()
+	if err != nil {
+		return commits
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, "\x1f", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		unix, _ := strconv.ParseInt(fields[3], 10, 64)
+		commits = append(commits, entities.ReleaseCommit{
+			Hash:      fields[0],
+			Short:     fields[1],
+			Author:    fields[2],
+			Timestamp: time.Unix(unix, 0),
+			Message:   fields[4],
+			URL:       commitURL(fields[0]),
+		})
+	}
+
+	return commits
+}
+
+func groupReleaseItems(items []entities.ReleaseItem) []entities.ReleaseGroup {
+	groups := []entities.ReleaseGroup{}
+	index := make(map[string]int)
+
+	for _, item := range items {
+		dir := path.Dir(item.File)
+		key := string(item.Type) + "\x00" + dir
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, entities.ReleaseGroup{Type: item.Type, Directory: dir})
+		}
+		groups[i].Items = append(groups[i].Items, item)
+	}
+
+	slices.SortFunc(groups, func(a, b entities.ReleaseGroup) int {
+		if c := strings.Compare(string(a.Type), string(b.Type)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Directory, b.Directory)
+	})
+	for _, group := range groups {
+		slices.SortFunc(group.Items, func(a, b entities.ReleaseItem) int {
+			if c := strings.Compare(a.File, b.File); c != 0 {
+				return c
+			}
+			return a.Line - b.Line
+		})
+	}
+
+	return groups
+}
+
+// githubCommitURL links commits when origin points at GitHub.
+func githubCommitURL() func(string) string {
+	out, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil || !strings.Contains(string(out), "github.com") {
+		return func(string) string { return "" }
+	}
+
+	owner, repo, err := getRepoOwnerAndName()
+	if err != nil {
+		return func(string) string { return "" }
+	}
+
+	return func(hash string) string {
+		return fmt.Sprintf("https://github.com/%s/%s/commit/%s", owner, repo, hash)
+	}
+}
+
+func RenderReleaseNotesMarkdown(notes *entities.ReleaseNotes) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Release notes: %s...%s\n\n", releaseRefName(notes.From), releaseRefName(notes.To))
+	fmt.Fprintf(&b, "%d items resolved, %d introduced.\n", notes.Summary["resolved"], notes.Summary["introduced"])
+
+	for _, section := range []struct {
+		title  string
+		groups []entities.ReleaseGroup
+	}{{"Resolved", notes.Resolved}, {"Introduced", notes.Introduced}} {
+		fmt.Fprintf(&b, "\n## %s\n", section.title)
+		if len(section.groups) == 0 {
+			b.WriteString("\nNothing.\n")
+			continue
+		}
+
+		var lastType entities.ItemType
+		for _, group := range section.groups {
+			if group.Type != lastType {
+				fmt.Fprintf(&b, "\n### %s\n", group.Type)
+				lastType = group.Type
+			}
+			fmt.Fprintf(&b, "\n#### `%s`\n\n", group.Directory)
+
+			for _, item := range group.Items {
+				fmt.Fprintf(&b, "- %s (`%s`)", item.Title, item.File)
+				if item.Resolution == "done" {
+					b.WriteString(" marked done")
+				}
+				for i, commit := range item.Commits {
+					sep := ", "
+					if i == 0 {
+						sep = " — "
+					}
+					if commit.URL != "" {
+						fmt.Fprintf(&b, "%s[%s](%s)", sep, commit.Short, commit.URL)
+					} else {
+						fmt.Fprintf(&b, "%s%s", sep, commit.Short)
+					}
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func releaseRefName(ref entities.ReleaseRef) string {
+	if ref.Ref != "" {
+		return ref.Ref
+	}
+	return ref.CommitShort
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+func releaseGroupTitles(groups []entities.ReleaseGroup) []string {
+	titles := []string{}
+	for _, group := range groups {
+		var items []string
+		for _, item := range group.Items {
+			items = append(items, item.Title)
+		}
+		titles = append(titles, string(group.Type)+" "+group.Directory+": "+strings.Join(items, ", "))
+	}
+	return titles
+}
+
+func TestReleaseNotesGroupItems(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	config := newTestProject(t, map[string]string{
+		"src/a.go": "package src\n\n// TODO: one\nfunc a() {}\n",
+		"src/b.go": "package src\n\n// FIXME: two\nfunc b() {}\n",
+		"lib/c.go": "package lib\n\n// TODO: three\nfunc c() {}\n",
+	})
+	runGit(t, "init", "-q")
+	runGit(t, "add", ".")
+	runGit(t, "commit", "-qm", "first release")
+	runGit(t, "tag", "v1")
+
+	writeTestFile(t, "src/a.go", "package src\n\nfunc a() {}\n\n// TODO: five\nfunc e() {}\n")
+	writeTestFile(t, "src/b.go", "package src\n\nfunc b() {}\n")
+	writeTestFile(t, "lib/c.go", "package lib\n\n// TODO: three\nfunc c() {}\n\n// TODO: four\nfunc d() {}\n")
+	runGit(t, "commit", "-qam", "fix: one and two")
+
+	scanner := newTestScanner(t, config)
+	notes, err := scanner.historyService.ReleaseNotes(scanner.settings, "v1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolved := strings.Join(releaseGroupTitles(notes.Resolved), "; ")
+	if want := "FIXME src: two; TODO src: one"; resolved != want {
+		t.Errorf("resolved = %q, want %q", resolved, want)
+	}
+	introduced := strings.Join(releaseGroupTitles(notes.Introduced), "; ")
+	if want := "TODO lib: four; TODO src: five"; introduced != want {
+		t.Errorf("introduced = %q, want %q", introduced, want)
+	}
+	if notes.Summary["resolved"] != 2 || notes.Summary["introduced"] != 2 {
+		t.Errorf("summary = %v, want 2 resolved and 2 introduced", notes.Summary)
+	}
+
+	one := notes.Resolved[1].Items[0]
+	if one.Resolution != "removed" || len(one.Commits) != 1 || one.Commits[0].Message != "fix: one and two" {
+		t.Errorf("resolved item = %+v, want it removed by the fix commit", one)
+	}
+
+	markdown := RenderReleaseNotesMarkdown(notes)
+	for _, want := range []string{"# Release notes: v1...HEAD", "2 items resolved, 2 introduced.", "### FIXME", "#### `src`", "- one (`src/a.go`)"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("markdown is missing %q:\n%s", want, markdown)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v55/github"
@@ -15,16 +16,27 @@ import (
 )
 
 type RemoteService struct {
-	logger      *zap.Logger
-	settings    *SettingsService
-	noteService *NoteService
+	config         *entities.Config
+	logger         *zap.Logger
+	settings       *SettingsService
+	noteService    *NoteService
+	scannerService *ScannerService
+	historyService *HistoryService
+
+	// itemSyncMu serialises the issue syncs of this process, so two of them
+	// do not comment on and close the same issue. item_issues.json itself is
+	// guarded by its file lock.
+	itemSyncMu sync.Mutex
 }
 
-func NewRemoteManager(logger *zap.Logger, settings *SettingsService, noteService *NoteService) *RemoteService {
+func NewRemoteManager(config *entities.Config, logger *zap.Logger, settings *SettingsService, noteService *NoteService, scannerService *ScannerService, historyService *HistoryService) *RemoteService {
 	return &RemoteService{
-		logger:      logger,
-		settings:    settings,
-		noteService: noteService,
+		config:         config,
+		logger:         logger,
+		settings:       settings,
+		noteService:    noteService,
+		scannerService: scannerService,
+		historyService: historyService,
 	}
 }
 
@@ -50,7 +62,10 @@ type SyncConflict struct {
 func (r *RemoteService) SyncIssuesWithNotes() (*SyncResult, error) {
 	result := &SyncResult{}
 
-	settings := r.settings.LoadSettings()
+	settings, err := r.settings.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
 	githubToken := settings.GithubAuth.Token
 
 	if !settings.CodeScanSettings.SyncEnabled {
@@ -291,7 +306,7 @@ func (r *RemoteService) createNotesForNewIssues(ctx context.Context, githubIssue
 			continue
 		}
 
-		if !r.hasNoteManagedLabel(issue) {
+		if !r.hasNoteManagedLabel(issue) && !r.hasItemManagedLabel(issue) {
 
 			err := r.createNoteFromGitHubIssue(issue, result)
 			if err != nil {
@@ -312,6 +327,15 @@ func (r *RemoteService) hasNoteManagedLabel(issue *github.Issue) bool {
 	return false
 }
 
+func (r *RemoteService) hasItemManagedLabel(issue *github.Issue) bool {
+	for _, label := range issue.Labels {
+		if label.GetName() == itemManagedLabel {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *RemoteService) createNoteFromGitHubIssue(issue *github.Issue, result *SyncResult) error {
 
 	content := r.extractNoteContentFromIssue(issue)
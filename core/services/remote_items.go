@@ -0,0 +1,447 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v55/github"
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+const itemManagedLabel = "item-managed"
+
+type ItemSyncResult struct {
+	IssuesClosed int      `json:"issues_closed"`
+	ItemsMoved   int      `json:"items_moved"`
+	Tracked      int      `json:"tracked"`
+	Errors       []string `json:"errors"`
+}
+
+func (r *RemoteService) getItemIssuesFilePath() string {
+	wd, _ := os.Getwd()
+	return filepath.Join(wd, r.config.Flags.Config, "item_issues.json")
+}
+
+func (r *RemoteService) loadItemIssueStorage() (*entities.ItemIssueStorage, error) {
+	data, err := os.ReadFile(r.getItemIssuesFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &entities.ItemIssueStorage{Issues: []entities.ItemIssue{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read item issues file: %v", err)
+	}
+
+	var storage entities.ItemIssueStorage
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal item issues: %v", err)
+	}
+
+	return &storage, nil
+}
+
+func (r *RemoteService) saveItemIssueStorage(issues *entities.ItemIssueStorage) error {
+	data, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal item issues: %v", err)
+	}
+
+	if err := storage.WriteFile(r.getItemIssuesFilePath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write item issues file: %v", err)
+	}
+
+	return nil
+}
+
+// updateItemIssueStorage runs fn on the item issues while holding the lock
+// of item_issues.json and saves them, unless fn returns an error or
+// errUnchanged. fn must not call GitHub: the lock times out.
+func (r *RemoteService) updateItemIssueStorage(fn func(issues *entities.ItemIssueStorage) error) error {
+	return storage.WithFileLock(r.getItemIssuesFilePath(), func() error {
+		issues, err := r.loadItemIssueStorage()
+		if err != nil {
+			return err
+		}
+
+		if err := fn(issues); err != nil {
+			if errors.Is(err, errUnchanged) {
+				return nil
+			}
+			return err
+		}
+		return r.saveItemIssueStorage(issues)
+	})
+}
+
+func (r *RemoteService) GetItemIssues() ([]entities.ItemIssue, error) {
+	storage, err := r.loadItemIssueStorage()
+	if err != nil {
+		return nil, err
+	}
+	return storage.Issues, nil
+}
+
+func (r *RemoteService) newGitHubClient() (context.Context, *github.Client, string, string, error) {
+	settings, err := r.settings.LoadSettings()
+	if err != nil {
+		return nil, nil, "", "", err
+	}
+
+	if !settings.CodeScanSettings.SyncEnabled {
+		return nil, nil, "", "", fmt.Errorf("sync not enabled")
+	}
+
+	if settings.GithubAuth.Token == "" {
+		return nil, nil, "", "", fmt.Errorf("GitHub token not configured")
+	}
+
+	owner, repo, err := getRepoOwnerAndName()
+	if err != nil {
+		return nil, nil, "", "", fmt.Errorf("failed to get repo info: %v", err)
+	}
+
+	ctx := context.Background()
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: settings.GithubAuth.Token})
+	client := github.NewClient(oauth2.NewClient(ctx, ts))
+
+	return ctx, client, owner, repo, nil
+}
+
+func (r *RemoteService) CreateIssueForItem(item *entities.Item) (*entities.ItemIssue, error) {
+	if item.IssueNumber != nil {
+		return nil, fmt.Errorf("item is already linked to issue #%d", *item.IssueNumber)
+	}
+
+	ctx, client, owner, repo, err := r.newGitHubClient()
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := getHeadCommit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve current commit: %v", err)
+	}
+
+	permalink := fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s#L%d", owner, repo, commit, filepath.ToSlash(item.File), item.Line)
+	title := item.GetFullTitle()
+	body := r.formatIssueBodyFromItem(item, permalink)
+	labels := []string{itemManagedLabel, fmt.Sprintf("type:%s", strings.ToLower(string(item.Type)))}
+
+	issueRequest := &github.IssueRequest{
+		Title:  &title,
+		Body:   &body,
+		Labels: &labels,
+	}
+
+	// A create that timed out may still have opened the issue, so every
+	// retry first looks for an issue carrying the item's key. The margin
+	// covers clock skew against GitHub.
+	since := time.Now().Add(-time.Minute)
+
+	var createdIssue *github.Issue
+	for retries := 3; retries > 0; retries-- {
+		createdIssue, _, err = client.Issues.Create(ctx, owner, repo, issueRequest)
+		if err == nil || retries == 1 {
+			break
+		}
+		r.logger.Warn("Retrying issue creation", zap.Int("retries_left", retries-1), zap.Error(err))
+		time.Sleep(2 * time.Second)
+
+		existing, findErr := r.findItemIssue(ctx, client, owner, repo, item.Key, since)
+		if findErr != nil {
+			err = fmt.Errorf("%v (not retried, looking up the issue failed: %v)", err, findErr)
+			break
+		}
+		if existing != nil {
+			createdIssue, err = existing, nil
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create issue for item %s:%d: %v", item.File, item.Line, err)
+	}
+
+	issueNumber := createdIssue.GetNumber()
+	if err := r.scannerService.SetItemIssueReference(item, issueNumber); err != nil {
+		return nil, fmt.Errorf("issue #%d created but failed to write reference back: %v", issueNumber, err)
+	}
+
+	now := time.Now().UTC()
+	itemIssue := entities.ItemIssue{
+		IssueNumber: issueNumber,
+		IssueURL:    createdIssue.GetHTMLURL(),
+		ItemKey:     item.Key,
+		File:        item.File,
+		Line:        item.Line,
+		Type:        item.Type,
+		Title:       item.Title,
+		Commit:      commit,
+		State:       createdIssue.GetState(),
+		CreatedAt:   now,
+		LastSync:    &now,
+	}
+
+	err = r.updateItemIssueStorage(func(issues *entities.ItemIssueStorage) error {
+		for _, existing := range issues.Issues {
+			if existing.IssueNumber == issueNumber {
+				return errUnchanged
+			}
+		}
+		issues.Issues = append(issues.Issues, itemIssue)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("Created issue for item",
+		zap.Int("issue_number", issueNumber),
+		zap.String("file", item.File),
+		zap.Int("line", item.Line))
+
+	return &itemIssue, nil
+}
+
+// findItemIssue returns the item-managed issue of the item with key that
+// was updated since since, or nil.
+func (r *RemoteService) findItemIssue(ctx context.Context, client *github.Client, owner, repo, key string, since time.Time) (*github.Issue, error) {
+	marker := fmt.Sprintf("Item-Key: %s\n", key)
+
+	issues, _, err := client.Issues.ListByRepo(ctx, owner, repo, &github.IssueListByRepoOptions{
+		State:       "all",
+		Labels:      []string{itemManagedLabel},
+		Since:       since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		if strings.Contains(issue.GetBody(), marker) {
+			return issue, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *RemoteService) SyncItemIssues() (*ItemSyncResult, error) {
+	ctx, client, owner, repo, err := r.newGitHubClient()
+	if err != nil {
+		return nil, err
+	}
+	return r.syncItemIssues(ctx, client, owner, repo)
+}
+
+// syncItemIssues closes the issues of resolved items and moves the items of
+// closed issues to the last column. The GitHub calls run without the lock
+// of item_issues.json; the results are merged into a fresh copy of it.
+func (r *RemoteService) syncItemIssues(ctx context.Context, client *github.Client, owner, repo string) (*ItemSyncResult, error) {
+	result := &ItemSyncResult{Errors: []string{}}
+
+	r.itemSyncMu.Lock()
+	defer r.itemSyncMu.Unlock()
+
+	if err := r.scannerService.Rescan(); err != nil {
+		return nil, fmt.Errorf("failed to rescan items: %v", err)
+	}
+
+	stored, err := r.loadItemIssueStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := make(map[int]bool)
+	for _, itemIssue := range stored.Issues {
+		tracked[itemIssue.IssueNumber] = true
+	}
+	for _, item := range r.scannerService.GetItems() {
+		if item.IssueNumber != nil && !tracked[*item.IssueNumber] {
+			stored.Issues = append(stored.Issues, entities.ItemIssue{
+				IssueNumber: *item.IssueNumber,
+				ItemKey:     item.Key,
+				File:        item.File,
+				Line:        item.Line,
+				Type:        item.Type,
+				Title:       item.Title,
+				State:       "open",
+				CreatedAt:   time.Now().UTC(),
+			})
+			tracked[*item.IssueNumber] = true
+		}
+	}
+
+	settings, err := r.settings.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	lifecycles := make(map[string]entities.ItemLifecycle)
+	for _, lifecycle := range r.historyService.GetLifecycles() {
+		lifecycles[lifecycle.Key] = lifecycle
+	}
+
+	synced := make(map[int]entities.ItemIssue)
+	for _, itemIssue := range stored.Issues {
+		if itemIssue.State == "closed" {
+			continue
+		}
+
+		issue, _, err := client.Issues.Get(ctx, owner, repo, itemIssue.IssueNumber)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to load issue #%d: %v", itemIssue.IssueNumber, err))
+			continue
+		}
+
+		now := time.Now().UTC()
+		itemIssue.LastSync = &now
+		itemIssue.IssueURL = issue.GetHTMLURL()
+
+		item := r.scannerService.GetItemByIssue(itemIssue.IssueNumber)
+		if item != nil {
+			itemIssue.ItemKey = item.Key
+			itemIssue.File = item.File
+			itemIssue.Line = item.Line
+		}
+
+		board := settings.DefaultBoard()
+		if item != nil {
+			board = settings.BoardFor(item)
+		}
+		lastColumn := board.Columns[len(board.Columns)-1]
+
+		// An item missing from the scan only resolves its issue once its
+		// lifecycle says so: it may just live on another branch.
+		var resolved bool
+		if item != nil {
+			resolved = board.StatusFor(item.Status) == entities.ItemStatus(lastColumn.ID)
+		} else if lifecycle, ok := lifecycles[itemIssue.ItemKey]; ok {
+			resolved = lifecycle.IsRemoved() && lifecycle.Removal == entities.RemovalResolved
+		}
+
+		switch {
+		case issue.GetState() == "closed":
+			itemIssue.State = "closed"
+			if item != nil && !resolved {
+				if err := r.scannerService.UpdateItemStatus(item, board.ID, lastColumn.ID); err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("failed to move item for issue #%d: %v", itemIssue.IssueNumber, err))
+				} else {
+					result.ItemsMoved++
+				}
+			}
+		case resolved:
+			if err := r.closeItemIssue(ctx, client, owner, repo, &itemIssue, item == nil); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			} else {
+				itemIssue.State = "closed"
+				result.IssuesClosed++
+			}
+		default:
+			result.Tracked++
+		}
+		synced[itemIssue.IssueNumber] = itemIssue
+	}
+
+	// Issues another process added meanwhile are kept; the synced ones and
+	// those found in the code are written over the fresh copy.
+	err = r.updateItemIssueStorage(func(issues *entities.ItemIssueStorage) error {
+		written := make(map[int]bool, len(issues.Issues))
+		for i, itemIssue := range issues.Issues {
+			if update, ok := synced[itemIssue.IssueNumber]; ok {
+				issues.Issues[i] = update
+			}
+			written[itemIssue.IssueNumber] = true
+		}
+		for _, itemIssue := range stored.Issues {
+			if written[itemIssue.IssueNumber] {
+				continue
+			}
+			if update, ok := synced[itemIssue.IssueNumber]; ok {
+				itemIssue = update
+			}
+			issues.Issues = append(issues.Issues, itemIssue)
+			written[itemIssue.IssueNumber] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if result.ItemsMoved > 0 {
+		if err := r.scannerService.Rescan(); err != nil {
+			return result, fmt.Errorf("failed to rescan items: %v", err)
+		}
+	}
+
+	r.logger.Info("Item issue sync completed",
+		zap.Int("issues_closed", result.IssuesClosed),
+		zap.Int("items_moved", result.ItemsMoved),
+		zap.Int("tracked", result.Tracked),
+		zap.Int("errors", len(result.Errors)))
+
+	return result, nil
+}
+
+func (r *RemoteService) closeItemIssue(ctx context.Context, client *github.Client, owner, repo string, itemIssue *entities.ItemIssue, removed bool) error {
+	message := fmt.Sprintf("Resolved in code: `%s` was moved to the last column.", itemIssue.File)
+	if removed {
+		message = fmt.Sprintf("Resolved in code: the %s comment was removed from `%s`.", itemIssue.Type, itemIssue.File)
+	}
+
+	if _, _, err := client.Issues.CreateComment(ctx, owner, repo, itemIssue.IssueNumber, &github.IssueComment{Body: &message}); err != nil {
+		r.logger.Warn("Failed to comment on resolved issue", zap.Int("issue_number", itemIssue.IssueNumber), zap.Error(err))
+	}
+
+	state := "closed"
+	if _, _, err := client.Issues.Edit(ctx, owner, repo, itemIssue.IssueNumber, &github.IssueRequest{State: &state}); err != nil {
+		return fmt.Errorf("failed to close issue #%d: %v", itemIssue.IssueNumber, err)
+	}
+
+	return nil
+}
+
+func (r *RemoteService) formatIssueBodyFromItem(item *entities.Item, permalink string) string {
+	var body strings.Builder
+
+	if item.Description != "" {
+		body.WriteString(item.Description)
+		body.WriteString("\n\n")
+	}
+
+	body.WriteString(permalink)
+	body.WriteString("\n\n")
+
+	body.WriteString("---\n")
+	body.WriteString("<!-- Item Metadata - DO NOT EDIT MANUALLY -->\n")
+	body.WriteString(fmt.Sprintf("Item-Key: %s\n", item.Key))
+	body.WriteString(fmt.Sprintf("Type: %s\n", item.Type))
+	body.WriteString(fmt.Sprintf("Priority: %s\n", item.Priority))
+	body.WriteString(fmt.Sprintf("Location: %s:%d\n", filepath.ToSlash(item.File), item.Line))
+	body.WriteString("<!-- End Item Metadata -->\n")
+
+	return body.String()
+}
+
+func getHeadCommit() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", err
+	}
+
+	commit := strings.TrimSpace(string(out))
+	if commit == "" {
+		return "", errors.New("empty commit hash")
+	}
+
+	return commit, nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v55/github"
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
+	"go.uber.org/zap"
+)
+
+func newTestGitHub(t *testing.T, mux *http.ServeMux) *github.Client {
+	t.Helper()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	base, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = base
+	return client
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestFindItemIssueMatchesItemKey(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/issues", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("labels") != itemManagedLabel {
+			t.Errorf("listed issues with labels %q", r.URL.Query().Get("labels"))
+		}
+		writeJSON(w, []map[string]any{
+			{"number": 1, "body": "Item-Key: a.go:TODO:fix this later\nType: TODO\n"},
+			{"number": 2, "body": "Item-Key: a.go:TODO:fix this\nType: TODO\n"},
+		})
+	})
+	client := newTestGitHub(t, mux)
+	remote := &RemoteService{logger: zap.NewNop()}
+
+	issue, err := remote.findItemIssue(context.Background(), client, "o", "r", "a.go:TODO:fix this", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue == nil || issue.GetNumber() != 2 {
+		t.Fatalf("got issue %v, want #2", issue)
+	}
+
+	issue, err = remote.findItemIssue(context.Background(), client, "o", "r", "b.go:TODO:other", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue != nil {
+		t.Errorf("found issue #%d for an item without one", issue.GetNumber())
+	}
+}
+
+func TestSyncItemIssues(t *testing.T) {
+	config := newTestProject(t, map[string]string{
+		"a.go": "package a\n\n// TODO(#1): resolved in code\nfunc a() {}\n",
+		"b.go": "package b\n\n// TODO(#2): closed on github\nfunc b() {}\n",
+		"c.go": "package c\n\n// TODO(#3): still open\nfunc c() {}\n",
+	})
+	scanner := newTestScanner(t, config)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+	if err := scanner.UpdateItemStatus(scanner.GetItemByIssue(1), entities.DefaultBoardID, "done"); err != nil {
+		t.Fatal(err)
+	}
+	remote := NewRemoteManager(config, zap.NewNop(), scanner.settings, nil, scanner, scanner.historyService)
+
+	states := map[int]string{1: "open", 2: "closed", 3: "open"}
+	var mu sync.Mutex
+	var closed, commented []int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/issues/{number}", func(w http.ResponseWriter, r *http.Request) {
+		number, _ := strconv.Atoi(r.PathValue("number"))
+		if number == 3 {
+			// Another process records an issue while the sync runs.
+			err := storage.WithFileLock(remote.getItemIssuesFilePath(), func() error {
+				issues, err := remote.loadItemIssueStorage()
+				if err != nil {
+					return err
+				}
+				issues.Issues = append(issues.Issues, entities.ItemIssue{IssueNumber: 9, State: "open"})
+				return remote.saveItemIssueStorage(issues)
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}
+		writeJSON(w, map[string]any{"number": number, "state": states[number], "html_url": fmt.Sprintf("https://github.com/o/r/issues/%d", number)})
+	})
+	mux.HandleFunc("POST /repos/o/r/issues/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+		number, _ := strconv.Atoi(r.PathValue("number"))
+		mu.Lock()
+		commented = append(commented, number)
+		mu.Unlock()
+		writeJSON(w, map[string]any{"id": 1})
+	})
+	mux.HandleFunc("PATCH /repos/o/r/issues/{number}", func(w http.ResponseWriter, r *http.Request) {
+		number, _ := strconv.Atoi(r.PathValue("number"))
+		mu.Lock()
+		closed = append(closed, number)
+		mu.Unlock()
+		writeJSON(w, map[string]any{"number": number, "state": "closed"})
+	})
+	client := newTestGitHub(t, mux)
+
+	result, err := remote.syncItemIssues(context.Background(), client, "o", "r")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("sync errors: %v", result.Errors)
+	}
+	if result.IssuesClosed != 1 || result.ItemsMoved != 1 || result.Tracked != 1 {
+		t.Errorf("got %+v, want one issue closed, one item moved and one tracked", result)
+	}
+	if !slices.Equal(closed, []int{1}) || !slices.Equal(commented, []int{1}) {
+		t.Errorf("closed %v and commented on %v, want only #1", closed, commented)
+	}
+
+	if item := scanner.GetItemByIssue(2); item == nil || item.Status != "done" {
+		t.Errorf("item of the closed issue was not moved to done: %+v", item)
+	}
+
+	issues, err := remote.GetItemIssues()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[int]string{}
+	for _, issue := range issues {
+		got[issue.IssueNumber] = issue.State
+	}
+	want := map[int]string{1: "closed", 2: "closed", 3: "open", 9: "open"}
+	if len(got) != len(want) || len(issues) != len(want) {
+		t.Fatalf("got issues %v, want %v", got, want)
+	}
+	for number, state := range want {
+		if got[number] != state {
+			t.Errorf("issue #%d: got state %q, want %q", number, got[number], state)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+// compactSnapshots applies the retention policy: snapshots younger than
+// FullDays are kept as they are, older ones are folded into one rollup per
+// branch and day and then per branch and week. A rollup is the last
+// snapshot of its period with the item list dropped, so counts still line
+// up with full snapshots. Backfilled snapshots follow the same policy, so
+// replaying a long history does not keep every item list around.
+func compactSnapshots(snapshots []entities.BranchSnapshot, retention entities.HistoryRetention, settings *entities.Settings, now time.Time) []entities.BranchSnapshot {
+	slices.SortStableFunc(snapshots, func(a, b entities.BranchSnapshot) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	fullCutoff := now.AddDate(0, 0, -retention.FullDays)
+	dailyCutoff := fullCutoff.AddDate(0, 0, -retention.DailyDays)
+	var weeklyCutoff time.Time
+	if retention.WeeklyDays > 0 {
+		weeklyCutoff = dailyCutoff.AddDate(0, 0, -retention.WeeklyDays)
+	}
+
+	compacted := make([]entities.BranchSnapshot, 0, len(snapshots))
+	buckets := make(map[string]int)
+
+	for _, snapshot := range snapshots {
+		var rollup entities.SnapshotRollup
+		var period string
+
+		switch {
+		case snapshot.Timestamp.After(fullCutoff):
+			compacted = append(compacted, snapshot)
+			continue
+		case snapshot.Timestamp.After(dailyCutoff):
+			rollup = entities.SnapshotRollupDaily
+			period = snapshot.Timestamp.UTC().Format("2006-01-02")
+		case weeklyCutoff.IsZero() || snapshot.Timestamp.After(weeklyCutoff):
+			rollup = entities.SnapshotRollupWeekly
+			year, week := snapshot.Timestamp.UTC().ISOWeek()
+			period = fmt.Sprintf("%d-W%02d", year, week)
+		default:
+			continue
+		}
+
+		snapshot = rollupSnapshot(snapshot, rollup, settings)
+
+		key := string(rollup) + ":" + snapshot.Branch + ":" + period
+		if i, ok := buckets[key]; ok {
+			compacted[i] = snapshot
+			continue
+		}
+		buckets[key] = len(compacted)
+		compacted = append(compacted, snapshot)
+	}
+
+	// A rollup takes the place of the first snapshot of its period, which
+	// can put it before the rollup of another branch.
+	slices.SortStableFunc(compacted, func(a, b entities.BranchSnapshot) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	return compacted
+}
+
+func rollupSnapshot(snapshot entities.BranchSnapshot, rollup entities.SnapshotRollup, settings *entities.Settings) entities.BranchSnapshot {
+	if snapshot.Rollup == "" {
+		boards := make(map[string]entities.BoardStats)
+		for _, board := range settings.GetBoards() {
+			boards[board.ID] = taskItemBoardStats(snapshot.History.Items, &board)
+		}
+		snapshot.Boards = boards
+		snapshot.History.Items = nil
+	}
+	snapshot.Rollup = rollup
+	return snapshot
+}
+
+// snapshotBoardStats returns the counts of a snapshot for one board. Full
+// snapshots are counted from their items, rollups from the stored stats.
+func snapshotBoardStats(snapshot entities.BranchSnapshot, board *entities.Board) entities.BoardStats {
+	if snapshot.Rollup == "" {
+		return taskItemBoardStats(snapshot.History.Items, board)
+	}
+
+	if stats, ok := snapshot.Boards[board.ID]; ok {
+		return stats
+	}
+	return taskItemBoardStats(nil, board)
+}
+
+func taskItemBoardStats(items []entities.TaskItem, board *entities.Board) entities.BoardStats {
+	stats := entities.BoardStats{
+		ByStatus:   make(map[string]int),
+		ByType:     make(map[string]int),
+		ByPriority: make(map[string]int),
+	}
+	for _, col := range board.Columns {
+		stats.ByStatus[col.ID] = 0
+	}
+
+	doneColumnID := board.Columns[len(board.Columns)-1].ID
+	for _, item := range boardTaskItems(items, board) {
+		stats.Total++
+		stats.ByStatus[string(item.Status)]++
+		if item.IsDone {
+			stats.Done++
+			if string(item.Status) != doneColumnID {
+				stats.ByStatus[doneColumnID]++
+			}
+		}
+		stats.ByType[string(item.Type)]++
+		stats.ByPriority[string(item.Priority)]++
+	}
+
+	return stats
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+func testSnapshot(branch, commit string, at time.Time) entities.BranchSnapshot {
+	return entities.BranchSnapshot{
+		Branch:    branch,
+		Commit:    commit,
+		Timestamp: at,
+		History: entities.ItemStats{
+			Total: 1,
+			Items: []entities.TaskItem{{Key: commit, Type: "TODO", Title: commit, Status: "todo"}},
+		},
+	}
+}
+
+func TestCompactSnapshotsKeepsBranchesApart(t *testing.T) {
+	settings := testSettings()
+	now := time.Now()
+	day := now.AddDate(0, 0, -(settings.HistoryRetention.FullDays + 5))
+
+	snapshots := compactSnapshots([]entities.BranchSnapshot{
+		testSnapshot("main", "a", day),
+		testSnapshot("feature", "b", day.Add(time.Minute)),
+		testSnapshot("main", "c", day.Add(2*time.Minute)),
+	}, settings.HistoryRetention, settings, now)
+
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots, want one daily rollup per branch", len(snapshots))
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Rollup != entities.SnapshotRollupDaily {
+			t.Errorf("snapshot %s is not a daily rollup", snapshot.Commit)
+		}
+	}
+	if snapshots[0].Commit != "b" || snapshots[1].Commit != "c" {
+		t.Errorf("kept %s and %s, want the last snapshot of each branch", snapshots[0].Commit, snapshots[1].Commit)
+	}
+}
+
+func TestMergeBackfillCompactsOldBackfilledSnapshots(t *testing.T) {
+	config := newTestProject(t, map[string]string{
+		"a.go": "package a\n\n// TODO: keep me\nfunc a() {}\n",
+	})
+	scanner := newTestScanner(t, config)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := scanner.settings.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().AddDate(0, 0, -(settings.HistoryRetention.FullDays + 5))
+	recent := time.Now().Add(-time.Hour)
+	backfilled := []entities.BranchSnapshot{
+		testSnapshot("main", "a", old),
+		testSnapshot("main", "b", old.Add(time.Minute)),
+		testSnapshot("main", "c", recent),
+	}
+	for i := range backfilled {
+		backfilled[i].Backfilled = true
+	}
+
+	added, _, _, err := scanner.historyService.MergeBackfill(backfilled, scanner.settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 3 {
+		t.Fatalf("added %d snapshots, want 3", added)
+	}
+
+	history, err := scanner.historyService.LoadStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := make(map[string]entities.BranchSnapshot)
+	for _, snapshot := range history.BranchHistory {
+		if snapshot.Backfilled {
+			kept[snapshot.Commit] = snapshot
+		}
+	}
+	if len(kept) != 2 {
+		t.Fatalf("kept %d backfilled snapshots, want a daily rollup and the recent one", len(kept))
+	}
+
+	rollup, ok := kept["b"]
+	if !ok {
+		t.Fatal("old backfilled snapshots were not rolled up into the last one of the day")
+	}
+	if rollup.Rollup != entities.SnapshotRollupDaily || len(rollup.History.Items) != 0 {
+		t.Errorf("old backfilled snapshot is %q with %d items, want a daily rollup without items", rollup.Rollup, len(rollup.History.Items))
+	}
+	if stats := rollup.Boards[settings.GetBoards()[0].ID]; stats.Total != 1 {
+		t.Errorf("rollup counts %d items, want 1", stats.Total)
+	}
+
+	full := kept["c"]
+	if full.Rollup != "" || len(full.History.Items) != 1 {
+		t.Errorf("recent backfilled snapshot was compacted")
+	}
+}
@@ -2,14 +2,19 @@ package services
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
@@ -20,64 +25,208 @@ import (
 
 const LargeFileSize = 1 * 1024 * 1024
 
+var ErrInvalidItemComment = errors.New("invalid item comment")
+
+// commentTerminators end a comment early in some languages; they are
+// escaped in text written into comments.
+var commentTerminators = strings.NewReplacer("*/", "* /", "-->", "-- >")
+
+type RescanHook func(items []*entities.Item) error
+
+// ItemSnapshot is the result of a scan and of the changes made to its
+// items since. A published snapshot and its items are never modified:
+// scans and changes publish a new one with the next version, so readers
+// can keep using the snapshot they loaded without a lock.
+type ItemSnapshot struct {
+	Version   int
+	Items     []*entities.Item
+	ScannedAt time.Time
+}
+
 type ScannerService struct {
-	Items  []*entities.Item
-	todoID int
+	snapshot atomic.Pointer[ItemSnapshot]
+	// mu serialises the changes to the items and the source files they
+	// are written back to; scanMu serialises the rescans.
+	mu      sync.Mutex
+	scanMu  sync.Mutex
+	scanErr error
 
 	historyService *HistoryService
 	settings       *SettingsService
+	logger         *zap.Logger
+	rescanHooks    []RescanHook
 }
 
 func NewScannerService(config *entities.Config, settings *SettingsService, historyService *HistoryService, logger *zap.Logger) *ScannerService {
 	scannerService := &ScannerService{
 		historyService: historyService,
 		settings:       settings,
+		logger:         logger,
 	}
+	scannerService.snapshot.Store(&ItemSnapshot{Items: []*entities.Item{}})
 	return scannerService
 }
 
+// Snapshot returns the current items. The snapshot must not be modified.
+func (s *ScannerService) Snapshot() *ItemSnapshot {
+	return s.snapshot.Load()
+}
+
 func (s *ScannerService) GetItems() []*entities.Item {
-	return s.Items
+	return s.Snapshot().Items
 }
 
 func (s *ScannerService) GetItemsLength() int {
-	return len(s.Items)
+	return len(s.GetItems())
 }
 
+// Rescan scans the project, saves the stats and runs the rescan hooks. A
+// caller that arrives while a rescan runs waits for it and shares the
+// next one, so parallel requests do not pile up scans.
 func (s *ScannerService) Rescan() error {
-	s.ScanTodos()
+	requested := time.Now()
+
+	s.scanMu.Lock()
+	defer s.scanMu.Unlock()
+
+	if s.Snapshot().ScannedAt.After(requested) {
+		return s.scanErr
+	}
 
-	if err := s.historyService.SaveStats(s.GetItems(), s.settings); err != nil {
+	if s.scanErr = s.ScanTodos(); s.scanErr != nil {
+		return s.scanErr
+	}
+	s.scanErr = s.runRescanHooks()
+	return s.scanErr
+}
+
+// RescanOlderThan rescans the project when the current snapshot is older
+// than maxAge, so frequent readers serve the snapshot and share one scan.
+func (s *ScannerService) RescanOlderThan(maxAge time.Duration) error {
+	if time.Since(s.Snapshot().ScannedAt) < maxAge {
+		return nil
+	}
+	return s.Rescan()
+}
+
+func (s *ScannerService) runRescanHooks() error {
+	items := s.GetItems()
+	if err := s.historyService.SaveStats(items, s.settings); err != nil {
 		return err
 	}
 
+	for _, hook := range s.rescanHooks {
+		if err := hook(items); err != nil {
+			s.logger.Warn("Rescan hook failed", zap.Error(err))
+		}
+	}
+
 	return nil
 }
 
-func (s *ScannerService) ScanTodos() {
-	s.Items = []*entities.Item{}
-	s.todoID = 0
+func (s *ScannerService) OnRescan(hook RescanHook) {
+	s.rescanHooks = append(s.rescanHooks, hook)
+}
 
-	wd, err := os.Getwd()
-	if err != nil {
-		fmt.Printf("Error getting working directory: %v\n", err)
-		return
+func (s *ScannerService) GetItemByID(id int) *entities.Item {
+	for _, item := range s.GetItems() {
+		if item.ID == id {
+			return item
+		}
+	}
+	return nil
+}
+
+func (s *ScannerService) GetItemByKey(key string) *entities.Item {
+	for _, item := range s.GetItems() {
+		if item.Key == key {
+			return item
+		}
+	}
+	return nil
+}
+
+// itemEdit is a copy-on-write view of the current items. item hands out a
+// private copy that the edit may change; the items it did not ask for stay
+// shared with the published snapshot.
+type itemEdit struct {
+	items  []*entities.Item
+	copied map[int]bool
+}
+
+func (e *itemEdit) item(i int) *entities.Item {
+	if !e.copied[i] {
+		item := *e.items[i]
+		item.Boards = slices.Clone(item.Boards)
+		item.History = slices.Clone(item.History)
+		e.items[i] = &item
+		e.copied[i] = true
+	}
+	return e.items[i]
+}
+
+// find returns the index of the current version of item, which may come
+// from an older snapshot. It fails once a rescan no longer finds the item.
+func (e *itemEdit) find(item *entities.Item) (int, error) {
+	for i, current := range e.items {
+		if current.ID == item.ID && current.Key == item.Key {
+			return i, nil
+		}
+	}
+	for i, current := range e.items {
+		if current.Key == item.Key {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("item %s:%d not found, rescan and try again", item.File, item.Line)
+}
+
+// update is the one path that changes items. fn runs with the write lock
+// held, so it is also where source files are rewritten, and its edit is
+// published as a new snapshot when fn succeeds.
+func (s *ScannerService) update(fn func(edit *itemEdit) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.snapshot.Load()
+	edit := &itemEdit{items: slices.Clone(current.Items), copied: map[int]bool{}}
+	if err := fn(edit); err != nil {
+		return err
+	}
+
+	if len(edit.copied) > 0 {
+		s.snapshot.Store(&ItemSnapshot{Version: current.Version + 1, Items: edit.items, ScannedAt: current.ScannedAt})
 	}
+	return nil
+}
 
-	settings := s.settings.LoadSettings()
+type itemScanner struct {
+	settings                 *entities.Settings
+	boards                   []entities.Board
+	itemTypes                []string
+	noneStartItemIdentifiers []string
+	itemPattern              *regexp.Regexp
+	descPattern              *regexp.Regexp
+	priorityPattern          *regexp.Regexp
+	noneStartItemPattern     *regexp.Regexp
+}
 
+func newItemScanner(settings *entities.Settings) *itemScanner {
 	itemTypes := []string{}
 	noneStartItemIdentifiers := []string{}
 
-	for _, setting := range settings.KanbanColumns {
-		if pt := setting.AutoAssignPattern; pt != nil {
-			for _, pattern := range strings.Split(*pt, "|") {
-				if pattern != "" {
-					itemTypes = append(itemTypes, pattern)
+	boards := settings.GetBoards()
+	for _, board := range boards {
+		for _, setting := range board.Columns {
+			if pt := setting.AutoAssignPattern; pt != nil {
+				for _, pattern := range strings.Split(*pt, "|") {
+					if pattern != "" && !slices.Contains(itemTypes, pattern) {
+						itemTypes = append(itemTypes, pattern)
+					}
 				}
+			} else if !slices.Contains(noneStartItemIdentifiers, setting.Name) {
+				noneStartItemIdentifiers = append(noneStartItemIdentifiers, setting.Name)
 			}
-		} else {
-			noneStartItemIdentifiers = append(noneStartItemIdentifiers, setting.Name)
 		}
 	}
 
@@ -90,15 +239,185 @@ func (s *ScannerService) ScanTodos() {
 	priorityPatternString := strings.Join(itemPriorities, "|")
 	noneStartItemIdentifiersPattern := strings.Join(noneStartItemIdentifiers, "|")
 
-	itemPattern := regexp.MustCompile(fmt.Sprintf(`^\s*(//|#|--|\<!--)\s*(%s):\s*(.+)?`, typePattern))
+	return &itemScanner{
+		settings:                 settings,
+		boards:                   boards,
+		itemTypes:                itemTypes,
+		noneStartItemIdentifiers: noneStartItemIdentifiers,
+		itemPattern:              regexp.MustCompile(fmt.Sprintf(`^\s*(//|#|--|\<!--)\s*(%s)(?:\(#(\d+)\))?:\s*(.+)?`, typePattern)),
+		descPattern:              regexp.MustCompile(`^\s*(//|#|--|\<!--)\s*(.+)`),
+		priorityPattern:          regexp.MustCompile(fmt.Sprintf(`^\s*(//|#|--|\<!--)\s*(%s)`, priorityPatternString)),
+		noneStartItemPattern:     regexp.MustCompile(fmt.Sprintf(`^\s*(//|#|--|\<!--)\s*(%s)\s+(\d{4}-\d{2}-\d{2}\s+\d{2}:\d{2})\s+by\s+(.+?)(\s*-->)?$`, noneStartItemIdentifiersPattern)),
+	}
+}
+
+func (is *itemScanner) isExcludedDir(name string) bool {
+	return slices.Contains(is.settings.CodeScanSettings.ExcludeDirectories, name)
+}
+
+func (is *itemScanner) isExcludedFile(path string) (bool, error) {
+	for _, excludeFilePattern := range is.settings.CodeScanSettings.ExcludeFiles {
+		slashedPath := filepath.ToSlash(path)
+		slashedExcludeFilePattern := filepath.ToSlash(excludeFilePattern)
+		matched, err := doublestar.PathMatch(slashedExcludeFilePattern, slashedPath)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// scan parses the items of a single file. currentUser returns the user
+// recorded on items without status history and now their creation time.
+func (is *itemScanner) scan(relPath string, r io.Reader, now time.Time, currentUser func() string) []*entities.Item {
+	settings := is.settings
+	items := []*entities.Item{}
+
+	scannerService := bufio.NewScanner(r)
+	lineNum := 0
+	occurrences := map[string]int{}
+	for scannerService.Scan() {
+		lineNum++
+		line := scannerService.Text()
+
+		if matches := is.itemPattern.FindStringSubmatch(line); len(matches) > 0 {
+			itemType := entities.ItemType(matches[2])
+			title := strings.TrimSpace(matches[4])
+			var issueNumber *int
+			if matches[3] != "" {
+				if n, err := strconv.Atoi(matches[3]); err == nil {
+					issueNumber = &n
+				}
+			}
+			todoStartLine := lineNum
 
-	descPattern := regexp.MustCompile(`^\s*(//|#|--|\<!--)\s*(.+)`)
+			itemBoards := []string{}
+			for _, board := range is.boards {
+				if board.Contains(itemType, relPath) {
+					itemBoards = append(itemBoards, board.ID)
+				}
+			}
 
-	priorityPattern := regexp.MustCompile(fmt.Sprintf(`^\s*(//|#|--|\<!--)\s*(%s)`, priorityPatternString))
+			firstColumn := settings.KanbanColumns[0]
+			if len(itemBoards) > 0 {
+				if board, ok := settings.GetBoard(itemBoards[0]); ok {
+					firstColumn = board.Columns[0]
+				}
+			}
 
-	noneStartItemPattern := regexp.MustCompile(fmt.Sprintf(`^\s*(//|#|--|\<!--)\s*(%s)\s+(\d{4}-\d{2}-\d{2}\s+\d{2}:\d{2})\s+by\s+(.+?)(\s*-->)?$`, noneStartItemIdentifiersPattern))
+			var descriptions []string
+			var history []entities.StatusHistory
+			currentStatus := entities.ItemStatus(firstColumn.ID)
+			currentPriority := entities.ItemPriority("LOW")
+
+			user := currentUser()
+
+			for scannerService.Scan() {
+				nextLine := scannerService.Text()
+				lineNum++
+
+				if noneStartMatches := is.noneStartItemPattern.FindStringSubmatch(nextLine); len(noneStartMatches) > 0 {
+					if parsedTime, err := time.Parse("2006-01-02 15:04", noneStartMatches[3]); err == nil {
+						status := entities.ItemStatus(strcase.SnakeCase(strings.TrimSpace(noneStartMatches[2])))
+						history = append(history, entities.StatusHistory{
+							Status:    status,
+							Timestamp: parsedTime,
+							User:      strings.TrimSpace(noneStartMatches[4]),
+						})
+						currentStatus = status
+					}
+				} else if priorityMatches := is.priorityPattern.FindStringSubmatch(nextLine); len(priorityMatches) > 0 {
+					pr := strings.TrimSpace(priorityMatches[2])
+					switch pr {
+					case settings.PriorityPatterns.Low:
+						currentPriority = "LOW"
+					case settings.PriorityPatterns.Medium:
+						currentPriority = "MEDIUM"
+					case settings.PriorityPatterns.High:
+						currentPriority = "HIGH"
+					}
+				} else if descMatches := is.descPattern.FindStringSubmatch(nextLine); len(descMatches) > 0 {
+					desc := strings.TrimSpace(descMatches[2])
+
+					upperDesc := strings.ToUpper(desc)
+					isStatusLine := false
+					isPriorityLine := false
+					for _, name := range is.noneStartItemIdentifiers {
+						if strings.HasPrefix(upperDesc, strings.ToUpper(name)) {
+							isStatusLine = true
+							break
+						}
+					}
 
-	firstColumn := settings.KanbanColumns[0]
+					if desc == settings.PriorityPatterns.Low && desc == settings.PriorityPatterns.Medium && desc == settings.PriorityPatterns.High {
+						isStatusLine = true
+						break
+					}
+
+					if !isPriorityLine && !isStatusLine {
+						descriptions = append(descriptions, desc)
+					}
+				} else {
+					break
+				}
+			}
+
+			occurrenceKey := strings.ToUpper(string(itemType)) + "\x00" + strings.Join(strings.Fields(title), " ")
+			occurrence := occurrences[occurrenceKey]
+			occurrences[occurrenceKey]++
+
+			if len(itemBoards) == 0 {
+				continue
+			}
+
+			item := &entities.Item{
+				Key:         entities.ItemKey(relPath, itemType, title, occurrence),
+				Type:        itemType,
+				Title:       title,
+				Description: strings.Join(descriptions, "\n"),
+				File:        relPath,
+				Line:        todoStartLine,
+				Status:      currentStatus,
+				Priority:    currentPriority,
+				IssueNumber: issueNumber,
+				Boards:      itemBoards,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+				CurrentUser: user,
+				History:     history,
+			}
+
+			if len(history) == 0 {
+				item.History = []entities.StatusHistory{{
+					Status:    entities.ItemStatus(firstColumn.ID),
+					Timestamp: item.CreatedAt,
+					User:      user,
+				}}
+			}
+
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// ScanTodos scans the project and publishes the items as a new snapshot.
+// ScanTodos scans the project and publishes the items as a new snapshot.
+// A failed scan keeps the previous snapshot.
+func (s *ScannerService) ScanTodos() error {
+	scannedAt := time.Now()
+	items := []*entities.Item{}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %v", err)
+	}
+
+	scanner := newItemScanner(s.settings.currentSettings())
 
 	err = filepath.Walk(wd, func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
@@ -106,23 +425,14 @@ func (s *ScannerService) ScanTodos() {
 		}
 
 		if info.IsDir() {
-			name := info.Name()
-			if slices.Contains(settings.CodeScanSettings.ExcludeDirectories, name) {
+			if scanner.isExcludedDir(info.Name()) {
 				return filepath.SkipDir
 			}
 			return nil
-		} else {
-			for _, excludeFilePattern := range settings.CodeScanSettings.ExcludeFiles {
-				slashedPath := filepath.ToSlash(path)
-				slashedExcludeFilePattern := filepath.ToSlash(excludeFilePattern)
-				matched, err := doublestar.PathMatch(slashedExcludeFilePattern, slashedPath)
-				if err != nil {
-					return err
-				}
-				if matched {
-					return nil
-				}
-			}
+		} else if excluded, err := scanner.isExcludedFile(path); err != nil {
+			return err
+		} else if excluded {
+			return nil
 		}
 
 		file, err := os.Open(path)
@@ -133,148 +443,82 @@ func (s *ScannerService) ScanTodos() {
 			_ = file.Close()
 		}()
 
-		scannerService := bufio.NewScanner(file)
-		lineNum := 0
-		for scannerService.Scan() {
-			lineNum++
-			line := scannerService.Text()
-
-			if matches := itemPattern.FindStringSubmatch(line); len(matches) > 0 {
-				s.todoID++
-				itemType := entities.ItemType(matches[2])
-				title := strings.TrimSpace(matches[3])
-				todoStartLine := lineNum
-
-				var descriptions []string
-				var history []entities.StatusHistory
-				currentStatus := entities.ItemStatus(firstColumn.ID)
-				currentPriority := entities.ItemPriority("LOW")
-
-				currentUser := s.getCurrentUser()
-
-				for scannerService.Scan() {
-					nextLine := scannerService.Text()
-					lineNum++
-
-					if noneStartMatches := noneStartItemPattern.FindStringSubmatch(nextLine); len(noneStartMatches) > 0 {
-						if parsedTime, err := time.Parse("2006-01-02 15:04", noneStartMatches[3]); err == nil {
-							status := entities.ItemStatus(strcase.SnakeCase(strings.TrimSpace(noneStartMatches[2])))
-							history = append(history, entities.StatusHistory{
-								Status:    status,
-								Timestamp: parsedTime,
-								User:      strings.TrimSpace(noneStartMatches[4]),
-							})
-							currentStatus = status
-						}
-					} else if priorityMatches := priorityPattern.FindStringSubmatch(nextLine); len(priorityMatches) > 0 {
-						pr := strings.TrimSpace(priorityMatches[2])
-						switch pr {
-						case settings.PriorityPatterns.Low:
-							currentPriority = "LOW"
-						case settings.PriorityPatterns.Medium:
-							currentPriority = "MEDIUM"
-						case settings.PriorityPatterns.High:
-							currentPriority = "HIGH"
-						}
-					} else if descMatches := descPattern.FindStringSubmatch(nextLine); len(descMatches) > 0 {
-						desc := strings.TrimSpace(descMatches[2])
-
-						upperDesc := strings.ToUpper(desc)
-						isStatusLine := false
-						isPriorityLine := false
-						for _, kanbanCol := range settings.KanbanColumns {
-							if strings.HasPrefix(upperDesc, strings.ToUpper(kanbanCol.Name)) {
-								isStatusLine = true
-								break
-							}
-						}
-
-						if desc == settings.PriorityPatterns.Low && desc == settings.PriorityPatterns.Medium && desc == settings.PriorityPatterns.High {
-							isStatusLine = true
-							break
-						}
+		relPath, _ := filepath.Rel(wd, path)
 
-						if !isPriorityLine && !isStatusLine {
-							descriptions = append(descriptions, desc)
-						}
-					} else {
-						break
-					}
-				}
-
-				relPath, _ := filepath.Rel(wd, path)
-
-				item := &entities.Item{
-					ID:          s.todoID,
-					Type:        itemType,
-					Title:       title,
-					Description: strings.Join(descriptions, "\n"),
-					File:        relPath,
-					Line:        todoStartLine,
-					Status:      currentStatus,
-					Priority:    currentPriority,
-					CreatedAt:   time.Now(),
-					UpdatedAt:   time.Now(),
-					CurrentUser: currentUser,
-					History:     history,
-				}
-
-				if len(history) == 0 {
-					item.History = []entities.StatusHistory{{
-						Status:    entities.ItemStatus(firstColumn.ID),
-						Timestamp: item.CreatedAt,
-						User:      currentUser,
-					}}
-				}
-
-				s.Items = append(s.Items, item)
-			}
+		for _, item := range scanner.scan(relPath, file, time.Now(), s.getCurrentUser) {
+			item.ID = len(items) + 1
+			items = append(items, item)
 		}
 
 		return nil
 	})
 
 	if err != nil {
-		fmt.Printf("Error walking directory: %v\n", err)
+		return fmt.Errorf("failed to walk %s: %v", wd, err)
 	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.snapshot.Load()
+	s.snapshot.Store(&ItemSnapshot{Version: current.Version + 1, Items: items, ScannedAt: scannedAt})
+	return nil
 }
 
-func (s *ScannerService) UpdateItemStatus(item *entities.Item, targetColumnID string) error {
+func (s *ScannerService) UpdateItemStatus(item *entities.Item, boardID, targetColumnID string) error {
 	currentUser := s.getCurrentUser()
-	settings := s.settings.LoadSettings()
+	settings, err := s.settings.LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	board, ok := settings.GetBoard(boardID)
+	if !ok {
+		return fmt.Errorf("board with ID '%s' not found", boardID)
+	}
+
+	targetColumn := board.GetColumn(targetColumnID)
+	if targetColumn == nil {
+		return fmt.Errorf("kanban column with ID '%s' not found", targetColumnID)
+	}
 
-	var targetColumn *entities.KanbanColumn
 	assignablePatterns := make(map[string]interface{})
 	statusColumns := make(map[string]interface{})
 
-	for _, col := range settings.KanbanColumns {
-		if col.ID == targetColumnID {
-			targetColumn = &col
-		}
-		if col.AutoAssignPattern != nil {
-			for _, p := range strings.Split(*col.AutoAssignPattern, "|") {
-				assignablePatterns[strings.TrimSpace(p)] = struct{}{}
+	for _, b := range settings.GetBoards() {
+		for _, col := range b.Columns {
+			if col.AutoAssignPattern != nil {
+				for _, p := range strings.Split(*col.AutoAssignPattern, "|") {
+					assignablePatterns[strings.TrimSpace(p)] = struct{}{}
+				}
+			} else {
+				statusColumns[col.Name] = struct{}{}
 			}
-		} else {
-			statusColumns[col.Name] = struct{}{}
 		}
 	}
 
-	if targetColumn == nil {
-		return fmt.Errorf("kanban column with ID '%s' not found", targetColumnID)
-	}
-
-	item.Status = entities.ItemStatus(targetColumn.Name)
+	status := entities.ItemStatus(targetColumn.Name)
 
 	var newComment string
 	if targetColumn.AutoAssignPattern == nil {
 		timestamp := time.Now().Format("2006-01-02 15:04")
-		newComment = fmt.Sprintf("// %s %s by %s", item.Status, timestamp, currentUser)
+		newComment = fmt.Sprintf("// %s %s by %s", status, timestamp, currentUser)
 	} else {
 		newComment = ""
 	}
 
-	return s.updateStatusCommentDynamic(item, newComment, assignablePatterns, statusColumns)
+	return s.update(func(edit *itemEdit) error {
+		i, err := edit.find(item)
+		if err != nil {
+			return err
+		}
+		if err := s.updateStatusCommentDynamic(edit.items[i], newComment, assignablePatterns, statusColumns); err != nil {
+			return err
+		}
+
+		edit.item(i).Status = status
+		return nil
+	})
 }
 
 func (s *ScannerService) updateStatusCommentDynamic(item *entities.Item, newComment string, assignablePatterns, statusColumns map[string]interface{}) error {
@@ -289,8 +533,8 @@ func (s *ScannerService) updateStatusCommentDynamic(item *entities.Item, newComm
 		return fmt.Errorf("failed to read file %s: %v", fullPath, err)
 	}
 
-	if item.Line < 1 || item.Line > len(lines) {
-		return fmt.Errorf("invalid line number %d (file has %d lines)", item.Line, len(lines))
+	if err := checkItemLine(lines, item); err != nil {
+		return err
 	}
 
 	prefix := s.getCommentPrefix(item.File)
@@ -336,6 +580,282 @@ func (s *ScannerService) updateStatusCommentDynamic(item *entities.Item, newComm
 	return s.writeFileLines(fullPath, newLines)
 }
 
+func (s *ScannerService) resolveProjectFile(file string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %v", err)
+	}
+
+	fullPath := filepath.Join(wd, filepath.FromSlash(file))
+	rel, err := filepath.Rel(wd, fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("file %s is outside the project", file)
+	}
+
+	return fullPath, nil
+}
+
+func (s *ScannerService) formatComment(prefix, text string) string {
+	if prefix == "<!--" {
+		return fmt.Sprintf("<!-- %s -->", text)
+	}
+	return fmt.Sprintf("%s %s", prefix, text)
+}
+
+// commentText flattens text to a single line that cannot close the comment
+// it is written into.
+func commentText(text string) string {
+	return commentTerminators.Replace(strings.Join(strings.Fields(text), " "))
+}
+
+func (s *ScannerService) InsertItemComment(file string, line int, itemType entities.ItemType, title string, description []string) error {
+	settings, err := s.settings.LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	scanner := newItemScanner(settings)
+	if !slices.Contains(scanner.itemTypes, string(itemType)) {
+		return fmt.Errorf("%w: unknown item type %q, expected one of %s", ErrInvalidItemComment, itemType, strings.Join(scanner.itemTypes, ", "))
+	}
+
+	title = commentText(title)
+	if title == "" {
+		return fmt.Errorf("%w: empty title", ErrInvalidItemComment)
+	}
+
+	fullPath, err := s.resolveProjectFile(file)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines, err := s.readFileLines(fullPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %v", fullPath, err)
+	}
+
+	if line < 1 || line > len(lines)+1 {
+		return fmt.Errorf("invalid line number %d (file has %d lines)", line, len(lines))
+	}
+
+	indent := ""
+	if line <= len(lines) {
+		target := lines[line-1]
+		indent = target[:len(target)-len(strings.TrimLeft(target, " \t"))]
+	}
+
+	prefix := s.getCommentPrefix(file)
+	block := []string{indent + s.formatComment(prefix, fmt.Sprintf("%s: %s", itemType, title))}
+	for _, desc := range description {
+		if desc = commentText(desc); desc != "" {
+			block = append(block, indent+s.formatComment(prefix, desc))
+		}
+	}
+
+	newLines := append([]string{}, lines[:line-1]...)
+	newLines = append(newLines, block...)
+	newLines = append(newLines, lines[line-1:]...)
+
+	return s.writeFileLines(fullPath, newLines)
+}
+
+// checkItemLine fails unless the item's line still holds its comment, so
+// that a file changed since the scan is not edited in the wrong place.
+func checkItemLine(lines []string, item *entities.Item) error {
+	if item.Line < 1 || item.Line > len(lines) {
+		return fmt.Errorf("invalid line number %d (file has %d lines)", item.Line, len(lines))
+	}
+
+	pattern := regexp.MustCompile(fmt.Sprintf(`(%s)(?:\(#\d+\))?:\s*(.*)`, regexp.QuoteMeta(string(item.Type))))
+	matches := pattern.FindStringSubmatch(lines[item.Line-1])
+	if matches == nil || strings.Join(strings.Fields(matches[2]), " ") != strings.Join(strings.Fields(item.Title), " ") {
+		return fmt.Errorf("%s comment %q not found at %s:%d, rescan and try again", item.Type, item.Title, item.File, item.Line)
+	}
+	return nil
+}
+
+func (s *ScannerService) RemoveItemComment(item *entities.Item) error {
+	fullPath, err := s.resolveProjectFile(item.File)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines, err := s.readFileLines(fullPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %v", fullPath, err)
+	}
+
+	if err := checkItemLine(lines, item); err != nil {
+		return err
+	}
+
+	prefix := s.getCommentPrefix(item.File)
+	todoIndex := item.Line - 1
+	endIndex := todoIndex
+	for i := todoIndex + 1; i < len(lines); i++ {
+		if !s.isCommentLine(lines[i], prefix) {
+			break
+		}
+		endIndex = i
+	}
+
+	newLines := append([]string{}, lines[:todoIndex]...)
+	newLines = append(newLines, lines[endIndex+1:]...)
+
+	return s.writeFileLines(fullPath, newLines)
+}
+
+func (s *ScannerService) SetItemIssueReference(item *entities.Item, issueNumber int) error {
+	return s.update(func(edit *itemEdit) error {
+		i, err := edit.find(item)
+		if err != nil {
+			return err
+		}
+		item := edit.items[i]
+
+		fullPath, err := s.resolveProjectFile(item.File)
+		if err != nil {
+			return err
+		}
+
+		lines, err := s.readFileLines(fullPath)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %v", fullPath, err)
+		}
+
+		if err := checkItemLine(lines, item); err != nil {
+			return err
+		}
+
+		referencePattern := regexp.MustCompile(fmt.Sprintf(`(%s)(\(#\d+\))?:`, regexp.QuoteMeta(string(item.Type))))
+		line := lines[item.Line-1]
+		loc := referencePattern.FindStringIndex(line)
+		if loc == nil {
+			return fmt.Errorf("%s comment not found at %s:%d", item.Type, item.File, item.Line)
+		}
+
+		lines[item.Line-1] = line[:loc[0]] + fmt.Sprintf("%s(#%d):", item.Type, issueNumber) + line[loc[1]:]
+		if err := s.writeFileLines(fullPath, lines); err != nil {
+			return err
+		}
+
+		edit.item(i).IssueNumber = &issueNumber
+		return nil
+	})
+}
+
+// SetItemPriority rewrites the priority line below the item's comment and
+// shifts the lines of the items after it in the same file.
+func (s *ScannerService) SetItemPriority(item *entities.Item, priority entities.ItemPriority) error {
+	settings, err := s.settings.LoadSettings()
+	if err != nil {
+		return err
+	}
+	patterns := map[entities.ItemPriority]string{
+		"LOW":    settings.PriorityPatterns.Low,
+		"MEDIUM": settings.PriorityPatterns.Medium,
+		"HIGH":   settings.PriorityPatterns.High,
+	}
+	pattern, ok := patterns[priority]
+	if !ok || pattern == "" {
+		return fmt.Errorf("unknown priority %q", priority)
+	}
+
+	return s.update(func(edit *itemEdit) error {
+		i, err := edit.find(item)
+		if err != nil {
+			return err
+		}
+		item := edit.items[i]
+
+		fullPath, err := s.resolveProjectFile(item.File)
+		if err != nil {
+			return err
+		}
+
+		lines, err := s.readFileLines(fullPath)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %v", fullPath, err)
+		}
+
+		if err := checkItemLine(lines, item); err != nil {
+			return err
+		}
+
+		prefix := s.getCommentPrefix(item.File)
+		todoLine := lines[item.Line-1]
+		indent := todoLine[:len(todoLine)-len(strings.TrimLeft(todoLine, " \t"))]
+
+		newLines := append([]string{}, lines[:item.Line]...)
+		newLines = append(newLines, indent+s.formatComment(prefix, pattern))
+
+		l := item.Line
+		for ; l < len(lines) && s.isCommentLine(lines[l], prefix); l++ {
+			text := strings.TrimSpace(lines[l])
+			text = strings.TrimSuffix(strings.TrimPrefix(text, prefix), "-->")
+			text = strings.TrimSpace(text)
+			if text == settings.PriorityPatterns.Low || text == settings.PriorityPatterns.Medium || text == settings.PriorityPatterns.High {
+				continue
+			}
+			newLines = append(newLines, lines[l])
+		}
+		newLines = append(newLines, lines[l:]...)
+
+		if err := s.writeFileLines(fullPath, newLines); err != nil {
+			return err
+		}
+
+		if delta := len(newLines) - len(lines); delta != 0 {
+			for j, other := range edit.items {
+				if j != i && other.File == item.File && other.Line > item.Line {
+					edit.item(j).Line += delta
+				}
+			}
+		}
+
+		edit.item(i).Priority = priority
+		return nil
+	})
+}
+
+// SetStale flags the items for which stale reports true and clears the
+// flag of the others.
+func (s *ScannerService) SetStale(stale func(key string) bool) error {
+	return s.update(func(edit *itemEdit) error {
+		for i, item := range edit.items {
+			if isStale := stale(item.Key); isStale != item.Stale {
+				edit.item(i).Stale = isStale
+			}
+		}
+		return nil
+	})
+}
+
+func (s *ScannerService) GetItemByIssue(issueNumber int) *entities.Item {
+	for _, item := range s.GetItems() {
+		if item.IssueNumber != nil && *item.IssueNumber == issueNumber {
+			return item
+		}
+	}
+	return nil
+}
+
+func (s *ScannerService) FindItemAt(file string, line int) *entities.Item {
+	file = filepath.ToSlash(filepath.Clean(file))
+	for _, item := range s.GetItems() {
+		if filepath.ToSlash(item.File) == file && item.Line == line {
+			return item
+		}
+	}
+	return nil
+}
+
 func (s *ScannerService) getCurrentUser() string {
 	if user, err := getGitUserName(); err == nil && user != "" {
 		return user
@@ -428,7 +948,7 @@ func getGitUserName() (string, error) {
 
 func (s *ScannerService) GetItemsByType(itemType entities.ItemType) []*entities.Item {
 	var filtered []*entities.Item
-	for _, item := range s.Items {
+	for _, item := range s.GetItems() {
 		if item.Type == itemType {
 			filtered = append(filtered, item)
 		}
@@ -438,7 +958,7 @@ func (s *ScannerService) GetItemsByType(itemType entities.ItemType) []*entities.
 
 func (s *ScannerService) GetItemsByStatus(status entities.ItemStatus) []*entities.Item {
 	var filtered []*entities.Item
-	for _, item := range s.Items {
+	for _, item := range s.GetItems() {
 		if item.Status == status {
 			filtered = append(filtered, item)
 		}
@@ -448,7 +968,7 @@ func (s *ScannerService) GetItemsByStatus(status entities.ItemStatus) []*entitie
 
 func (s *ScannerService) GetItemsByPriority(priority entities.ItemPriority) []*entities.Item {
 	var filtered []*entities.Item
-	for _, item := range s.Items {
+	for _, item := range s.GetItems() {
 		if item.Priority == priority {
 			filtered = append(filtered, item)
 		}
@@ -456,9 +976,26 @@ func (s *ScannerService) GetItemsByPriority(priority entities.ItemPriority) []*e
 	return filtered
 }
 
+func (s *ScannerService) GetBoardItems(board *entities.Board) []*entities.Item {
+	items := []*entities.Item{}
+	for _, item := range s.GetItems() {
+		if !item.InBoard(board.ID) {
+			continue
+		}
+		boardItem := *item
+		boardItem.Status = board.StatusFor(item.Status)
+		items = append(items, &boardItem)
+	}
+	return items
+}
+
+func (s *ScannerService) QueryItems(query *ItemQuery) []*entities.Item {
+	return query.Filter(s.GetItems())
+}
+
 func (s *ScannerService) GetItemsByCategory() map[string][]*entities.Item {
 	categories := make(map[string][]*entities.Item)
-	for _, item := range s.Items {
+	for _, item := range s.GetItems() {
 		category := string(item.Type)
 		categories[category] = append(categories[category], item)
 	}
@@ -488,16 +1025,26 @@ func (s *ScannerService) UpdateOldStatuses(oldSettings, newSettings *entities.Se
 		return nil
 	}
 
-	for _, item := range s.Items {
-		if newName, ok := renamed[string(item.Status)]; ok {
+	err := s.update(func(edit *itemEdit) error {
+		for i, current := range edit.items {
+			newName, ok := renamed[string(current.Status)]
+			if !ok {
+				continue
+			}
+
+			item := edit.item(i)
 			item.Status = entities.ItemStatus(newName)
 
-			for i, h := range item.History {
+			for j, h := range item.History {
 				if string(h.Status) == renamed[string(item.Status)] {
-					item.History[i].Status = item.Status
+					item.History[j].Status = item.Status
 				}
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	return s.historyService.SaveStats(s.GetItems(), s.settings)
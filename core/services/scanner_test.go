@@ -0,0 +1,287 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
+	"go.uber.org/zap"
+)
+
+func testSettings() *entities.Settings {
+	return (&SettingsService{}).GetDefaultSettings()
+}
+
+func scanSource(t *testing.T, file, source string) []*entities.Item {
+	t.Helper()
+	scanner := newItemScanner(testSettings())
+	return scanner.scan(file, strings.NewReader(source), time.Now(), func() string { return "tester" })
+}
+
+func TestScanDuplicateItemKeys(t *testing.T) {
+	items := scanSource(t, "a.go", `package a
+
+// TODO: fix this
+func one() {}
+
+// TODO: fix this
+func two() {}
+
+// FIXME: fix this
+func three() {}
+`)
+	if len(items) != 3 {
+		t.Fatalf("got %d items, want 3", len(items))
+	}
+
+	if items[0].Key != entities.ItemKey("a.go", "TODO", "fix this", 0) {
+		t.Errorf("first TODO does not keep the key of a unique item")
+	}
+	if items[1].Key != entities.ItemKey("a.go", "TODO", "fix this", 1) {
+		t.Errorf("second TODO has key %s", items[1].Key)
+	}
+	if items[0].Key == items[1].Key {
+		t.Errorf("identical comments share the key %s", items[0].Key)
+	}
+	if items[2].Key != entities.ItemKey("a.go", "FIXME", "fix this", 0) {
+		t.Errorf("FIXME counts the TODOs as earlier occurrences")
+	}
+}
+
+// newTestProject makes a temporary project directory the working directory
+// and writes files into it.
+func newTestProject(t *testing.T, files map[string]string) *entities.Config {
+	t.Helper()
+	dir := t.TempDir()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+
+	for name, content := range files {
+		writeTestFile(t, name, content)
+	}
+
+	config := entities.NewDefaultConfig()
+	config.Flags.Config = ".kodo"
+	return config
+}
+
+func writeTestFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func newTestScanner(t *testing.T, config *entities.Config) *ScannerService {
+	t.Helper()
+	logger := zap.NewNop()
+	settings := NewSettingsService(config, logger)
+	store, err := storage.Open(config.Flags.Config, storage.BackendJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+	return NewScannerService(config, settings, NewHistoryService(config, logger, store), logger)
+}
+
+func TestRemoveItemCommentChecksLine(t *testing.T) {
+	config := newTestProject(t, map[string]string{
+		"a.go": "package a\n\n// TODO: remove me\n// details\nfunc a() {}\n",
+	})
+	scanner := newTestScanner(t, config)
+	if err := scanner.ScanTodos(); err != nil {
+		t.Fatal(err)
+	}
+
+	item := scanner.GetItems()[0]
+
+	changed := "package a\n\nimport \"fmt\"\n// TODO: remove me\n// details\nfunc a() {}\n"
+	writeTestFile(t, "a.go", changed)
+	if err := scanner.RemoveItemComment(item); err == nil {
+		t.Fatal("removed a comment from a line that changed since the scan")
+	}
+	if got := readTestFile(t, "a.go"); got != changed {
+		t.Fatalf("file was edited:\n%s", got)
+	}
+
+	if err := scanner.ScanTodos(); err != nil {
+		t.Fatal(err)
+	}
+	if err := scanner.RemoveItemComment(scanner.GetItems()[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readTestFile(t, "a.go"), "package a\n\nimport \"fmt\"\nfunc a() {}\n"; got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestInsertItemComment(t *testing.T) {
+	config := newTestProject(t, map[string]string{
+		"a.go":       "package a\n\nfunc a() {}\n",
+		"index.html": "<body>\n</body>\n",
+	})
+	scanner := newTestScanner(t, config)
+
+	err := scanner.InsertItemComment("a.go", 3, "HACK", "title", nil)
+	if !errors.Is(err, ErrInvalidItemComment) {
+		t.Errorf("unknown item type: got %v", err)
+	}
+	err = scanner.InsertItemComment("a.go", 3, "TODO", " \n ", nil)
+	if !errors.Is(err, ErrInvalidItemComment) {
+		t.Errorf("empty title: got %v", err)
+	}
+
+	if err := scanner.InsertItemComment("a.go", 3, "TODO", "close */ here\nand there", []string{"end */ of", ""}); err != nil {
+		t.Fatal(err)
+	}
+	want := "package a\n\n// TODO: close * / here and there\n// end * / of\nfunc a() {}\n"
+	if got := readTestFile(t, "a.go"); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if err := scanner.InsertItemComment("index.html", 2, "FIXME", "a --> b", nil); err != nil {
+		t.Fatal(err)
+	}
+	want = "<body>\n<!-- FIXME: a -- > b -->\n</body>\n"
+	if got := readTestFile(t, "index.html"); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestConcurrentRescansAndEdits(t *testing.T) {
+	const files = 8
+	sources := map[string]string{}
+	for i := 0; i < files; i++ {
+		sources[fmt.Sprintf("f%d.go", i)] = fmt.Sprintf("package f\n\n// TODO: task %d\nfunc f() {}\n", i)
+	}
+	config := newTestProject(t, sources)
+	scanner := newTestScanner(t, config)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	var background sync.WaitGroup
+	background.Add(2)
+	go func() {
+		defer background.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if err := scanner.Rescan(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer background.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			for _, item := range scanner.GetItems() {
+				if item.Status == "" {
+					t.Errorf("%s has no status", item.File)
+				}
+			}
+		}
+	}()
+
+	var edits sync.WaitGroup
+	for _, item := range scanner.GetItems() {
+		edits.Add(1)
+		go func() {
+			defer edits.Done()
+			if err := scanner.SetItemPriority(item, "HIGH"); err != nil {
+				t.Error(err)
+			}
+			if err := scanner.UpdateItemStatus(item, entities.DefaultBoardID, "in_progress"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	edits.Wait()
+	close(done)
+	background.Wait()
+
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+	items := scanner.GetItems()
+	if len(items) != files {
+		t.Fatalf("got %d items, want %d", len(items), files)
+	}
+	for _, item := range items {
+		if item.Priority != "HIGH" || item.Status != "in_progress" {
+			t.Errorf("%s: got priority %s and status %s", item.File, item.Priority, item.Status)
+		}
+	}
+}
+
+func TestFailedScanKeepsSnapshot(t *testing.T) {
+	config := newTestProject(t, map[string]string{
+		"a.go": "package a\n\n// TODO: keep me\nfunc a() {}\n",
+	})
+	scanner := newTestScanner(t, config)
+	if err := scanner.Rescan(); err != nil {
+		t.Fatal(err)
+	}
+	before := scanner.Snapshot()
+
+	// A working directory that was removed cannot be scanned.
+	if err := os.Mkdir("gone", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("gone"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove("../gone"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Getwd(); err == nil {
+		t.Skip("the working directory can still be read after removing it")
+	}
+
+	if err := scanner.Rescan(); err == nil {
+		t.Fatal("rescan of a removed directory succeeded")
+	}
+	if after := scanner.Snapshot(); after != before || len(after.Items) != 1 {
+		t.Errorf("snapshot = version %d with %d items, want version %d kept", after.Version, len(after.Items), before.Version)
+	}
+}
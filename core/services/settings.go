@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
 	"go.uber.org/zap"
 )
 
@@ -26,7 +28,7 @@ func NewSettingsService(config *entities.Config, logger *zap.Logger) *SettingsSe
 		config:       config,
 		logger:       logger,
 		projectDir:   projectDir,
-		settingsFile: filepath.Join(projectDir, config.Flags.Config, "settings.json"),
+		settingsFile: filepath.Join(projectDir, config.Flags.Config, storage.SettingsFile),
 	}
 }
 
@@ -42,20 +44,19 @@ func (sm *SettingsService) Initialize() error {
 		}
 	}
 
-	_, err = os.ReadFile(sm.settingsFile)
-	if err != nil {
+	return storage.WithFileLock(sm.settingsFile, func() error {
+		_, err := os.Stat(sm.settingsFile)
 		if os.IsNotExist(err) {
-			return sm.SaveSettings(sm.GetDefaultSettings())
+			return sm.saveSettings(sm.GetDefaultSettings())
 		}
 		return err
-	}
-
-	return nil
+	})
 }
 
 func (sm *SettingsService) GetDefaultSettings() *entities.Settings {
 	defaultAutoAssignPattern := "TODO|FIXME"
 	return &entities.Settings{
+		SchemaVersion: storage.SettingsSchema,
 		KanbanColumns: []entities.KanbanColumn{
 			{ID: "todo", Name: "TODO", Color: "dark", AutoAssignPattern: &defaultAutoAssignPattern},
 			{ID: "in_progress", Name: "IN PROGRESS", Color: "blue"},
@@ -66,6 +67,18 @@ func (sm *SettingsService) GetDefaultSettings() *entities.Settings {
 			Medium: "MEDIUM",
 			High:   "HIGH",
 		},
+		HistoryRetention: entities.HistoryRetention{
+			FullDays:   30,
+			DailyDays:  180,
+			WeeklyDays: 0,
+		},
+		DebtModel: entities.DebtModel{
+			TypeWeights:     map[string]float64{"TODO": 1, "FIXME": 2},
+			PriorityWeights: map[string]float64{"LOW": 1, "MEDIUM": 1.5, "HIGH": 2},
+			AgePerMonth:     0.1,
+			AlertThreshold:  10,
+			AlertWindowDays: 30,
+		},
 		CodeScanSettings: entities.CodeScanConfig{
 			ExcludeDirectories: []string{
 				"node_modules",
@@ -116,35 +129,71 @@ func (sm *SettingsService) GetDefaultSettings() *entities.Settings {
 		GithubAuth: entities.GithubAuth{
 			Token: "",
 		},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Boards:          []entities.Board{},
+		SavedQueries:    []entities.SavedQuery{},
+		AutomationRules: []entities.AutomationRule{},
+		Storage:         storage.BackendJSON,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 }
 
-func (sm *SettingsService) LoadSettings() *entities.Settings {
+// LoadSettings reads the settings file. Only a missing file falls back to
+// the defaults; a file that cannot be read or decoded, or that has a newer
+// schema, is an error so that it is never overwritten with defaults.
+func (sm *SettingsService) LoadSettings() (*entities.Settings, error) {
 	data, err := os.ReadFile(sm.settingsFile)
 	if err != nil {
 		if os.IsNotExist(err) {
 			sm.logger.Info("Settings file not found, using defaults")
-			return sm.GetDefaultSettings()
+			return sm.GetDefaultSettings(), nil
 		}
-		sm.logger.Error("Failed to read settings file", zap.Error(err))
-		return sm.GetDefaultSettings()
+		return nil, fmt.Errorf("failed to read settings file: %v", err)
 	}
 
 	var settings entities.Settings
-	if err := json.Unmarshal(data, &settings); err != nil {
-		sm.logger.Error("Failed to unmarshal settings", zap.Error(err))
-		return sm.GetDefaultSettings()
+	if _, err := storage.Decode(storage.SettingsFile, data, &settings); err != nil {
+		return nil, err
 	}
 
-	settings = *sm.validateSettings(&settings)
+	return sm.validateSettings(&settings), nil
+}
 
-	return &settings
+// currentSettings is LoadSettings for readers that cannot fail. They get
+// the defaults when the file cannot be loaded; nothing may save them.
+func (sm *SettingsService) currentSettings() *entities.Settings {
+	settings, err := sm.LoadSettings()
+	if err != nil {
+		sm.logger.Error("Failed to load settings, using defaults", zap.Error(err))
+		return sm.GetDefaultSettings()
+	}
+	return settings
+}
+
+// UpdateSettings loads the settings, lets fn change them and saves the
+// result, all under the lock of the settings file. Nothing is saved when
+// the settings cannot be loaded or fn fails.
+func (sm *SettingsService) UpdateSettings(fn func(settings *entities.Settings) error) (*entities.Settings, error) {
+	var settings *entities.Settings
+	err := storage.WithFileLock(sm.settingsFile, func() (err error) {
+		if settings, err = sm.LoadSettings(); err != nil {
+			return err
+		}
+		if err := fn(settings); err != nil {
+			return err
+		}
+		return sm.saveSettings(settings)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
 }
 
-func (sm *SettingsService) SaveSettings(settings *entities.Settings) error {
+// saveSettings writes the settings file; the caller holds its lock.
+func (sm *SettingsService) saveSettings(settings *entities.Settings) error {
 	settings = sm.validateSettings(settings)
+	settings.SchemaVersion = storage.SettingsSchema
 	settings.UpdatedAt = time.Now()
 
 	if settings.CreatedAt.IsZero() {
@@ -156,7 +205,7 @@ func (sm *SettingsService) SaveSettings(settings *entities.Settings) error {
 		return fmt.Errorf("failed to marshal settings: %v", err)
 	}
 
-	if err := os.WriteFile(sm.settingsFile, data, 0644); err != nil {
+	if err := storage.WriteFile(sm.settingsFile, data, 0644); err != nil {
 		return fmt.Errorf("failed to write settings file: %v", err)
 	}
 
@@ -182,35 +231,275 @@ func (sm *SettingsService) validateSettings(settings *entities.Settings) *entiti
 		settings.CodeScanSettings.ExcludeFiles = sm.GetDefaultSettings().CodeScanSettings.ExcludeFiles
 	}
 
+	if settings.SavedQueries == nil {
+		settings.SavedQueries = []entities.SavedQuery{}
+	}
+
+	if settings.Boards == nil {
+		settings.Boards = []entities.Board{}
+	}
+
+	// Updates reject boards without columns, but a hand edited or merged
+	// file may still have them and every board view needs a last column.
+	boards := []entities.Board{}
+	for _, board := range settings.Boards {
+		if len(board.Columns) == 0 {
+			sm.logger.Warn("Ignoring board without columns", zap.String("board", board.ID))
+			continue
+		}
+		boards = append(boards, board)
+	}
+	settings.Boards = boards
+
+	if settings.AutomationRules == nil {
+		settings.AutomationRules = []entities.AutomationRule{}
+	}
+
+	if settings.HistoryRetention.FullDays <= 0 {
+		settings.HistoryRetention = sm.GetDefaultSettings().HistoryRetention
+	}
+
+	if settings.DebtModel.TypeWeights == nil && settings.DebtModel.PriorityWeights == nil {
+		settings.DebtModel = sm.GetDefaultSettings().DebtModel
+	}
+
+	if settings.DebtModel.AlertWindowDays <= 0 {
+		settings.DebtModel.AlertWindowDays = sm.GetDefaultSettings().DebtModel.AlertWindowDays
+	}
+
+	if settings.Storage == "" {
+		settings.Storage = storage.BackendJSON
+	}
+
 	return settings
 }
 
+func (sm *SettingsService) GetBoards() []entities.Board {
+	return sm.currentSettings().GetBoards()
+}
+
+func (sm *SettingsService) GetBoard(id string) (*entities.Board, bool) {
+	return sm.currentSettings().GetBoard(id)
+}
+
+func parseKanbanColumns(columnsData []interface{}) []entities.KanbanColumn {
+	var columns []entities.KanbanColumn
+	for _, col := range columnsData {
+		if colMap, ok := col.(map[string]interface{}); ok {
+			column := entities.KanbanColumn{}
+			if id, ok := colMap["id"].(string); ok {
+				column.ID = id
+			}
+			if name, ok := colMap["name"].(string); ok {
+				column.Name = name
+			}
+			if color, ok := colMap["color"].(string); ok {
+				column.Color = color
+			}
+			if pattern, ok := colMap["auto_assign_pattern"].(string); ok {
+				column.AutoAssignPattern = &pattern
+			}
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+func parseBoards(boardsData []interface{}) ([]entities.Board, error) {
+	boards := []entities.Board{}
+	seen := map[string]bool{entities.DefaultBoardID: true}
+
+	for _, b := range boardsData {
+		bMap, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		board := entities.Board{}
+		if id, ok := bMap["id"].(string); ok {
+			board.ID = id
+		}
+		if name, ok := bMap["name"].(string); ok {
+			board.Name = name
+		}
+		if columns, ok := bMap["columns"].([]interface{}); ok {
+			board.Columns = parseKanbanColumns(columns)
+		}
+		if filters, ok := bMap["path_filters"].([]interface{}); ok {
+			for _, f := range filters {
+				if filter, ok := f.(string); ok && filter != "" {
+					board.PathFilters = append(board.PathFilters, filter)
+				}
+			}
+		}
+
+		if board.ID == "" {
+			return nil, fmt.Errorf("board id is required")
+		}
+		if seen[board.ID] {
+			return nil, fmt.Errorf("duplicate board id %q", board.ID)
+		}
+		if len(board.Columns) == 0 {
+			return nil, fmt.Errorf("board %q must have at least one column", board.ID)
+		}
+		if len(board.ItemTypes()) == 0 {
+			return nil, fmt.Errorf("board %q must have a column with an auto assign pattern", board.ID)
+		}
+		if board.Name == "" {
+			board.Name = board.ID
+		}
+
+		seen[board.ID] = true
+		boards = append(boards, board)
+	}
+
+	return boards, nil
+}
+
+func parseAutomationRules(rulesData []interface{}) ([]entities.AutomationRule, error) {
+	rules := []entities.AutomationRule{}
+	seen := map[string]bool{}
+
+	for _, r := range rulesData {
+		rMap, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		rule := entities.AutomationRule{}
+		if id, ok := rMap["id"].(string); ok {
+			rule.ID = strings.TrimSpace(id)
+		}
+		if name, ok := rMap["name"].(string); ok {
+			rule.Name = name
+		}
+		if enabled, ok := rMap["enabled"].(bool); ok {
+			rule.Enabled = enabled
+		}
+		if query, ok := rMap["query"].(string); ok {
+			rule.Query = query
+		}
+		if days, ok := rMap["untouched_days"].(float64); ok {
+			rule.UntouchedDays = int(days)
+		}
+		if days, ok := rMap["no_commit_days"].(float64); ok {
+			rule.NoCommitDays = int(days)
+		}
+		if action, ok := rMap["action"].(string); ok {
+			rule.Action = entities.AutomationAction(action)
+		}
+		if priority, ok := rMap["priority"].(string); ok {
+			rule.Priority = entities.ItemPriority(strings.ToUpper(priority))
+		}
+
+		if rule.ID == "" {
+			return nil, fmt.Errorf("automation rule id is required")
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate automation rule id %q", rule.ID)
+		}
+		if _, err := ParseItemQuery(rule.Query); err != nil {
+			return nil, fmt.Errorf("invalid query for automation rule %q: %v", rule.ID, err)
+		}
+		if rule.UntouchedDays < 0 || rule.NoCommitDays < 0 {
+			return nil, fmt.Errorf("automation rule %q must not use negative days", rule.ID)
+		}
+		if !rule.Action.IsValid() {
+			return nil, fmt.Errorf("automation rule %q has unknown action %q", rule.ID, rule.Action)
+		}
+		if rule.Action == entities.AutomationEscalate && priorityRank(rule.Priority) < 0 {
+			return nil, fmt.Errorf("automation rule %q must escalate to LOW, MEDIUM or HIGH", rule.ID)
+		}
+		if rule.Action != entities.AutomationEscalate {
+			rule.Priority = ""
+		}
+		if rule.Name == "" {
+			rule.Name = rule.ID
+		}
+
+		seen[rule.ID] = true
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func parseDebtModel(model entities.DebtModel, dmMap map[string]interface{}) (entities.DebtModel, error) {
+	for field, target := range map[string]*map[string]float64{
+		"type_weights":     &model.TypeWeights,
+		"priority_weights": &model.PriorityWeights,
+	} {
+		weightsData, ok := dmMap[field].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		weights := make(map[string]float64, len(weightsData))
+		for key, value := range weightsData {
+			weight, ok := value.(float64)
+			if !ok || weight < 0 {
+				return model, fmt.Errorf("debt model %s for %q must be a non-negative number", field, key)
+			}
+			weights[strings.ToUpper(key)] = weight
+		}
+		*target = weights
+	}
+
+	for field, target := range map[string]*float64{
+		"age_per_month":   &model.AgePerMonth,
+		"alert_threshold": &model.AlertThreshold,
+	} {
+		if value, ok := dmMap[field].(float64); ok {
+			if value < 0 {
+				return model, fmt.Errorf("debt model %s must not be negative", field)
+			}
+			*target = value
+		}
+	}
+
+	if value, ok := dmMap["alert_window_days"].(float64); ok {
+		if value < 1 {
+			return model, fmt.Errorf("debt model alert_window_days must be at least 1")
+		}
+		model.AlertWindowDays = int(value)
+	}
+
+	return model, nil
+}
+
+func (sm *SettingsService) GetSavedQuery(name string) (*entities.SavedQuery, bool) {
+	settings := sm.currentSettings()
+	for _, q := range settings.SavedQueries {
+		if q.Name == name {
+			return &q, true
+		}
+	}
+	return nil, false
+}
+
 func (sm *SettingsService) UpdatePartialSettings(updates map[string]interface{}) (*entities.Settings, error) {
+	return sm.UpdateSettings(func(settings *entities.Settings) error {
+		return applySettingsUpdates(settings, updates)
+	})
+}
 
-	settings := sm.LoadSettings()
+func applySettingsUpdates(settings *entities.Settings, updates map[string]interface{}) error {
+	if backend, ok := updates["storage"].(string); ok && backend != settings.Storage {
+		return fmt.Errorf("the storage backend can only be changed with kodo migrate-storage")
+	}
 
 	if kanbanColumns, ok := updates["kanban_columns"]; ok {
 		if columnsData, ok := kanbanColumns.([]interface{}); ok {
-			var columns []entities.KanbanColumn
-			for _, col := range columnsData {
-				if colMap, ok := col.(map[string]interface{}); ok {
-					column := entities.KanbanColumn{}
-					if id, ok := colMap["id"].(string); ok {
-						column.ID = id
-					}
-					if name, ok := colMap["name"].(string); ok {
-						column.Name = name
-					}
-					if color, ok := colMap["color"].(string); ok {
-						column.Color = color
-					}
-					if pattern, ok := colMap["auto_assign_pattern"].(string); ok {
-						column.AutoAssignPattern = &pattern
-					}
-					columns = append(columns, column)
-				}
+			settings.KanbanColumns = parseKanbanColumns(columnsData)
+		}
+	}
+
+	if boardsUpdate, ok := updates["boards"]; ok {
+		if boardsData, ok := boardsUpdate.([]interface{}); ok {
+			boards, err := parseBoards(boardsData)
+			if err != nil {
+				return err
 			}
-			settings.KanbanColumns = columns
+			settings.Boards = boards
 		}
 	}
 
@@ -262,22 +551,91 @@ func (sm *SettingsService) UpdatePartialSettings(updates map[string]interface{})
 		}
 	}
 
-	if err := sm.SaveSettings(settings); err != nil {
-		return nil, err
+	if savedQueries, ok := updates["saved_queries"]; ok {
+		if queriesData, ok := savedQueries.([]interface{}); ok {
+			queries := []entities.SavedQuery{}
+			for _, q := range queriesData {
+				if qMap, ok := q.(map[string]interface{}); ok {
+					query := entities.SavedQuery{}
+					if name, ok := qMap["name"].(string); ok {
+						query.Name = name
+					}
+					if raw, ok := qMap["query"].(string); ok {
+						query.Query = raw
+					}
+					if query.Name == "" {
+						continue
+					}
+					if _, err := ParseItemQuery(query.Query); err != nil {
+						return fmt.Errorf("invalid saved query %q: %v", query.Name, err)
+					}
+					queries = append(queries, query)
+				}
+			}
+			settings.SavedQueries = queries
+		}
 	}
 
-	return settings, nil
+	if automationRules, ok := updates["automation_rules"]; ok {
+		if rulesData, ok := automationRules.([]interface{}); ok {
+			rules, err := parseAutomationRules(rulesData)
+			if err != nil {
+				return err
+			}
+			settings.AutomationRules = rules
+		}
+	}
+
+	if debtModel, ok := updates["debt_model"]; ok {
+		if dmMap, ok := debtModel.(map[string]interface{}); ok {
+			model, err := parseDebtModel(settings.DebtModel, dmMap)
+			if err != nil {
+				return err
+			}
+			settings.DebtModel = model
+		}
+	}
+
+	if historyRetention, ok := updates["history_retention"]; ok {
+		if hrMap, ok := historyRetention.(map[string]interface{}); ok {
+			retention := settings.HistoryRetention
+			for field, target := range map[string]*int{
+				"full_days":   &retention.FullDays,
+				"daily_days":  &retention.DailyDays,
+				"weekly_days": &retention.WeeklyDays,
+			} {
+				if value, ok := hrMap[field].(float64); ok {
+					if value < 0 {
+						return fmt.Errorf("history retention %s must not be negative", field)
+					}
+					*target = int(value)
+				}
+			}
+			if retention.FullDays < 1 {
+				return fmt.Errorf("history retention full_days must be at least 1")
+			}
+			settings.HistoryRetention = retention
+		}
+	}
+
+	return nil
 }
 
 func (sm *SettingsService) GetSettingsSummary() map[string]interface{} {
-	settings := sm.LoadSettings()
+	settings := sm.currentSettings()
 
 	return map[string]interface{}{
 		"kanban_columns_count": len(settings.KanbanColumns),
+		"boards_count":         len(settings.GetBoards()),
 		"sync_enabled":         settings.CodeScanSettings.SyncEnabled,
 		"exclude_directories":  len(settings.CodeScanSettings.ExcludeDirectories),
 		"exclude_files":        len(settings.CodeScanSettings.ExcludeFiles),
 		"has_github_token":     settings.GithubAuth.Token != "",
+		"saved_queries":        len(settings.SavedQueries),
+		"history_retention":    settings.HistoryRetention,
+		"automation_rules":     len(settings.AutomationRules),
+		"debt_model":           settings.DebtModel,
+		"storage":              settings.Storage,
 		"created_at":           settings.CreatedAt,
 		"updated_at":           settings.UpdatedAt,
 	}
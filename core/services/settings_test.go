@@ -0,0 +1,93 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prodemmi/kodo/core/storage"
+
+	"go.uber.org/zap"
+)
+
+func TestBoardsWithoutColumns(t *testing.T) {
+	config := newTestProject(t, map[string]string{
+		".kodo/settings.json": `{
+  "schema_version": 1,
+  "boards": [
+    {"id": "empty", "name": "Empty", "columns": []},
+    {"id": "web", "name": "Web", "columns": [{"id": "open", "name": "OPEN", "auto_assign_pattern": "TODO"}]}
+  ]
+}`,
+	})
+	settings := NewSettingsService(config, zap.NewNop())
+
+	if _, ok := settings.GetBoard("empty"); ok {
+		t.Error("board without columns was loaded")
+	}
+	if _, ok := settings.GetBoard("web"); !ok {
+		t.Error("valid board was dropped")
+	}
+
+	_, err := settings.UpdatePartialSettings(map[string]interface{}{
+		"boards": []interface{}{
+			map[string]interface{}{"id": "empty", "columns": []interface{}{}},
+		},
+	})
+	if err == nil {
+		t.Error("update accepted a board without columns")
+	}
+
+	for _, board := range settings.GetBoards() {
+		if len(board.Columns) == 0 {
+			t.Errorf("board %q has no columns", board.ID)
+		}
+	}
+	loaded, err := settings.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.KanbanColumns) == 0 {
+		t.Error("default board has no columns")
+	}
+}
+
+func TestSettingsAreNotOverwrittenWhenLoadFails(t *testing.T) {
+	for name, content := range map[string]string{
+		"corrupt": `{"schema_version": 1, "boards": [`,
+		"newer":   `{"schema_version": 99, "boards": []}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			config := newTestProject(t, map[string]string{".kodo/settings.json": content})
+			settings := NewSettingsService(config, zap.NewNop())
+
+			_, err := settings.LoadSettings()
+			if err == nil {
+				t.Fatal("loaded an unreadable settings file")
+			}
+			var schemaErr *storage.SchemaError
+			if name == "newer" && !errors.As(err, &schemaErr) {
+				t.Errorf("got %v, want a schema error", err)
+			}
+
+			if _, err := settings.UpdatePartialSettings(map[string]interface{}{"saved_queries": []interface{}{}}); err == nil {
+				t.Error("update saved over an unreadable settings file")
+			}
+			if got := readTestFile(t, ".kodo/settings.json"); got != content {
+				t.Errorf("settings file was rewritten:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestMissingSettingsUseDefaults(t *testing.T) {
+	config := newTestProject(t, nil)
+	settings := NewSettingsService(config, zap.NewNop())
+
+	loaded, err := settings.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.KanbanColumns) == 0 {
+		t.Error("defaults have no columns")
+	}
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+// itemMatchThreshold is the lowest similarity at which a removed and an
+// added item are treated as the same item.
+const itemMatchThreshold = 0.6
+
+type itemMatch struct {
+	Previous   entities.TaskItem
+	Current    entities.TaskItem
+	Similarity float64
+}
+
+func (m itemMatch) Changes() []string {
+	changes := []string{}
+	if m.Previous.File != m.Current.File {
+		changes = append(changes, "moved")
+	}
+	if normalizeTitle(m.Previous.Title) != normalizeTitle(m.Current.Title) {
+		changes = append(changes, "renamed")
+	}
+	if m.Previous.Priority != m.Current.Priority {
+		changes = append(changes, "priority")
+	}
+	return changes
+}
+
+// matchItems pairs removed and added items by similarity, best pairs first.
+// Items left over are the real additions and removals.
+func matchItems(removed, added []entities.TaskItem) (matches []itemMatch, unmatchedRemoved, unmatchedAdded []entities.TaskItem) {
+	type candidate struct {
+		removed, added int
+		score          float64
+		distance       int
+	}
+
+	var candidates []candidate
+	for i, prev := range removed {
+		for j, cur := range added {
+			score := itemSimilarity(prev, cur)
+			if score < itemMatchThreshold {
+				continue
+			}
+			distance := prev.Line - cur.Line
+			if distance < 0 {
+				distance = -distance
+			}
+			candidates = append(candidates, candidate{removed: i, added: j, score: score, distance: distance})
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		return a.distance - b.distance
+	})
+
+	removedTaken := make([]bool, len(removed))
+	addedTaken := make([]bool, len(added))
+	for _, c := range candidates {
+		if removedTaken[c.removed] || addedTaken[c.added] {
+			continue
+		}
+		removedTaken[c.removed] = true
+		addedTaken[c.added] = true
+		matches = append(matches, itemMatch{
+			Previous:   removed[c.removed],
+			Current:    added[c.added],
+			Similarity: c.score,
+		})
+	}
+
+	for i, item := range removed {
+		if !removedTaken[i] {
+			unmatchedRemoved = append(unmatchedRemoved, item)
+		}
+	}
+	for j, item := range added {
+		if !addedTaken[j] {
+			unmatchedAdded = append(unmatchedAdded, item)
+		}
+	}
+
+	return matches, unmatchedRemoved, unmatchedAdded
+}
+
+// itemSimilarity scores two items between 0 and 1. Items of different
+// types never match; staying in the same file adds to the title score.
+func itemSimilarity(a, b entities.TaskItem) float64 {
+	if !strings.EqualFold(string(a.Type), string(b.Type)) {
+		return 0
+	}
+
+	score := titleSimilarity(a.Title, b.Title) * 0.8
+	if a.File == b.File {
+		score += 0.2
+	}
+	return score
+}
+
+// titleSimilarity is the Sørensen–Dice coefficient over character bigrams
+// of the normalized titles.
+func titleSimilarity(a, b string) float64 {
+	a, b = normalizeTitle(a), normalizeTitle(b)
+	if a == b {
+		return 1
+	}
+
+	aBigrams := bigrams(a)
+	bBigrams := bigrams(b)
+	if len(aBigrams) == 0 || len(bBigrams) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int, len(aBigrams))
+	for _, bg := range aBigrams {
+		counts[bg]++
+	}
+
+	shared := 0
+	for _, bg := range bBigrams {
+		if counts[bg] > 0 {
+			counts[bg]--
+			shared++
+		}
+	}
+
+	return 2 * float64(shared) / float64(len(aBigrams)+len(bBigrams))
+}
+
+func bigrams(s string) []string {
+	runes := []rune(s)
+	if len(runes) < 2 {
+		return nil
+	}
+	result := make([]string, 0, len(runes)-1)
+	for i := 0; i < len(runes)-1; i++ {
+		result = append(result, string(runes[i:i+2]))
+	}
+	return result
+}
+
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
@@ -0,0 +1,545 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"github.com/prodemmi/kodo/core/storage"
+	"go.uber.org/zap"
+)
+
+type SprintService struct {
+	config         *entities.Config
+	logger         *zap.Logger
+	settings       *SettingsService
+	historyService *HistoryService
+	noteService    *NoteService
+}
+
+func NewSprintService(config *entities.Config, logger *zap.Logger, settings *SettingsService, historyService *HistoryService, noteService *NoteService) *SprintService {
+	return &SprintService{
+		config:         config,
+		logger:         logger,
+		settings:       settings,
+		historyService: historyService,
+		noteService:    noteService,
+	}
+}
+
+func (s *SprintService) getSprintsFilePath() string {
+	wd, _ := os.Getwd()
+	return filepath.Join(wd, s.config.Flags.Config, storage.SprintsFile)
+}
+
+func (s *SprintService) loadSprintStorage() (*entities.SprintStorage, error) {
+	data, err := os.ReadFile(s.getSprintsFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &entities.SprintStorage{
+				Sprints: []entities.Sprint{},
+				NextID:  1,
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to read sprints file: %v", err)
+	}
+
+	var storage entities.SprintStorage
+	if err := json.Unmarshal(data, &storage); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sprints: %v", err)
+	}
+
+	if storage.NextID < 1 {
+		storage.NextID = 1
+	}
+
+	return &storage, nil
+}
+
+func (s *SprintService) saveSprintStorage(sprints *entities.SprintStorage) error {
+	data, err := json.MarshalIndent(sprints, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sprints: %v", err)
+	}
+
+	if err := storage.WriteFile(s.getSprintsFilePath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write sprints file: %v", err)
+	}
+
+	return nil
+}
+
+// updateSprintStorage runs fn on the sprints while holding the lock of
+// sprints.json and saves them, unless fn returns an error or errUnchanged.
+func (s *SprintService) updateSprintStorage(fn func(sprints *entities.SprintStorage) error) error {
+	return storage.WithFileLock(s.getSprintsFilePath(), func() error {
+		sprints, err := s.loadSprintStorage()
+		if err != nil {
+			return err
+		}
+
+		if err := fn(sprints); err != nil {
+			if errors.Is(err, errUnchanged) {
+				return nil
+			}
+			return err
+		}
+		return s.saveSprintStorage(sprints)
+	})
+}
+
+// updateSprint runs fn on a single sprint and returns the sprint as saved.
+func (s *SprintService) updateSprint(id int, fn func(sprint *entities.Sprint) error) (*entities.Sprint, error) {
+	var updated entities.Sprint
+	err := s.updateSprintStorage(func(sprints *entities.SprintStorage) error {
+		sprint := s.findSprint(sprints, id)
+		if sprint == nil {
+			return errors.New("sprint not found")
+		}
+
+		err := fn(sprint)
+		updated = *sprint
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+func (s *SprintService) findSprint(storage *entities.SprintStorage, id int) *entities.Sprint {
+	for i := range storage.Sprints {
+		if storage.Sprints[i].ID == id {
+			return &storage.Sprints[i]
+		}
+	}
+	return nil
+}
+
+func validateSprint(name string, kind entities.SprintKind, start, end time.Time) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("sprint name is required")
+	}
+	if !kind.IsValid() {
+		return errors.New("invalid sprint kind")
+	}
+	if end.IsZero() {
+		return errors.New("end date is required")
+	}
+	if !start.IsZero() && end.Before(start) {
+		return errors.New("end date must be after start date")
+	}
+	return nil
+}
+
+func (s *SprintService) GetSprints() ([]entities.Sprint, error) {
+	storage, err := s.loadSprintStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(storage.Sprints, func(a, b entities.Sprint) int {
+		return a.StartDate.Compare(b.StartDate)
+	})
+
+	return storage.Sprints, nil
+}
+
+func (s *SprintService) GetSprint(id int) (*entities.Sprint, error) {
+	storage, err := s.loadSprintStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	sprint := s.findSprint(storage, id)
+	if sprint == nil {
+		return nil, errors.New("sprint not found")
+	}
+
+	return sprint, nil
+}
+
+func (s *SprintService) CreateSprint(name string, kind entities.SprintKind, goal string, start, end time.Time) (*entities.Sprint, error) {
+	if kind == "" {
+		kind = entities.SprintKindSprint
+	}
+
+	now := time.Now()
+	if start.IsZero() {
+		start = now
+	}
+
+	if err := validateSprint(name, kind, start, end); err != nil {
+		return nil, err
+	}
+
+	var sprint entities.Sprint
+	err := s.updateSprintStorage(func(sprints *entities.SprintStorage) error {
+		sprint = entities.Sprint{
+			ID:        sprints.NextID,
+			Name:      strings.TrimSpace(name),
+			Kind:      kind,
+			Goal:      goal,
+			StartDate: start,
+			EndDate:   end,
+			Items:     []entities.SprintItem{},
+			NoteIDs:   []int{},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		sprints.Sprints = append(sprints.Sprints, sprint)
+		sprints.NextID++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sprint, nil
+}
+
+func (s *SprintService) UpdateSprint(id int, name string, kind entities.SprintKind, goal string, start, end time.Time) (*entities.Sprint, error) {
+	return s.updateSprint(id, func(sprint *entities.Sprint) error {
+		if kind == "" {
+			kind = sprint.Kind
+		}
+		if start.IsZero() {
+			start = sprint.StartDate
+		}
+		if end.IsZero() {
+			end = sprint.EndDate
+		}
+
+		if err := validateSprint(name, kind, start, end); err != nil {
+			return err
+		}
+
+		sprint.Name = strings.TrimSpace(name)
+		sprint.Kind = kind
+		sprint.Goal = goal
+		sprint.StartDate = start
+		sprint.EndDate = end
+		sprint.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+func (s *SprintService) DeleteSprint(id int) error {
+	return s.updateSprintStorage(func(sprints *entities.SprintStorage) error {
+		for i, sprint := range sprints.Sprints {
+			if sprint.ID == id {
+				sprints.Sprints = append(sprints.Sprints[:i], sprints.Sprints[i+1:]...)
+				return nil
+			}
+		}
+		return errors.New("sprint not found")
+	})
+}
+
+func (s *SprintService) AssignItem(sprintID int, item *entities.Item) (*entities.Sprint, error) {
+	return s.updateSprint(sprintID, func(sprint *entities.Sprint) error {
+		for _, assigned := range sprint.Items {
+			if assigned.ItemKey == item.Key && assigned.RemovedAt == nil {
+				return errors.New("item already assigned to sprint")
+			}
+		}
+
+		now := time.Now()
+		sprint.Items = append(sprint.Items, entities.SprintItem{
+			ItemKey: item.Key,
+			Type:    item.Type,
+			Title:   item.Title,
+			File:    item.File,
+			AddedAt: now,
+			AddedBy: getGitAuthor(),
+		})
+		sprint.UpdatedAt = now
+		return nil
+	})
+}
+
+func (s *SprintService) UnassignItem(sprintID int, itemKey string) (*entities.Sprint, error) {
+	return s.updateSprint(sprintID, func(sprint *entities.Sprint) error {
+		now := time.Now()
+		for i := range sprint.Items {
+			if sprint.Items[i].ItemKey != itemKey || sprint.Items[i].RemovedAt != nil {
+				continue
+			}
+
+			// Removing an item before the sprint starts is planning, not a scope change.
+			if now.Before(sprint.StartDate) {
+				sprint.Items = append(sprint.Items[:i], sprint.Items[i+1:]...)
+			} else {
+				sprint.Items[i].RemovedAt = &now
+			}
+			sprint.UpdatedAt = now
+			return nil
+		}
+		return errors.New("item not assigned to sprint")
+	})
+}
+
+func (s *SprintService) AssignNote(sprintID, noteID int) (*entities.Sprint, error) {
+	if s.noteService.getNoteByID(noteID) == nil {
+		return nil, errors.New("note not found")
+	}
+
+	return s.updateSprint(sprintID, func(sprint *entities.Sprint) error {
+		if slices.Contains(sprint.NoteIDs, noteID) {
+			return errUnchanged
+		}
+		sprint.NoteIDs = append(sprint.NoteIDs, noteID)
+		sprint.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+func (s *SprintService) UnassignNote(sprintID, noteID int) (*entities.Sprint, error) {
+	return s.updateSprint(sprintID, func(sprint *entities.Sprint) error {
+		index := slices.Index(sprint.NoteIDs, noteID)
+		if index == -1 {
+			return errors.New("note not assigned to sprint")
+		}
+
+		sprint.NoteIDs = slices.Delete(sprint.NoteIDs, index, index+1)
+		sprint.UpdatedAt = time.Now()
+		return nil
+	})
+}
+
+func (s *SprintService) GetSprintsForItem(itemKey string) ([]entities.Sprint, error) {
+	storage, err := s.loadSprintStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	sprints := []entities.Sprint{}
+	for _, sprint := range storage.Sprints {
+		for _, assigned := range sprint.Items {
+			if assigned.ItemKey == itemKey && assigned.RemovedAt == nil {
+				sprints = append(sprints, sprint)
+				break
+			}
+		}
+	}
+
+	return sprints, nil
+}
+
+func (s *SprintService) GetSprintReport(id int, items []*entities.Item) (*entities.SprintReport, error) {
+	sprint, err := s.GetSprint(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	settings, err := s.settings.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	itemsByKey := make(map[string]*entities.Item)
+	for _, item := range items {
+		itemsByKey[item.Key] = item
+	}
+
+	snapshots := append([]entities.BranchSnapshot{}, s.historyService.GetBranchHistory()...)
+	slices.SortFunc(snapshots, func(a, b entities.BranchSnapshot) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	report := &entities.SprintReport{
+		Sprint:         *sprint,
+		State:          sprint.StateAt(now),
+		DaysTotal:      int(sprint.EndDate.Sub(sprint.StartDate).Hours()/24) + 1,
+		Items:          []entities.SprintItemReport{},
+		AddedItems:     []entities.SprintItemReport{},
+		RemovedItems:   []entities.SprintItemReport{},
+		CarryOverItems: []entities.SprintItemReport{},
+		Notes:          []entities.Note{},
+	}
+
+	switch report.State {
+	case entities.SprintActive:
+		report.DaysElapsed = int(now.Sub(sprint.StartDate).Hours()/24) + 1
+	case entities.SprintClosed:
+		report.DaysElapsed = report.DaysTotal
+	}
+
+	for _, assigned := range sprint.Items {
+		itemReport := s.buildItemReport(assigned, itemsByKey[assigned.ItemKey], snapshots, settings)
+
+		if assigned.RemovedAt != nil {
+			if assigned.RemovedAt.After(sprint.StartDate) && !assigned.RemovedAt.After(sprint.EndDate) {
+				report.ScopeRemoved++
+				report.RemovedItems = append(report.RemovedItems, itemReport)
+			}
+			if !assigned.AddedAt.After(sprint.StartDate) {
+				report.InitialScope++
+			}
+			continue
+		}
+
+		// Work finished before the sprint is listed but adds neither scope
+		// nor velocity.
+		if itemReport.CompletedAt != nil && itemReport.CompletedAt.Before(sprint.StartDate) {
+			report.DoneBeforeStart++
+			report.Items = append(report.Items, itemReport)
+			continue
+		}
+
+		if assigned.AddedAt.After(sprint.StartDate) {
+			report.ScopeAdded++
+			report.AddedItems = append(report.AddedItems, itemReport)
+		} else {
+			report.InitialScope++
+		}
+
+		report.Scope++
+		report.Items = append(report.Items, itemReport)
+
+		if itemReport.CompletedAt != nil && !itemReport.CompletedAt.After(sprint.EndDate) {
+			report.Completed++
+			continue
+		}
+
+		report.Remaining++
+		if report.State == entities.SprintClosed {
+			report.CarryOver++
+			report.CarryOverItems = append(report.CarryOverItems, itemReport)
+		}
+	}
+
+	if report.Scope > 0 {
+		report.CompletionPercent = float64(report.Completed) / float64(report.Scope) * 100
+	}
+
+	for _, noteID := range sprint.NoteIDs {
+		if note := s.noteService.getNoteByID(noteID); note != nil {
+			report.Notes = append(report.Notes, *note)
+		}
+	}
+
+	return report, nil
+}
+
+func (s *SprintService) buildItemReport(assigned entities.SprintItem, item *entities.Item, snapshots []entities.BranchSnapshot, settings *entities.Settings) entities.SprintItemReport {
+	itemReport := entities.SprintItemReport{
+		ItemKey:   assigned.ItemKey,
+		Type:      assigned.Type,
+		Title:     assigned.Title,
+		File:      assigned.File,
+		AddedAt:   assigned.AddedAt,
+		RemovedAt: assigned.RemovedAt,
+	}
+
+	if item != nil {
+		board := settings.BoardFor(item)
+		lastColumn := board.Columns[len(board.Columns)-1].ID
+
+		itemReport.InCode = true
+		itemReport.File = item.File
+		itemReport.Line = item.Line
+		itemReport.Status = board.StatusFor(item.Status)
+
+		if string(itemReport.Status) == lastColumn || item.IsDone {
+			for _, h := range item.History {
+				if string(board.StatusFor(h.Status)) == lastColumn {
+					completedAt := h.Timestamp
+					itemReport.CompletedAt = &completedAt
+				}
+			}
+			if itemReport.CompletedAt == nil && item.DoneAt != nil {
+				itemReport.CompletedAt = item.DoneAt
+			}
+		}
+
+		return itemReport
+	}
+
+	if lifecycle := s.historyService.GetItemLifecycle(assigned.ItemKey); lifecycle != nil {
+		itemReport.Status = lifecycle.LastStatus
+		itemReport.Line = lifecycle.Line
+		itemReport.CompletedAt = lifecycle.RemovedAt
+		return itemReport
+	}
+
+	// Items removed before lifecycles were tracked: fall back to the first
+	// snapshot taken after their last appearance.
+	lastSeen := -1
+	for i, snapshot := range snapshots {
+		for _, taskItem := range snapshot.History.Items {
+			if taskItem.GetKey() == assigned.ItemKey {
+				lastSeen = i
+				itemReport.Status = taskItem.Status
+				itemReport.Line = taskItem.Line
+				break
+			}
+		}
+	}
+
+	if lastSeen != -1 && lastSeen+1 < len(snapshots) {
+		removedAt := snapshots[lastSeen+1].Timestamp
+		itemReport.CompletedAt = &removedAt
+	}
+
+	return itemReport
+}
+
+func (s *SprintService) Reconcile(items []*entities.Item) error {
+	itemsByKey := make(map[string]*entities.Item)
+	for _, item := range items {
+		itemsByKey[item.Key] = item
+	}
+
+	return s.updateSprintStorage(func(sprints *entities.SprintStorage) error {
+		changed := false
+		for i := range sprints.Sprints {
+			sprint := &sprints.Sprints[i]
+
+			takenKeys := make(map[string]bool)
+			for _, assigned := range sprint.Items {
+				takenKeys[assigned.ItemKey] = true
+			}
+
+			for j := range sprint.Items {
+				assigned := &sprint.Items[j]
+				if assigned.RemovedAt != nil {
+					continue
+				}
+				if _, ok := itemsByKey[assigned.ItemKey]; ok {
+					continue
+				}
+
+				item := findMovedItem(assigned.Type, assigned.Title, items, takenKeys)
+				if item == nil {
+					continue
+				}
+
+				s.logger.Info("Re-anchored sprint item",
+					zap.Int("sprint_id", sprint.ID),
+					zap.String("old_key", assigned.ItemKey),
+					zap.String("new_key", item.Key))
+
+				delete(takenKeys, assigned.ItemKey)
+				takenKeys[item.Key] = true
+				assigned.ItemKey = item.Key
+				assigned.File = item.File
+				changed = true
+			}
+		}
+
+		if !changed {
+			return errUnchanged
+		}
+		return nil
+	})
+}
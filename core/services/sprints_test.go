@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	"go.uber.org/zap"
+)
+
+func TestSprintReportCountsCompletionsInsideSprint(t *testing.T) {
+	config := newTestProject(t, nil)
+	scanner := newTestScanner(t, config)
+	logger := zap.NewNop()
+	sprints := NewSprintService(config, logger, scanner.settings, scanner.historyService, nil)
+
+	start := time.Now().Add(-48 * time.Hour)
+	end := time.Now().Add(48 * time.Hour)
+	sprint, err := sprints.CreateSprint("Sprint 1", entities.SprintKindSprint, "", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doneAt := func(at time.Time) *entities.Item {
+		return &entities.Item{
+			Status: "DONE",
+			History: []entities.StatusHistory{
+				{Status: "todo", Timestamp: start.Add(-72 * time.Hour)},
+				{Status: "done", Timestamp: at},
+			},
+		}
+	}
+	items := []*entities.Item{
+		doneAt(start.Add(-24 * time.Hour)),
+		doneAt(start.Add(24 * time.Hour)),
+		{Status: "todo", History: []entities.StatusHistory{{Status: "todo", Timestamp: start}}},
+	}
+	for i, item := range items {
+		item.Key = entities.ItemKey("a.go", "TODO", "item", i)
+		item.Type = "TODO"
+		if _, err := sprints.AssignItem(sprint.ID, item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := sprints.GetSprintReport(sprint.ID, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Completed != 1 {
+		t.Errorf("completed = %d, want 1", report.Completed)
+	}
+	if report.DoneBeforeStart != 1 {
+		t.Errorf("done before start = %d, want 1", report.DoneBeforeStart)
+	}
+	if report.Scope != 2 || report.Remaining != 1 {
+		t.Errorf("scope = %d, remaining = %d, want 2 and 1", report.Scope, report.Remaining)
+	}
+	if len(report.Items) != 3 {
+		t.Errorf("listed %d items, want 3", len(report.Items))
+	}
+}
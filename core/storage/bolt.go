@@ -0,0 +1,617 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketMeta          = []byte("meta")
+	bucketNotes         = []byte("notes")
+	bucketFolders       = []byte("folders")
+	bucketHistory       = []byte("note_history")
+	bucketNotesByFolder = []byte("notes_by_folder")
+	bucketNotesByCat    = []byte("notes_by_category")
+	bucketHistoryByNote = []byte("note_history_by_note")
+	bucketSnapshots     = []byte("snapshots")
+	bucketLifecycles    = []byte("lifecycles")
+
+	keyNextID        = []byte("next_id")
+	keyNextHistoryID = []byte("next_history_id")
+	keyStats         = []byte("stats")
+	keySchemaVersion = []byte("schema_version")
+)
+
+var boltBuckets = [][]byte{
+	bucketMeta, bucketNotes, bucketFolders, bucketHistory, bucketNotesByFolder,
+	bucketNotesByCat, bucketHistoryByNote, bucketSnapshots, bucketLifecycles,
+}
+
+// BoltStore keeps one record per note, folder, history entry, snapshot and
+// lifecycle in kodo.db, with indexes on the note folder and category and on
+// the note of a history entry.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+func OpenBoltStore(dir string) (*BoltStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %v", err)
+	}
+
+	db, err := bolt.Open(filepath.Join(dir, "kodo.db"), 0644, &bolt.Options{Timeout: 2 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open kodo.db: %v", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range boltBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		// Databases created before the schema was versioned are version 0,
+		// which has the same layout as version 1.
+		meta := tx.Bucket(bucketMeta)
+		if data := meta.Get(keySchemaVersion); len(data) == 8 && btoi(data) > BoltSchema {
+			return &SchemaError{File: "kodo.db", Version: btoi(data), Supported: BoltSchema}
+		}
+		return meta.Put(keySchemaVersion, itob(BoltSchema))
+	})
+	if err != nil {
+		_ = db.Close()
+		if _, ok := err.(*SchemaError); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to prepare kodo.db: %v", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Backend() string {
+	return BackendBolt
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStore) ViewNotes(fn func(tx NoteTx) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return fn(&boltNoteTx{tx: tx})
+	})
+}
+
+func (s *BoltStore) UpdateNotes(fn func(tx NoteTx) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(&boltNoteTx{tx: tx})
+	})
+}
+
+func (s *BoltStore) DumpNotes() (*entities.EnhancedNoteStorage, error) {
+	notes := &entities.EnhancedNoteStorage{SchemaVersion: NotesSchema}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		ntx := &boltNoteTx{tx: tx}
+
+		var err error
+		if notes.Notes, err = ntx.Notes(NoteQuery{}); err != nil {
+			return err
+		}
+		if notes.Folders, err = ntx.Folders(); err != nil {
+			return err
+		}
+		if notes.History, err = ntx.History(entities.NoteHistoryFilter{}); err != nil {
+			return err
+		}
+		notes.NextID = ntx.counter(keyNextID)
+		notes.NextHistoryID = ntx.counter(keyNextHistoryID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// RestoreNotes replaces all notes, folders and history entries.
+func (s *BoltStore) RestoreNotes(notes *entities.EnhancedNoteStorage) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketNotes, bucketFolders, bucketHistory, bucketNotesByFolder, bucketNotesByCat, bucketHistoryByNote} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+
+		ntx := &boltNoteTx{tx: tx}
+		for _, note := range notes.Notes {
+			if err := ntx.PutNote(note); err != nil {
+				return err
+			}
+		}
+		for _, folder := range notes.Folders {
+			if err := ntx.PutFolder(folder); err != nil {
+				return err
+			}
+		}
+		for _, entry := range notes.History {
+			if err := ntx.putHistory(entry); err != nil {
+				return err
+			}
+		}
+
+		if err := ntx.setCounter(keyNextID, max(notes.NextID, 1)); err != nil {
+			return err
+		}
+		return ntx.setCounter(keyNextHistoryID, max(notes.NextHistoryID, 1))
+	})
+}
+
+// LoadStats assembles the history from its stats, snapshot and lifecycle
+// records. Snapshots come back in time order.
+func (s *BoltStore) LoadStats() (*entities.ItemsHistory, error) {
+	var history *entities.ItemsHistory
+	err := s.db.View(func(tx *bolt.Tx) (err error) {
+		history, err = loadStats(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// SaveStats only writes the records that changed and drops the snapshots
+// and lifecycles that are gone.
+func (s *BoltStore) SaveStats(history *entities.ItemsHistory) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return saveStats(tx, history)
+	})
+}
+
+func (s *BoltStore) UpdateStats(fn func(history *entities.ItemsHistory) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		history, err := loadStats(tx)
+		if err != nil {
+			return err
+		}
+		if history == nil {
+			history = &entities.ItemsHistory{}
+		}
+
+		if err := fn(history); err != nil {
+			return err
+		}
+		return saveStats(tx, history)
+	})
+}
+
+// PatchStats reads and writes single records, so a rescan that does not
+// add a snapshot never touches the snapshots.
+func (s *BoltStore) PatchStats(fn func(tx StatsTx) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(&boltStatsTx{tx: tx})
+	})
+}
+
+func loadStats(tx *bolt.Tx) (*entities.ItemsHistory, error) {
+	records := &boltStatsTx{tx: tx}
+
+	history, err := records.Stats()
+	if err != nil || history == nil {
+		return nil, err
+	}
+	if history.BranchHistory, err = records.Snapshots(); err != nil {
+		return nil, err
+	}
+	if history.Lifecycles, err = records.Lifecycles(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func saveStats(tx *bolt.Tx, history *entities.ItemsHistory) error {
+	stats := *history
+	stats.SchemaVersion = ItemsSchema
+	stats.BranchHistory = nil
+	stats.Lifecycles = nil
+	if err := putJSONIfChanged(tx.Bucket(bucketMeta), keyStats, stats); err != nil {
+		return err
+	}
+
+	if err := syncBucket(tx.Bucket(bucketSnapshots), snapshotRecords(history.BranchHistory)); err != nil {
+		return err
+	}
+
+	lifecycles := make(map[string]any, len(history.Lifecycles))
+	for _, lifecycle := range history.Lifecycles {
+		lifecycles[lifecycle.Key] = lifecycle
+	}
+	return syncBucket(tx.Bucket(bucketLifecycles), lifecycles)
+}
+
+// snapshotRecords keys the snapshots, numbering the ones that share a time
+// and commit.
+func snapshotRecords(snapshots []entities.BranchSnapshot) map[string]any {
+	records := make(map[string]any, len(snapshots))
+	for _, snapshot := range snapshots {
+		key := snapshotKey(snapshot)
+		for n := 1; records[string(key)] != nil; n++ {
+			key = append(snapshotKey(snapshot), fmt.Sprintf("#%d", n)...)
+		}
+		records[string(key)] = snapshot
+	}
+	return records
+}
+
+// snapshotKey orders snapshots by time, then commit.
+func snapshotKey(snapshot entities.BranchSnapshot) []byte {
+	key := binary.BigEndian.AppendUint64(nil, uint64(snapshot.Timestamp.UnixNano()))
+	return append(key, snapshot.Commit...)
+}
+
+func syncBucket(bucket *bolt.Bucket, records map[string]any) error {
+	var stale [][]byte
+	err := bucket.ForEach(func(k, _ []byte) error {
+		if _, ok := records[string(k)]; !ok {
+			stale = append(stale, bytes.Clone(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range stale {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	for k, v := range records {
+		if err := putJSONIfChanged(bucket, []byte(k), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func putJSONIfChanged(bucket *bolt.Bucket, key []byte, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(bucket.Get(key), data) {
+		return nil
+	}
+	return bucket.Put(key, data)
+}
+
+type boltStatsTx struct {
+	tx *bolt.Tx
+}
+
+func (b *boltStatsTx) Stats() (*entities.ItemsHistory, error) {
+	data := b.tx.Bucket(bucketMeta).Get(keyStats)
+	if data == nil {
+		return nil, nil
+	}
+
+	var stats entities.ItemsHistory
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal history: %v", err)
+	}
+	return &stats, nil
+}
+
+func (b *boltStatsTx) PutStats(stats *entities.ItemsHistory) error {
+	record := *stats
+	record.SchemaVersion = ItemsSchema
+	record.BranchHistory = nil
+	record.Lifecycles = nil
+	return putJSONIfChanged(b.tx.Bucket(bucketMeta), keyStats, record)
+}
+
+func (b *boltStatsTx) Snapshots() ([]entities.BranchSnapshot, error) {
+	snapshots := []entities.BranchSnapshot{}
+	err := b.tx.Bucket(bucketSnapshots).ForEach(func(_, v []byte) error {
+		var snapshot entities.BranchSnapshot
+		if err := json.Unmarshal(v, &snapshot); err != nil {
+			return fmt.Errorf("failed to unmarshal snapshot: %v", err)
+		}
+		snapshots = append(snapshots, snapshot)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+func (b *boltStatsTx) AppendSnapshot(snapshot entities.BranchSnapshot) error {
+	bucket := b.tx.Bucket(bucketSnapshots)
+	key := snapshotKey(snapshot)
+	for n := 1; bucket.Get(key) != nil; n++ {
+		key = append(snapshotKey(snapshot), fmt.Sprintf("#%d", n)...)
+	}
+	return putJSONIfChanged(bucket, key, snapshot)
+}
+
+func (b *boltStatsTx) ReplaceSnapshots(snapshots []entities.BranchSnapshot) error {
+	return syncBucket(b.tx.Bucket(bucketSnapshots), snapshotRecords(snapshots))
+}
+
+func (b *boltStatsTx) Lifecycles() ([]entities.ItemLifecycle, error) {
+	lifecycles := []entities.ItemLifecycle{}
+	err := b.tx.Bucket(bucketLifecycles).ForEach(func(_, v []byte) error {
+		var lifecycle entities.ItemLifecycle
+		if err := json.Unmarshal(v, &lifecycle); err != nil {
+			return fmt.Errorf("failed to unmarshal lifecycle: %v", err)
+		}
+		lifecycles = append(lifecycles, lifecycle)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lifecycles, nil
+}
+
+func (b *boltStatsTx) PutLifecycle(lifecycle entities.ItemLifecycle) error {
+	return putJSONIfChanged(b.tx.Bucket(bucketLifecycles), []byte(lifecycle.Key), lifecycle)
+}
+
+func (b *boltStatsTx) DeleteLifecycle(key string) error {
+	return b.tx.Bucket(bucketLifecycles).Delete([]byte(key))
+}
+
+type boltNoteTx struct {
+	tx *bolt.Tx
+}
+
+func itob(id int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(id))
+}
+
+func btoi(b []byte) int {
+	return int(binary.BigEndian.Uint64(b))
+}
+
+// folderIndexKey files notes outside of any folder under folder 0.
+func folderIndexKey(folderID *int, noteID int) []byte {
+	id := 0
+	if folderID != nil {
+		id = *folderID
+	}
+	return append(itob(id), itob(noteID)...)
+}
+
+func categoryIndexKey(category string, noteID int) []byte {
+	return append(append([]byte(category), 0), itob(noteID)...)
+}
+
+func (b *boltNoteTx) counter(key []byte) int {
+	data := b.tx.Bucket(bucketMeta).Get(key)
+	if len(data) != 8 {
+		return 1
+	}
+	return btoi(data)
+}
+
+func (b *boltNoteTx) setCounter(key []byte, value int) error {
+	return b.tx.Bucket(bucketMeta).Put(key, itob(value))
+}
+
+func (b *boltNoteTx) Note(id int) (entities.Note, error) {
+	var note entities.Note
+	data := b.tx.Bucket(bucketNotes).Get(itob(id))
+	if data == nil {
+		return note, ErrNotFound
+	}
+	err := json.Unmarshal(data, &note)
+	return note, err
+}
+
+// Notes looks notes up through the folder or category index when the query
+// has one, and scans all notes otherwise.
+func (b *boltNoteTx) Notes(q NoteQuery) ([]entities.Note, error) {
+	var prefix []byte
+	var index *bolt.Bucket
+	switch {
+	case q.FolderID != nil:
+		index, prefix = b.tx.Bucket(bucketNotesByFolder), itob(*q.FolderID)
+	case q.Category != "":
+		index, prefix = b.tx.Bucket(bucketNotesByCat), append([]byte(q.Category), 0)
+	}
+
+	notes := []entities.Note{}
+	add := func(data []byte) error {
+		var note entities.Note
+		if err := json.Unmarshal(data, &note); err != nil {
+			return fmt.Errorf("failed to unmarshal note: %v", err)
+		}
+		if q.Match(note) {
+			notes = append(notes, note)
+		}
+		return nil
+	}
+
+	if index == nil {
+		err := b.tx.Bucket(bucketNotes).ForEach(func(_, v []byte) error {
+			return add(v)
+		})
+		return notes, err
+	}
+
+	byID := b.tx.Bucket(bucketNotes)
+	c := index.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		if data := byID.Get(k[len(prefix):]); data != nil {
+			if err := add(data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return notes, nil
+}
+
+func (b *boltNoteTx) PutNote(note entities.Note) error {
+	if err := b.unindexNote(note.ID); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(note)
+	if err != nil {
+		return fmt.Errorf("failed to marshal note: %v", err)
+	}
+	if err := b.tx.Bucket(bucketNotes).Put(itob(note.ID), data); err != nil {
+		return err
+	}
+
+	if err := b.tx.Bucket(bucketNotesByFolder).Put(folderIndexKey(note.FolderID, note.ID), nil); err != nil {
+		return err
+	}
+	return b.tx.Bucket(bucketNotesByCat).Put(categoryIndexKey(note.Category, note.ID), nil)
+}
+
+func (b *boltNoteTx) DeleteNote(id int) error {
+	if err := b.unindexNote(id); err != nil {
+		return err
+	}
+	return b.tx.Bucket(bucketNotes).Delete(itob(id))
+}
+
+func (b *boltNoteTx) unindexNote(id int) error {
+	old, err := b.Note(id)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := b.tx.Bucket(bucketNotesByFolder).Delete(folderIndexKey(old.FolderID, id)); err != nil {
+		return err
+	}
+	return b.tx.Bucket(bucketNotesByCat).Delete(categoryIndexKey(old.Category, id))
+}
+
+func (b *boltNoteTx) Folder(id int) (entities.Folder, error) {
+	var folder entities.Folder
+	data := b.tx.Bucket(bucketFolders).Get(itob(id))
+	if data == nil {
+		return folder, ErrNotFound
+	}
+	err := json.Unmarshal(data, &folder)
+	return folder, err
+}
+
+func (b *boltNoteTx) Folders() ([]entities.Folder, error) {
+	folders := []entities.Folder{}
+	err := b.tx.Bucket(bucketFolders).ForEach(func(_, v []byte) error {
+		var folder entities.Folder
+		if err := json.Unmarshal(v, &folder); err != nil {
+			return fmt.Errorf("failed to unmarshal folder: %v", err)
+		}
+		folders = append(folders, folder)
+		return nil
+	})
+	return folders, err
+}
+
+func (b *boltNoteTx) PutFolder(folder entities.Folder) error {
+	data, err := json.Marshal(folder)
+	if err != nil {
+		return fmt.Errorf("failed to marshal folder: %v", err)
+	}
+	return b.tx.Bucket(bucketFolders).Put(itob(folder.ID), data)
+}
+
+func (b *boltNoteTx) DeleteFolder(id int) error {
+	return b.tx.Bucket(bucketFolders).Delete(itob(id))
+}
+
+func (b *boltNoteTx) NextID() (int, error) {
+	id := b.counter(keyNextID)
+	return id, b.setCounter(keyNextID, id+1)
+}
+
+func (b *boltNoteTx) AddHistory(entry entities.NoteHistoryEntry) (entities.NoteHistoryEntry, error) {
+	entry.ID = b.counter(keyNextHistoryID)
+	if err := b.setCounter(keyNextHistoryID, entry.ID+1); err != nil {
+		return entry, err
+	}
+	return entry, b.putHistory(entry)
+}
+
+func (b *boltNoteTx) putHistory(entry entities.NoteHistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %v", err)
+	}
+	if err := b.tx.Bucket(bucketHistory).Put(itob(entry.ID), data); err != nil {
+		return err
+	}
+	return b.tx.Bucket(bucketHistoryByNote).Put(append(itob(entry.NoteID), itob(entry.ID)...), nil)
+}
+
+// History uses the note index when the filter names a note.
+func (b *boltNoteTx) History(filter entities.NoteHistoryFilter) ([]entities.NoteHistoryEntry, error) {
+	entries := []entities.NoteHistoryEntry{}
+	add := func(data []byte) error {
+		var entry entities.NoteHistoryEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return fmt.Errorf("failed to unmarshal history entry: %v", err)
+		}
+		if matchHistory(entry, filter) {
+			entries = append(entries, entry)
+		}
+		return nil
+	}
+
+	if filter.NoteID == nil {
+		err := b.tx.Bucket(bucketHistory).ForEach(func(_, v []byte) error {
+			return add(v)
+		})
+		return entries, err
+	}
+
+	byID := b.tx.Bucket(bucketHistory)
+	prefix := itob(*filter.NoteID)
+	c := b.tx.Bucket(bucketHistoryByNote).Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		if data := byID.Get(k[len(prefix):]); data != nil {
+			if err := add(data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return entries, nil
+}
+
+func (b *boltNoteTx) DeleteHistory(id int) error {
+	data := b.tx.Bucket(bucketHistory).Get(itob(id))
+	if data == nil {
+		return nil
+	}
+
+	var entry entities.NoteHistoryEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("failed to unmarshal history entry: %v", err)
+	}
+	if err := b.tx.Bucket(bucketHistoryByNote).Delete(append(itob(entry.NoteID), itob(id)...)); err != nil {
+		return err
+	}
+	return b.tx.Bucket(bucketHistory).Delete(itob(id))
+}
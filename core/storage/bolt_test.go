@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestBoltNotesSurviveReopen(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenBoltStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var folderID, filedID, looseID int
+	err = store.UpdateNotes(func(tx NoteTx) error {
+		var err error
+		if folderID, err = tx.NextID(); err != nil {
+			return err
+		}
+		if err := tx.PutFolder(entities.Folder{ID: folderID, Name: "design"}); err != nil {
+			return err
+		}
+
+		if filedID, err = tx.NextID(); err != nil {
+			return err
+		}
+		if err := tx.PutNote(entities.Note{ID: filedID, Title: "filed", Category: "idea", Tags: []string{"a"}, FolderID: &folderID}); err != nil {
+			return err
+		}
+		if looseID, err = tx.NextID(); err != nil {
+			return err
+		}
+		if err := tx.PutNote(entities.Note{ID: looseID, Title: "loose", Category: "bug", Tags: []string{"a", "b"}}); err != nil {
+			return err
+		}
+
+		_, err = tx.AddHistory(entities.NoteHistoryEntry{NoteID: filedID, Action: entities.ActionCreated, Timestamp: time.Now()})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed transaction leaves nothing behind.
+	err = store.UpdateNotes(func(tx NoteTx) error {
+		if err := tx.PutNote(entities.Note{ID: looseID + 100, Title: "rolled back"}); err != nil {
+			return err
+		}
+		return errors.New("failed")
+	})
+	if err == nil {
+		t.Fatal("expected the error of fn")
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if store, err = OpenBoltStore(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	err = store.ViewNotes(func(tx NoteTx) error {
+		note, err := tx.Note(filedID)
+		if err != nil {
+			return err
+		}
+		if note.Title != "filed" || note.FolderID == nil || *note.FolderID != folderID {
+			t.Errorf("note = %+v, want the filed note in folder %d", note, folderID)
+		}
+		if _, err := tx.Note(looseID + 100); !errors.Is(err, ErrNotFound) {
+			t.Errorf("rolled back note: %v, want ErrNotFound", err)
+		}
+
+		for _, tt := range []struct {
+			query NoteQuery
+			want  string
+		}{
+			{NoteQuery{FolderID: &folderID}, "[filed]"},
+			{NoteQuery{Category: "bug"}, "[loose]"},
+			{NoteQuery{Tag: "a"}, "[filed loose]"},
+			{NoteQuery{Tag: "b"}, "[loose]"},
+		} {
+			notes, err := tx.Notes(tt.query)
+			if err != nil {
+				return err
+			}
+			titles := []string{}
+			for _, note := range notes {
+				titles = append(titles, note.Title)
+			}
+			if fmt.Sprint(titles) != tt.want {
+				t.Errorf("notes of %+v = %v, want %s", tt.query, titles, tt.want)
+			}
+		}
+
+		history, err := tx.History(entities.NoteHistoryFilter{NoteID: &filedID})
+		if err != nil {
+			return err
+		}
+		if len(history) != 1 || history[0].Action != entities.ActionCreated {
+			t.Errorf("history = %+v, want the created entry", history)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving a note out of its folder updates the folder index.
+	err = store.UpdateNotes(func(tx NoteTx) error {
+		note, err := tx.Note(filedID)
+		if err != nil {
+			return err
+		}
+		note.FolderID = nil
+		return tx.PutNote(note)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.ViewNotes(func(tx NoteTx) error {
+		notes, err := tx.Notes(NoteQuery{FolderID: &folderID})
+		if err != nil {
+			return err
+		}
+		if len(notes) != 0 {
+			t.Errorf("folder still lists %d notes", len(notes))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCopyMigratesBetweenBackends(t *testing.T) {
+	source := NewJSONStore(t.TempDir())
+	now := time.Now()
+	err := source.UpdateNotes(func(tx NoteTx) error {
+		id, err := tx.NextID()
+		if err != nil {
+			return err
+		}
+		if err := tx.PutNote(entities.Note{ID: id, Title: "kept", Tags: []string{"x"}}); err != nil {
+			return err
+		}
+		_, err = tx.AddHistory(entities.NoteHistoryEntry{NoteID: id, Action: entities.ActionCreated, Timestamp: now})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = source.SaveStats(&entities.ItemsHistory{
+		GitCommit:     "a",
+		CreatedAt:     now,
+		BranchHistory: []entities.BranchSnapshot{{Commit: "a", Timestamp: now}},
+		Lifecycles:    []entities.ItemLifecycle{{Key: "one"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// json -> bolt -> files, so each backend is read and written once.
+	from := Store(source)
+	for _, backend := range []string{BackendBolt, BackendFiles} {
+		target, err := Open(filepath.Join(t.TempDir(), backend), backend)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer target.Close()
+
+		result, err := Copy(from, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Notes != 1 || result.HistoryEntries != 1 || result.Snapshots != 1 || result.Lifecycles != 1 {
+			t.Errorf("%s: copied %+v", backend, result)
+		}
+
+		notes, err := target.DumpNotes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(notes.Notes) != 1 || notes.Notes[0].Title != "kept" || len(notes.History) != 1 || notes.History[0].NoteID != notes.Notes[0].ID {
+			t.Errorf("%s: notes = %+v", backend, notes)
+		}
+
+		history, err := target.LoadStats()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if history == nil || history.GitCommit != "a" || len(history.BranchHistory) != 1 || len(history.Lifecycles) != 1 {
+			t.Errorf("%s: history = %+v", backend, history)
+		}
+
+		from = target
+	}
+}
+
+func TestBoltRejectsNewerSchema(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenBoltStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := bolt.Open(filepath.Join(dir, "kodo.db"), 0644, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketMeta).Put(keySchemaVersion, itob(BoltSchema+1))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var schemaErr *SchemaError
+	if _, err := OpenBoltStore(dir); !errors.As(err, &schemaErr) {
+		t.Fatalf("opened a newer database: %v", err)
+	}
+}
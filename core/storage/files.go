@@ -0,0 +1,411 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+const (
+	filesNotesDir   = "notes"
+	filesFoldersDir = "folders"
+	filesHistoryDir = "note_history"
+)
+
+// FilesStore keeps every note, folder and note history entry in a file of
+// its own under notes/, folders/ and note_history/, named after its ID.
+// There are no shared counters: IDs come from recordIDs, so notes created
+// on parallel branches merge with plain git. The item history stays in
+// items.json.
+type FilesStore struct {
+	dir   string
+	stats *JSONStore
+	ids   recordIDs
+}
+
+func NewFilesStore(dir string) *FilesStore {
+	return &FilesStore{dir: dir, stats: NewJSONStore(dir)}
+}
+
+func (s *FilesStore) Backend() string {
+	return BackendFiles
+}
+
+func (s *FilesStore) Close() error {
+	return nil
+}
+
+func (s *FilesStore) ViewNotes(fn func(tx NoteTx) error) error {
+	return WithLock(s.dir, false, func() error {
+		return fn(&filesNoteTx{store: s})
+	})
+}
+
+// UpdateNotes collects the writes of fn and applies them once fn returns
+// without an error. Every record is replaced atomically on its own.
+func (s *FilesStore) UpdateNotes(fn func(tx NoteTx) error) error {
+	return WithLock(s.dir, true, func() error {
+		tx := &filesNoteTx{store: s, writes: map[string][]byte{}}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.commit()
+	})
+}
+
+func (s *FilesStore) DumpNotes() (*entities.EnhancedNoteStorage, error) {
+	notes := &entities.EnhancedNoteStorage{SchemaVersion: NotesSchema, NextID: 1, NextHistoryID: 1}
+	err := s.ViewNotes(func(tx NoteTx) error {
+		var err error
+		if notes.Notes, err = tx.Notes(NoteQuery{}); err != nil {
+			return err
+		}
+		if notes.Folders, err = tx.Folders(); err != nil {
+			return err
+		}
+		if notes.History, err = tx.History(entities.NoteHistoryFilter{}); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Counters for stores that still use them.
+	for _, note := range notes.Notes {
+		notes.NextID = max(notes.NextID, note.ID+1)
+	}
+	for _, folder := range notes.Folders {
+		notes.NextID = max(notes.NextID, folder.ID+1)
+	}
+	for _, entry := range notes.History {
+		notes.NextHistoryID = max(notes.NextHistoryID, entry.ID+1)
+	}
+
+	return notes, nil
+}
+
+// RestoreNotes replaces all notes, folders and history entries. Records
+// keep their IDs.
+func (s *FilesStore) RestoreNotes(notes *entities.EnhancedNoteStorage) error {
+	return WithLock(s.dir, true, func() error {
+		tx := &filesNoteTx{store: s, writes: map[string][]byte{}}
+		for _, kind := range []string{filesNotesDir, filesFoldersDir, filesHistoryDir} {
+			ids, err := s.recordIDs(kind)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				tx.writes[s.recordPath(kind, id)] = nil
+			}
+		}
+
+		for _, note := range notes.Notes {
+			if err := tx.put(filesNotesDir, note.ID, note); err != nil {
+				return err
+			}
+		}
+		for _, folder := range notes.Folders {
+			if err := tx.put(filesFoldersDir, folder.ID, folder); err != nil {
+				return err
+			}
+		}
+		for _, entry := range notes.History {
+			if err := tx.put(filesHistoryDir, entry.ID, entry); err != nil {
+				return err
+			}
+		}
+
+		return tx.commit()
+	})
+}
+
+func (s *FilesStore) LoadStats() (*entities.ItemsHistory, error) {
+	return s.stats.LoadStats()
+}
+
+func (s *FilesStore) SaveStats(history *entities.ItemsHistory) error {
+	return s.stats.SaveStats(history)
+}
+
+func (s *FilesStore) UpdateStats(fn func(history *entities.ItemsHistory) error) error {
+	return s.stats.UpdateStats(fn)
+}
+
+func (s *FilesStore) PatchStats(fn func(tx StatsTx) error) error {
+	return s.stats.PatchStats(fn)
+}
+
+// recordPath pads the ID so that file names sort in ID order.
+func (s *FilesStore) recordPath(kind string, id int) string {
+	return filepath.Join(s.dir, kind, fmt.Sprintf("%016d.json", id))
+}
+
+// recordIDs lists the IDs of the records of a kind in ascending order.
+// Temporary files of interrupted writes are skipped.
+func (s *FilesStore) recordIDs(kind string) ([]int, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, kind))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list %s: %v", kind, err)
+	}
+
+	var ids []int
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		id, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	return ids, nil
+}
+
+type filesNoteTx struct {
+	store *FilesStore
+	// writes maps the path of a changed record to its new content, or to
+	// nil when the record is deleted. It is nil in read-only transactions.
+	writes map[string][]byte
+}
+
+func (tx *filesNoteTx) read(kind string, id int, v any) error {
+	path := tx.store.recordPath(kind, id)
+
+	data, pending := tx.writes[path]
+	if !pending {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			if os.IsNotExist(err) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+	}
+	if data == nil {
+		return ErrNotFound
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %v", path, err)
+	}
+	return nil
+}
+
+// ids lists the records of a kind including the pending writes.
+func (tx *filesNoteTx) ids(kind string) ([]int, error) {
+	ids, err := tx.store.recordIDs(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	for path, data := range tx.writes {
+		if data == nil || filepath.Base(filepath.Dir(path)) != kind {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err == nil && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	return ids, nil
+}
+
+func readAll[T any](tx *filesNoteTx, kind string) ([]T, error) {
+	ids, err := tx.ids(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []T{}
+	for _, id := range ids {
+		var record T
+		if err := tx.read(kind, id, &record); err != nil {
+			if err == ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (tx *filesNoteTx) put(kind string, id int, v any) error {
+	if tx.writes == nil {
+		return fmt.Errorf("write in a read-only transaction")
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s record: %v", kind, err)
+	}
+	tx.writes[tx.store.recordPath(kind, id)] = append(data, '\n')
+	return nil
+}
+
+func (tx *filesNoteTx) delete(kind string, id int) error {
+	if tx.writes == nil {
+		return fmt.Errorf("write in a read-only transaction")
+	}
+
+	tx.writes[tx.store.recordPath(kind, id)] = nil
+	return nil
+}
+
+func (tx *filesNoteTx) commit() error {
+	paths := make([]string, 0, len(tx.writes))
+	for path := range tx.writes {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		data := tx.writes[path]
+		if data == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %v", path, err)
+			}
+			continue
+		}
+		if err := WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+func (tx *filesNoteTx) Note(id int) (entities.Note, error) {
+	var note entities.Note
+	err := tx.read(filesNotesDir, id, &note)
+	return note, err
+}
+
+func (tx *filesNoteTx) Notes(q NoteQuery) ([]entities.Note, error) {
+	all, err := readAll[entities.Note](tx, filesNotesDir)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(all, func(note entities.Note) bool {
+		return !q.Match(note)
+	}), nil
+}
+
+func (tx *filesNoteTx) PutNote(note entities.Note) error {
+	return tx.put(filesNotesDir, note.ID, note)
+}
+
+func (tx *filesNoteTx) DeleteNote(id int) error {
+	return tx.delete(filesNotesDir, id)
+}
+
+func (tx *filesNoteTx) Folder(id int) (entities.Folder, error) {
+	var folder entities.Folder
+	err := tx.read(filesFoldersDir, id, &folder)
+	return folder, err
+}
+
+func (tx *filesNoteTx) Folders() ([]entities.Folder, error) {
+	return readAll[entities.Folder](tx, filesFoldersDir)
+}
+
+func (tx *filesNoteTx) PutFolder(folder entities.Folder) error {
+	return tx.put(filesFoldersDir, folder.ID, folder)
+}
+
+func (tx *filesNoteTx) DeleteFolder(id int) error {
+	return tx.delete(filesFoldersDir, id)
+}
+
+// NextID skips the rare ID that is already taken by a note or folder.
+func (tx *filesNoteTx) NextID() (int, error) {
+	for {
+		id := tx.store.ids.next()
+		if _, err := tx.Note(id); err != ErrNotFound {
+			continue
+		}
+		if _, err := tx.Folder(id); err != ErrNotFound {
+			continue
+		}
+		return id, nil
+	}
+}
+
+func (tx *filesNoteTx) AddHistory(entry entities.NoteHistoryEntry) (entities.NoteHistoryEntry, error) {
+	for {
+		entry.ID = tx.store.ids.next()
+		var existing entities.NoteHistoryEntry
+		if err := tx.read(filesHistoryDir, entry.ID, &existing); err == ErrNotFound {
+			break
+		}
+	}
+	return entry, tx.put(filesHistoryDir, entry.ID, entry)
+}
+
+// History orders entries by time first: IDs from different clones are
+// only ordered to the second.
+func (tx *filesNoteTx) History(filter entities.NoteHistoryFilter) ([]entities.NoteHistoryEntry, error) {
+	all, err := readAll[entities.NoteHistoryEntry](tx, filesHistoryDir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := slices.DeleteFunc(all, func(entry entities.NoteHistoryEntry) bool {
+		return !matchHistory(entry, filter)
+	})
+	slices.SortStableFunc(entries, func(a, b entities.NoteHistoryEntry) int {
+		if c := a.Timestamp.Compare(b.Timestamp); c != 0 {
+			return c
+		}
+		return a.ID - b.ID
+	})
+	return entries, nil
+}
+
+func (tx *filesNoteTx) DeleteHistory(id int) error {
+	return tx.delete(filesHistoryDir, id)
+}
+
+// recordEpoch is the start of the seconds in a record ID.
+var recordEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+// recordIDs hands out IDs that need no shared counter, in the spirit of
+// ULIDs: the seconds since recordEpoch above 21 random bits. They stay
+// below 2^53, so the web UI reads them exactly, and they increase within
+// one process.
+type recordIDs struct {
+	mu   sync.Mutex
+	last int
+}
+
+func (g *recordIDs) next() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := int(time.Now().Unix()-recordEpoch)<<21 | rand.IntN(1<<21)
+	if id <= g.last {
+		id = g.last + 1
+	}
+	g.last = id
+
+	return id
+}
@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+func TestRecordIDsIncreaseWithinASecond(t *testing.T) {
+	var ids recordIDs
+
+	const n = 1000
+	seen := make(map[int]bool, n)
+	last := 0
+	for i := 0; i < n; i++ {
+		id := ids.next()
+		if id <= last {
+			t.Fatalf("id %d after %d", id, last)
+		}
+		if id >= 1<<53 {
+			t.Fatalf("id %d does not fit a JavaScript number", id)
+		}
+		seen[id] = true
+		last = id
+	}
+	if len(seen) != n {
+		t.Errorf("handed out %d distinct IDs, want %d", len(seen), n)
+	}
+}
+
+func TestFilesStoreSkipsTakenIDs(t *testing.T) {
+	dir := t.TempDir()
+
+	// Records written by another process, or merged in from a clone, with
+	// IDs ahead of this process's generator.
+	taken := int(time.Now().Unix()-recordEpoch+3600) << 21
+	err := NewFilesStore(dir).UpdateNotes(func(tx NoteTx) error {
+		if err := tx.PutNote(entities.Note{ID: taken, Title: "note"}); err != nil {
+			return err
+		}
+		if err := tx.PutFolder(entities.Folder{ID: taken + 1, Name: "folder"}); err != nil {
+			return err
+		}
+		return tx.(*filesNoteTx).put(filesHistoryDir, taken+2, entities.NoteHistoryEntry{ID: taken + 2, NoteID: taken})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewFilesStore(dir)
+	store.ids.last = taken - 1
+
+	var noteID int
+	var entry entities.NoteHistoryEntry
+	err = store.UpdateNotes(func(tx NoteTx) error {
+		var err error
+		if noteID, err = tx.NextID(); err != nil {
+			return err
+		}
+		if err := tx.PutNote(entities.Note{ID: noteID, Title: "new"}); err != nil {
+			return err
+		}
+
+		store.ids.last = taken + 1
+		entry, err = tx.AddHistory(entities.NoteHistoryEntry{NoteID: noteID, Action: entities.ActionCreated})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if noteID != taken+2 {
+		t.Errorf("note ID = %d, want %d after the taken note and folder IDs", noteID, taken+2)
+	}
+	if entry.ID != taken+3 {
+		t.Errorf("history ID = %d, want %d after the taken entry", entry.ID, taken+3)
+	}
+
+	notes, err := store.DumpNotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes.Notes) != 2 || len(notes.Folders) != 1 || len(notes.History) != 2 {
+		t.Errorf("store kept %d notes, %d folders and %d history entries, want 2, 1 and 2",
+			len(notes.Notes), len(notes.Folders), len(notes.History))
+	}
+}
+
+func TestFilesStoreConcurrentNotes(t *testing.T) {
+	dir := t.TempDir()
+
+	// Separate stores share nothing but the directory, like processes.
+	const writers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store := NewFilesStore(dir)
+			err := store.UpdateNotes(func(tx NoteTx) error {
+				id, err := tx.NextID()
+				if err != nil {
+					return err
+				}
+				return tx.PutNote(entities.Note{ID: id})
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	notes, err := NewFilesStore(dir).DumpNotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes.Notes) != writers {
+		t.Errorf("kept %d of %d notes", len(notes.Notes), writers)
+	}
+}
@@ -0,0 +1,483 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+// JSONStore keeps the notes in notes.json and the item history in
+// items.json. Both files are cached and read again only when another
+// process changed them. Reads hold the directory lock shared, writes
+// exclusive, and files are replaced atomically.
+type JSONStore struct {
+	dir string
+
+	mu         sync.Mutex
+	notes      *entities.EnhancedNoteStorage
+	notesStamp fileStamp
+	stats      *entities.ItemsHistory
+	statsStamp fileStamp
+	statsRead  bool
+}
+
+func NewJSONStore(dir string) *JSONStore {
+	return &JSONStore{dir: dir}
+}
+
+func (s *JSONStore) Backend() string {
+	return BackendJSON
+}
+
+func (s *JSONStore) notesFile() string {
+	return filepath.Join(s.dir, NotesFile)
+}
+
+func (s *JSONStore) statsFile() string {
+	return filepath.Join(s.dir, ItemsFile)
+}
+
+// cachedNotes returns the cached notes, reading the file again when it
+// changed since it was last read or written. The caller holds the lock.
+// The result is shared and must not be modified.
+func (s *JSONStore) cachedNotes() (*entities.EnhancedNoteStorage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stamp, err := stampFile(s.notesFile())
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat notes file: %v", err)
+	}
+	if s.notes != nil && stamp.Same(s.notesStamp) {
+		return s.notes, nil
+	}
+
+	notes, err := s.readNotes()
+	if err != nil {
+		return nil, err
+	}
+	s.notes, s.notesStamp = notes, stamp
+
+	return notes, nil
+}
+
+func (s *JSONStore) readNotes() (*entities.EnhancedNoteStorage, error) {
+	data, err := os.ReadFile(s.notesFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &entities.EnhancedNoteStorage{
+				SchemaVersion: NotesSchema,
+				Notes:         []entities.Note{},
+				Folders:       []entities.Folder{},
+				History:       []entities.NoteHistoryEntry{},
+				NextID:        1,
+				NextHistoryID: 1,
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to read notes file: %v", err)
+	}
+
+	// Files of an older schema, such as one checked out by git, are
+	// migrated in memory and written back in the current one.
+	var notes entities.EnhancedNoteStorage
+	if _, err := Decode(NotesFile, data, &notes); err != nil {
+		return nil, err
+	}
+
+	if notes.Notes == nil {
+		notes.Notes = []entities.Note{}
+	}
+	if notes.Folders == nil {
+		notes.Folders = []entities.Folder{}
+	}
+	if notes.History == nil {
+		notes.History = []entities.NoteHistoryEntry{}
+	}
+
+	return &notes, nil
+}
+
+// writeNotes saves the notes and makes them the cached copy. The caller
+// holds the exclusive lock.
+func (s *JSONStore) writeNotes(notes *entities.EnhancedNoteStorage) error {
+	notes.SchemaVersion = NotesSchema
+	data, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal notes: %v", err)
+	}
+
+	if err := WriteFile(s.notesFile(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write notes file: %v", err)
+	}
+
+	stamp, err := stampFile(s.notesFile())
+	if err != nil {
+		return fmt.Errorf("failed to stat notes file: %v", err)
+	}
+
+	s.mu.Lock()
+	s.notes, s.notesStamp = notes, stamp
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *JSONStore) ViewNotes(fn func(tx NoteTx) error) error {
+	var notes *entities.EnhancedNoteStorage
+	err := WithLock(s.dir, false, func() (err error) {
+		notes, err = s.cachedNotes()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Writers replace the cached notes instead of changing them, so the
+	// view stays consistent without holding the lock.
+	return fn(&jsonNoteTx{notes: notes})
+}
+
+func (s *JSONStore) UpdateNotes(fn func(tx NoteTx) error) error {
+	return WithLock(s.dir, true, func() error {
+		cached, err := s.cachedNotes()
+		if err != nil {
+			return err
+		}
+
+		notes := cloneNotes(cached)
+		if err := fn(&jsonNoteTx{notes: notes}); err != nil {
+			return err
+		}
+		return s.writeNotes(notes)
+	})
+}
+
+func (s *JSONStore) DumpNotes() (*entities.EnhancedNoteStorage, error) {
+	var notes *entities.EnhancedNoteStorage
+	err := WithLock(s.dir, false, func() (err error) {
+		notes, err = s.cachedNotes()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneNotes(notes), nil
+}
+
+func (s *JSONStore) RestoreNotes(notes *entities.EnhancedNoteStorage) error {
+	return WithLock(s.dir, true, func() error {
+		return s.writeNotes(cloneNotes(notes))
+	})
+}
+
+func (s *JSONStore) LoadStats() (*entities.ItemsHistory, error) {
+	var history *entities.ItemsHistory
+	err := WithLock(s.dir, false, func() (err error) {
+		history, err = s.cachedStats()
+		return err
+	})
+	if err != nil || history == nil {
+		return nil, err
+	}
+	return cloneStats(history), nil
+}
+
+func (s *JSONStore) cachedStats() (*entities.ItemsHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stamp, err := stampFile(s.statsFile())
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat history file: %v", err)
+	}
+	if s.statsRead && stamp.Same(s.statsStamp) {
+		return s.stats, nil
+	}
+
+	data, err := os.ReadFile(s.statsFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.stats, s.statsStamp, s.statsRead = nil, stamp, true
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read history file: %v", err)
+	}
+
+	var history entities.ItemsHistory
+	if _, err := Decode(ItemsFile, data, &history); err != nil {
+		return nil, err
+	}
+	s.stats, s.statsStamp, s.statsRead = &history, stamp, true
+
+	return &history, nil
+}
+
+func (s *JSONStore) SaveStats(history *entities.ItemsHistory) error {
+	return WithLock(s.dir, true, func() error {
+		return s.writeStats(history)
+	})
+}
+
+func (s *JSONStore) UpdateStats(fn func(history *entities.ItemsHistory) error) error {
+	return WithLock(s.dir, true, func() error {
+		cached, err := s.cachedStats()
+		if err != nil {
+			return err
+		}
+
+		history := &entities.ItemsHistory{}
+		if cached != nil {
+			history = cloneStats(cached)
+		}
+		if err := fn(history); err != nil {
+			return err
+		}
+		return s.writeStats(history)
+	})
+}
+
+// PatchStats works on a copy of the whole history, which is written back
+// in one piece since items.json is a single file.
+func (s *JSONStore) PatchStats(fn func(tx StatsTx) error) error {
+	return WithLock(s.dir, true, func() error {
+		cached, err := s.cachedStats()
+		if err != nil {
+			return err
+		}
+
+		tx := &jsonStatsTx{history: &entities.ItemsHistory{}, saved: cached != nil}
+		if cached != nil {
+			tx.history = cloneStats(cached)
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if !tx.written {
+			return nil
+		}
+		return s.writeStats(tx.history)
+	})
+}
+
+// writeStats writes the history and caches a copy. The caller holds the
+// lock exclusive.
+func (s *JSONStore) writeStats(history *entities.ItemsHistory) error {
+	history.SchemaVersion = ItemsSchema
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %v", err)
+	}
+
+	if err := WriteFile(s.statsFile(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write history file: %v", err)
+	}
+
+	stamp, err := stampFile(s.statsFile())
+	if err != nil {
+		return fmt.Errorf("failed to stat history file: %v", err)
+	}
+
+	s.mu.Lock()
+	s.stats, s.statsStamp, s.statsRead = cloneStats(history), stamp, true
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *JSONStore) Close() error {
+	return nil
+}
+
+// cloneNotes copies the slices of a note set, which is all a transaction
+// changes.
+func cloneNotes(notes *entities.EnhancedNoteStorage) *entities.EnhancedNoteStorage {
+	clone := *notes
+	clone.Notes = slices.Clone(notes.Notes)
+	clone.Folders = slices.Clone(notes.Folders)
+	clone.History = slices.Clone(notes.History)
+	return &clone
+}
+
+// cloneStats copies the slices callers update in place, so the cached
+// history is not changed behind the store's back.
+func cloneStats(history *entities.ItemsHistory) *entities.ItemsHistory {
+	clone := *history
+	clone.CurrentItems = slices.Clone(history.CurrentItems)
+	clone.BranchHistory = slices.Clone(history.BranchHistory)
+	clone.Lifecycles = slices.Clone(history.Lifecycles)
+	return &clone
+}
+
+type jsonStatsTx struct {
+	history *entities.ItemsHistory
+	saved   bool
+	written bool
+}
+
+func (tx *jsonStatsTx) Stats() (*entities.ItemsHistory, error) {
+	if !tx.saved {
+		return nil, nil
+	}
+	stats := *tx.history
+	stats.BranchHistory = nil
+	stats.Lifecycles = nil
+	return &stats, nil
+}
+
+func (tx *jsonStatsTx) PutStats(stats *entities.ItemsHistory) error {
+	history := *stats
+	history.BranchHistory = tx.history.BranchHistory
+	history.Lifecycles = tx.history.Lifecycles
+	tx.history, tx.saved, tx.written = &history, true, true
+	return nil
+}
+
+func (tx *jsonStatsTx) Snapshots() ([]entities.BranchSnapshot, error) {
+	return slices.Clone(tx.history.BranchHistory), nil
+}
+
+func (tx *jsonStatsTx) AppendSnapshot(snapshot entities.BranchSnapshot) error {
+	tx.history.BranchHistory = append(tx.history.BranchHistory, snapshot)
+	tx.written = true
+	return nil
+}
+
+func (tx *jsonStatsTx) ReplaceSnapshots(snapshots []entities.BranchSnapshot) error {
+	tx.history.BranchHistory = slices.Clone(snapshots)
+	tx.written = true
+	return nil
+}
+
+func (tx *jsonStatsTx) Lifecycles() ([]entities.ItemLifecycle, error) {
+	return slices.Clone(tx.history.Lifecycles), nil
+}
+
+func (tx *jsonStatsTx) PutLifecycle(lifecycle entities.ItemLifecycle) error {
+	tx.written = true
+	for i := range tx.history.Lifecycles {
+		if tx.history.Lifecycles[i].Key == lifecycle.Key {
+			tx.history.Lifecycles[i] = lifecycle
+			return nil
+		}
+	}
+	tx.history.Lifecycles = append(tx.history.Lifecycles, lifecycle)
+	return nil
+}
+
+func (tx *jsonStatsTx) DeleteLifecycle(key string) error {
+	tx.history.Lifecycles = slices.DeleteFunc(tx.history.Lifecycles, func(lifecycle entities.ItemLifecycle) bool {
+		return lifecycle.Key == key
+	})
+	tx.written = true
+	return nil
+}
+
+type jsonNoteTx struct {
+	notes *entities.EnhancedNoteStorage
+}
+
+func (tx *jsonNoteTx) Note(id int) (entities.Note, error) {
+	for _, note := range tx.notes.Notes {
+		if note.ID == id {
+			return note, nil
+		}
+	}
+	return entities.Note{}, ErrNotFound
+}
+
+func (tx *jsonNoteTx) Notes(q NoteQuery) ([]entities.Note, error) {
+	notes := []entities.Note{}
+	for _, note := range tx.notes.Notes {
+		if q.Match(note) {
+			notes = append(notes, note)
+		}
+	}
+	return notes, nil
+}
+
+func (tx *jsonNoteTx) PutNote(note entities.Note) error {
+	for i := range tx.notes.Notes {
+		if tx.notes.Notes[i].ID == note.ID {
+			tx.notes.Notes[i] = note
+			return nil
+		}
+	}
+	tx.notes.Notes = append(tx.notes.Notes, note)
+	return nil
+}
+
+func (tx *jsonNoteTx) DeleteNote(id int) error {
+	tx.notes.Notes = slices.DeleteFunc(tx.notes.Notes, func(note entities.Note) bool {
+		return note.ID == id
+	})
+	return nil
+}
+
+func (tx *jsonNoteTx) Folder(id int) (entities.Folder, error) {
+	for _, folder := range tx.notes.Folders {
+		if folder.ID == id {
+			return folder, nil
+		}
+	}
+	return entities.Folder{}, ErrNotFound
+}
+
+func (tx *jsonNoteTx) Folders() ([]entities.Folder, error) {
+	return slices.Clone(tx.notes.Folders), nil
+}
+
+func (tx *jsonNoteTx) PutFolder(folder entities.Folder) error {
+	for i := range tx.notes.Folders {
+		if tx.notes.Folders[i].ID == folder.ID {
+			tx.notes.Folders[i] = folder
+			return nil
+		}
+	}
+	tx.notes.Folders = append(tx.notes.Folders, folder)
+	return nil
+}
+
+func (tx *jsonNoteTx) DeleteFolder(id int) error {
+	tx.notes.Folders = slices.DeleteFunc(tx.notes.Folders, func(folder entities.Folder) bool {
+		return folder.ID == id
+	})
+	return nil
+}
+
+func (tx *jsonNoteTx) NextID() (int, error) {
+	id := tx.notes.NextID
+	tx.notes.NextID++
+	return id, nil
+}
+
+func (tx *jsonNoteTx) AddHistory(entry entities.NoteHistoryEntry) (entities.NoteHistoryEntry, error) {
+	entry.ID = tx.notes.NextHistoryID
+	tx.notes.NextHistoryID++
+	tx.notes.History = append(tx.notes.History, entry)
+	return entry, nil
+}
+
+func (tx *jsonNoteTx) History(filter entities.NoteHistoryFilter) ([]entities.NoteHistoryEntry, error) {
+	entries := []entities.NoteHistoryEntry{}
+	for _, entry := range tx.notes.History {
+		if matchHistory(entry, filter) {
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b entities.NoteHistoryEntry) int {
+		return a.ID - b.ID
+	})
+	return entries, nil
+}
+
+func (tx *jsonNoteTx) DeleteHistory(id int) error {
+	tx.notes.History = slices.DeleteFunc(tx.notes.History, func(entry entities.NoteHistoryEntry) bool {
+		return entry.ID == id
+	})
+	return nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockFileName = ".lock"
+	lockTimeout  = 10 * time.Second
+	lockRetry    = 25 * time.Millisecond
+)
+
+var ErrLocked = errors.New("the config directory is locked by another kodo process")
+
+// DirLock is an advisory lock on a config directory. It is held on the
+// .lock file in the directory, so every kodo process and every store in
+// this process working on the same directory sees it. Readers take it
+// shared, writers exclusive.
+//
+// The lock is not reentrant: every call opens the lock file again, so code
+// holding it must not take it a second time. Stores take it once per
+// operation and never call each other while holding it.
+type DirLock struct {
+	file *os.File
+}
+
+// LockDir waits for the lock of dir and gives up with ErrLocked after
+// lockTimeout.
+func LockDir(dir string, exclusive bool) (*DirLock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %v", err)
+	}
+	return lockPath(filepath.Join(dir, lockFileName), exclusive)
+}
+
+func lockPath(path string, exclusive bool) (*DirLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %v", err)
+	}
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := lockFile(file, exclusive)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %v", filepath.Base(path), err)
+		}
+		if locked {
+			return &DirLock{file: file}, nil
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, ErrLocked
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+func (l *DirLock) Unlock() error {
+	err := unlockFile(l.file)
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// WithLock runs fn while holding the lock of dir.
+func WithLock(dir string, exclusive bool, fn func() error) error {
+	lock, err := LockDir(dir, exclusive)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return fn()
+}
+
+// WithFileLock runs fn while holding an exclusive lock on the file at path,
+// for read-modify-writes of files kept outside of the store. The lock is
+// taken on .<name>.lock next to the file and does not conflict with the
+// lock of the directory.
+func WithFileLock(path string, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+
+	lock, err := lockPath(filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".lock"), true)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return fn()
+}
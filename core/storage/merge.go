@@ -0,0 +1,568 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+// MergeConflict is a field both sides of a merge changed. The side with the
+// most recent edit wins; the others are kept for review.
+type MergeConflict struct {
+	File   string `json:"file"`
+	Path   string `json:"path"`
+	Base   any    `json:"base"`
+	Ours   any    `json:"ours"`
+	Theirs any    `json:"theirs"`
+	Chosen string `json:"chosen"`
+}
+
+// MergeRenumber is a note, folder or history entry that was added on both
+// sides with the same ID. Theirs gets a new one; references to it inside
+// notes.json move along, references from other files are reported by
+// NoteReferenceConflicts.
+type MergeRenumber struct {
+	File   string `json:"file"`
+	Record string `json:"record"`
+	From   int    `json:"from"`
+	To     int    `json:"to"`
+}
+
+type MergeResult struct {
+	Data       []byte
+	Conflicts  []MergeConflict
+	Renumbered []MergeRenumber
+}
+
+// mergeKeys names the arrays of each file that hold records, by their path
+// without indexes, and how a record is identified. They are merged record by
+// record; other arrays are merged as one value.
+var mergeKeys = map[string]map[string]func(map[string]any) string{
+	NotesFile: {
+		"notes":   fieldKey("id"),
+		"folders": fieldKey("id"),
+		"history": fieldKey("id"),
+	},
+	ItemsFile: {
+		"branch_history": func(r map[string]any) string { return fieldKey("commit")(r) + "@" + fieldKey("timestamp")(r) },
+		"lifecycles":     fieldKey("key"),
+	},
+	SettingsFile: {
+		"kanban_columns":   fieldKey("id"),
+		"boards":           fieldKey("id"),
+		"boards.columns":   fieldKey("id"),
+		"saved_queries":    fieldKey("name"),
+		"automation_rules": fieldKey("id"),
+	},
+}
+
+// scanFields are the fields of items.json that the next scan overwrites
+// from the code, so a conflict in them is not worth reviewing.
+var scanFields = map[string]bool{
+	"project_path":     true,
+	"last_scan_at":     true,
+	"git_branch":       true,
+	"git_commit":       true,
+	"git_commit_short": true,
+	"total_items":      true,
+	"items_by_status":  true,
+	"items_by_type":    true,
+	"items_by_file":    true,
+	"current_items":    true,
+}
+
+// recencyFields tell which side of a record was edited last.
+var recencyFields = []string{"updated_at", "last_seen_at", "last_scan_at"}
+
+// MergeFileName recognizes a data file by its name or, for the temporary
+// files git hands a merge driver, by its content.
+func MergeFileName(path string, data []byte) (string, error) {
+	if name := filepath.Base(path); mergeKeys[name] != nil {
+		return name, nil
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err == nil {
+		switch {
+		case doc["kanban_columns"] != nil:
+			return SettingsFile, nil
+		case doc["current_items"] != nil || doc["branch_history"] != nil:
+			return ItemsFile, nil
+		case doc["notes"] != nil || doc["folders"] != nil:
+			return NotesFile, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not a kodo data file", path)
+}
+
+// Merge does a three-way merge of a data file. Records are matched by ID,
+// fields changed on one side are taken from it, and fields changed on both
+// go to the side edited last. Conflicts in the parts of items.json that
+// every scan rebuilds are resolved without being reported.
+func Merge(file string, base, ours, theirs []byte) (*MergeResult, error) {
+	keys, ok := mergeKeys[file]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a kodo data file", file)
+	}
+
+	var docs [3]map[string]any
+	for i, data := range [][]byte{base, ours, theirs} {
+		doc, err := decodeMergeSide(file, data)
+		if err != nil {
+			return nil, err
+		}
+		docs[i] = doc
+	}
+
+	m := &merger{file: file, keys: keys}
+	result := &MergeResult{}
+	if file == NotesFile {
+		result.Renumbered = renumberNotes(docs[0], docs[1], docs[2])
+	}
+
+	merged := m.merge("", "", docs[0], docs[1], docs[2], isOursNewer(docs[1], docs[2]))
+	for _, conflict := range m.conflicts {
+		if file == ItemsFile && scanFields[topField(conflict.Path)] {
+			continue
+		}
+		result.Conflicts = append(result.Conflicts, conflict)
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode into the file's struct for the same layout kodo writes.
+	typed := schemaValue(file)
+	if _, err := Decode(file, data, typed); err != nil {
+		return nil, err
+	}
+	if result.Data, err = json.MarshalIndent(typed, "", "  "); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// decodeMergeSide migrates one side to the current schema and decodes it
+// generically. A missing side, such as the base of a file added on both
+// branches, is empty.
+func decodeMergeSide(file string, data []byte) (map[string]any, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return map[string]any{}, nil
+	}
+
+	typed := schemaValue(file)
+	if _, err := Decode(file, data, typed); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(typed)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %v", file, err)
+	}
+	return doc, nil
+}
+
+// absent stands for a field or record one side does not have.
+var absent = &struct{}{}
+
+type merger struct {
+	file      string
+	keys      map[string]func(map[string]any) string
+	conflicts []MergeConflict
+}
+
+// merge merges one value. path locates it for conflict reports, schema is
+// the path without record keys.
+func (m *merger) merge(path, schema string, base, ours, theirs any, oursNewer bool) any {
+	switch {
+	case reflect.DeepEqual(ours, theirs):
+		return ours
+	case reflect.DeepEqual(base, ours):
+		return theirs
+	case reflect.DeepEqual(base, theirs):
+		return ours
+	}
+
+	o, oursObject := ours.(map[string]any)
+	t, theirsObject := theirs.(map[string]any)
+	if oursObject && theirsObject {
+		b, _ := base.(map[string]any)
+		return m.mergeObject(path, schema, b, o, t, oursNewer)
+	}
+
+	if key, ok := m.keys[schema]; ok {
+		if merged, ok := m.mergeList(path, schema, key, base, ours, theirs, oursNewer); ok {
+			return merged
+		}
+	}
+
+	// A record deleted on one side and edited on the other is kept.
+	chosen := "ours"
+	if ours == absent || (theirs != absent && !oursNewer) {
+		chosen = "theirs"
+	}
+	m.conflicts = append(m.conflicts, MergeConflict{
+		File:   m.file,
+		Path:   path,
+		Base:   present(base),
+		Ours:   present(ours),
+		Theirs: present(theirs),
+		Chosen: chosen,
+	})
+	if chosen == "ours" {
+		return ours
+	}
+	return theirs
+}
+
+func (m *merger) mergeObject(path, schema string, base, ours, theirs map[string]any, oursNewer bool) any {
+	if newer, ok := recordOursNewer(ours, theirs); ok {
+		oursNewer = newer
+	}
+
+	keys := make([]string, 0, len(ours)+len(theirs))
+	for _, doc := range []map[string]any{base, ours, theirs} {
+		for key := range doc {
+			if !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	slices.Sort(keys)
+
+	result := make(map[string]any, len(keys))
+	for _, key := range keys {
+		b, o, t := lookup(base, key), lookup(ours, key), lookup(theirs, key)
+
+		var value any
+		if slices.Contains(recencyFields, key) && o != absent && t != absent {
+			value = o
+			if !oursNewer {
+				value = t
+			}
+		} else {
+			value = m.merge(joinPath(path, key), joinPath(schema, key), b, o, t, oursNewer)
+		}
+
+		if value != absent {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// mergeList merges arrays of records. Records keep the order of ours, with
+// the records only theirs has appended. It fails when a record has no key
+// or a key is used twice.
+func (m *merger) mergeList(path, schema string, key func(map[string]any) string, base, ours, theirs any, oursNewer bool) (any, bool) {
+	b, _, ok := indexRecords(base, key)
+	if !ok {
+		return nil, false
+	}
+	o, order, ok := indexRecords(ours, key)
+	if !ok {
+		return nil, false
+	}
+	t, theirsOrder, ok := indexRecords(theirs, key)
+	if !ok {
+		return nil, false
+	}
+
+	for _, k := range theirsOrder {
+		if _, ok := o[k]; !ok {
+			order = append(order, k)
+		}
+	}
+
+	result := []any{}
+	for _, k := range order {
+		value := m.merge(fmt.Sprintf("%s[%s]", path, k), schema, lookup(b, k), lookup(o, k), lookup(t, k), oursNewer)
+		if value != absent {
+			result = append(result, value)
+		}
+	}
+	return result, true
+}
+
+func indexRecords(list any, key func(map[string]any) string) (map[string]any, []string, bool) {
+	if list == absent || list == nil {
+		return map[string]any{}, nil, true
+	}
+	items, ok := list.([]any)
+	if !ok {
+		return nil, nil, false
+	}
+
+	index := make(map[string]any, len(items))
+	order := make([]string, 0, len(items))
+	for _, item := range items {
+		record, ok := item.(map[string]any)
+		if !ok {
+			return nil, nil, false
+		}
+		k := key(record)
+		if _, dup := index[k]; dup || k == "" {
+			return nil, nil, false
+		}
+		index[k] = record
+		order = append(order, k)
+	}
+	return index, order, true
+}
+
+// renumberNotes gives the notes, folders and history entries theirs added
+// with an ID ours also added a new ID, and moves the references to them.
+// The counters of both sides are set past every ID so they do not conflict.
+func renumberNotes(base, ours, theirs map[string]any) []MergeRenumber {
+	var renumbered []MergeRenumber
+
+	records := func(doc map[string]any, lists ...string) map[int]any {
+		ids := map[int]any{}
+		for _, list := range lists {
+			items, _ := doc[list].([]any)
+			for _, item := range items {
+				if record, ok := item.(map[string]any); ok {
+					ids[intValue(record["id"])] = record
+				}
+			}
+		}
+		return ids
+	}
+
+	for _, seq := range []struct {
+		lists   []string
+		counter string
+	}{
+		{[]string{"notes", "folders"}, "next_id"},
+		{[]string{"history"}, "next_history_id"},
+	} {
+		b, o, t := records(base, seq.lists...), records(ours, seq.lists...), records(theirs, seq.lists...)
+
+		next := max(intValue(ours[seq.counter]), intValue(theirs[seq.counter]), 1)
+		for _, ids := range []map[int]any{b, o, t} {
+			for id := range ids {
+				next = max(next, id+1)
+			}
+		}
+
+		ids := make([]int, 0, len(t))
+		for id := range t {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+
+		remap := map[int]int{}
+		for _, id := range ids {
+			_, inBase := b[id]
+			ourRecord, inOurs := o[id]
+			if inBase || !inOurs || reflect.DeepEqual(ourRecord, t[id]) {
+				continue
+			}
+			remap[id] = next
+			next++
+		}
+
+		for _, list := range seq.lists {
+			items, _ := theirs[list].([]any)
+			for _, item := range items {
+				record, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				if to, ok := remap[intValue(record["id"])]; ok {
+					renumbered = append(renumbered, MergeRenumber{File: NotesFile, Record: strings.TrimSuffix(list, "s"), From: intValue(record["id"]), To: to})
+					record["id"] = json.Number(fmt.Sprint(to))
+				}
+			}
+		}
+
+		// Move the references of theirs to the renumbered records.
+		refs := map[string][]string{"notes": {"folder_id"}, "folders": {"parent_id"}, "history": {"note_id"}}
+		if seq.counter == "next_id" {
+			for list, fields := range refs {
+				items, _ := theirs[list].([]any)
+				for _, item := range items {
+					record, ok := item.(map[string]any)
+					if !ok {
+						continue
+					}
+					for _, field := range fields {
+						if ref, ok := record[field]; ok && ref != nil {
+							if to, ok := remap[intValue(ref)]; ok {
+								record[field] = json.Number(fmt.Sprint(to))
+							}
+						}
+					}
+				}
+			}
+		}
+
+		ours[seq.counter] = json.Number(fmt.Sprint(next))
+		theirs[seq.counter] = json.Number(fmt.Sprint(next))
+	}
+
+	return renumbered
+}
+
+func isOursNewer(ours, theirs map[string]any) bool {
+	newer, ok := recordOursNewer(ours, theirs)
+	return newer || !ok
+}
+
+// recordOursNewer compares the first recency field that differs between
+// the sides.
+func recordOursNewer(ours, theirs map[string]any) (bool, bool) {
+	for _, field := range recencyFields {
+		o, ook := ours[field].(string)
+		t, tok := theirs[field].(string)
+		if !ook || !tok {
+			continue
+		}
+		ot, oerr := time.Parse(time.RFC3339Nano, o)
+		tt, terr := time.Parse(time.RFC3339Nano, t)
+		if oerr != nil || terr != nil || ot.Equal(tt) {
+			continue
+		}
+		return ot.After(tt), true
+	}
+	return false, false
+}
+
+func fieldKey(field string) func(map[string]any) string {
+	return func(record map[string]any) string {
+		if value, ok := record[field]; ok && value != nil {
+			return fmt.Sprint(value)
+		}
+		return ""
+	}
+}
+
+func lookup(doc map[string]any, key string) any {
+	if value, ok := doc[key]; ok {
+		return value
+	}
+	return absent
+}
+
+func present(value any) any {
+	if value == absent {
+		return nil
+	}
+	return value
+}
+
+// topField returns the top-level field a conflict path starts with.
+func topField(path string) string {
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// The files that refer to notes by ID outside of notes.json.
+const (
+	LinksFile   = "links.json"
+	SprintsFile = "sprints.json"
+)
+
+// NoteReferenceConflicts reports the links or sprints in data that refer to
+// a note renumbered by a merge. Such a reference may be meant for either
+// note, so it is left alone and reported for review.
+func NoteReferenceConflicts(file string, data []byte, renumbered []MergeRenumber) ([]MergeConflict, error) {
+	moved := map[int]int{}
+	for _, r := range renumbered {
+		if r.File == NotesFile && r.Record == "note" {
+			moved[r.From] = r.To
+		}
+	}
+	if len(moved) == 0 || len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var conflicts []MergeConflict
+	report := func(path string, noteID int) {
+		if to, ok := moved[noteID]; ok {
+			conflicts = append(conflicts, MergeConflict{File: file, Path: path, Ours: noteID, Theirs: to, Chosen: "ours"})
+		}
+	}
+
+	switch file {
+	case LinksFile:
+		var links entities.LinkStorage
+		if err := json.Unmarshal(data, &links); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %v", file, err)
+		}
+		for _, link := range links.Links {
+			report(fmt.Sprintf("links[%d].note_id", link.ID), link.NoteID)
+		}
+	case SprintsFile:
+		var sprints entities.SprintStorage
+		if err := json.Unmarshal(data, &sprints); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %v", file, err)
+		}
+		for _, sprint := range sprints.Sprints {
+			for _, noteID := range sprint.NoteIDs {
+				report(fmt.Sprintf("sprints[%d].note_ids", sprint.ID), noteID)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%s does not refer to notes", file)
+	}
+	return conflicts, nil
+}
+
+// MergeResolutionFile collects the conflicts and renumbered records of the
+// merges in a config directory until they are reviewed.
+const MergeResolutionFile = "merge-conflicts.json"
+
+type MergeResolution struct {
+	Conflicts  []MergeConflict `json:"conflicts"`
+	Renumbered []MergeRenumber `json:"renumbered"`
+}
+
+// AppendMergeResolution adds the conflicts and renumbered records of a
+// merge to the resolution file at path.
+func AppendMergeResolution(path string, result *MergeResult) error {
+	resolution := MergeResolution{Conflicts: []MergeConflict{}, Renumbered: []MergeRenumber{}}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &resolution); err != nil {
+			return fmt.Errorf("failed to unmarshal %s: %v", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	resolution.Conflicts = append(resolution.Conflicts, result.Conflicts...)
+	resolution.Renumbered = append(resolution.Renumbered, result.Renumbered...)
+
+	data, err := json.MarshalIndent(resolution, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, data, 0644)
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+func itemsDoc(t *testing.T, total int, removal entities.ItemRemoval) []byte {
+	t.Helper()
+	data, err := json.Marshal(entities.ItemsHistory{
+		SchemaVersion: ItemsSchema,
+		TotalItems:    total,
+		CurrentItems:  []entities.TaskItem{},
+		Lifecycles:    []entities.ItemLifecycle{{Key: "a.go:todo:fix", Removal: removal}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestMergeReportsItemsConflicts(t *testing.T) {
+	base := itemsDoc(t, 1, "")
+	ours := itemsDoc(t, 2, entities.RemovalResolved)
+	theirs := itemsDoc(t, 3, entities.RemovalDiscarded)
+
+	result, err := Merge(ItemsFile, base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Conflicts) != 1 {
+		t.Fatalf("got %d conflicts, want only the lifecycle one: %+v", len(result.Conflicts), result.Conflicts)
+	}
+	conflict := result.Conflicts[0]
+	if conflict.File != ItemsFile || conflict.Path != "lifecycles[a.go:todo:fix].removal" {
+		t.Errorf("got conflict %s in %s", conflict.Path, conflict.File)
+	}
+}
+
+func notesDoc(t *testing.T, notes ...entities.Note) []byte {
+	t.Helper()
+	data, err := json.Marshal(entities.EnhancedNoteStorage{
+		SchemaVersion: NotesSchema,
+		Notes:         notes,
+		Folders:       []entities.Folder{},
+		History:       []entities.NoteHistoryEntry{},
+		NextID:        len(notes) + 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestMergeReportsLinksToRenumberedNotes(t *testing.T) {
+	first := entities.Note{ID: 1, Title: "shared"}
+	base := notesDoc(t, first)
+	ours := notesDoc(t, first, entities.Note{ID: 2, Title: "ours"})
+	theirs := notesDoc(t, first, entities.Note{ID: 2, Title: "theirs"})
+
+	result, err := Merge(NotesFile, base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Renumbered) != 1 || result.Renumbered[0].From != 2 {
+		t.Fatalf("got renumbered %+v, want note 2", result.Renumbered)
+	}
+	to := result.Renumbered[0].To
+
+	links, err := json.Marshal(entities.LinkStorage{Links: []entities.Link{
+		{ID: 1, NoteID: 1, ItemKey: "a.go:TODO:one"},
+		{ID: 2, NoteID: 2, ItemKey: "a.go:TODO:two"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts, err := NoteReferenceConflicts(LinksFile, links, result.Renumbered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("got %d conflicts, want the link to note 2: %+v", len(conflicts), conflicts)
+	}
+	conflict := conflicts[0]
+	if conflict.File != LinksFile || conflict.Path != "links[2].note_id" || conflict.Ours != 2 || conflict.Theirs != to {
+		t.Errorf("got conflict %+v", conflict)
+	}
+}
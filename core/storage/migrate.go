@@ -0,0 +1,43 @@
+package storage
+
+import "fmt"
+
+type CopyResult struct {
+	Notes          int
+	Folders        int
+	HistoryEntries int
+	Snapshots      int
+	Lifecycles     int
+}
+
+// Copy replaces the notes and item history of one store with those of
+// another.
+func Copy(from, to Store) (*CopyResult, error) {
+	notes, err := from.DumpNotes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notes: %v", err)
+	}
+	if err := to.RestoreNotes(notes); err != nil {
+		return nil, fmt.Errorf("failed to write notes: %v", err)
+	}
+
+	result := &CopyResult{
+		Notes:          len(notes.Notes),
+		Folders:        len(notes.Folders),
+		HistoryEntries: len(notes.History),
+	}
+
+	history, err := from.LoadStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read item history: %v", err)
+	}
+	if history != nil {
+		if err := to.SaveStats(history); err != nil {
+			return nil, fmt.Errorf("failed to write item history: %v", err)
+		}
+		result.Snapshots = len(history.BranchHistory)
+		result.Lifecycles = len(history.Lifecycles)
+	}
+
+	return result, nil
+}
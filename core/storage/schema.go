@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+// The current schema version of each file in the config directory. A file
+// without a schema_version field is version 0. Bump a version together with
+// a migration from the previous one.
+const (
+	SettingsSchema = 1
+	NotesSchema    = 1
+	ItemsSchema    = 1
+	// BoltSchema is the version of kodo.db, kept in its meta bucket.
+	BoltSchema = 1
+)
+
+const (
+	SettingsFile = "settings.json"
+	NotesFile    = "notes.json"
+	ItemsFile    = "items.json"
+)
+
+// Migration upgrades a decoded file from one schema version to the next.
+// It works on the generic JSON document, since the old shape of a file
+// does not have to match any struct of this version.
+type Migration struct {
+	File        string
+	From        int
+	Description string
+	Migrate     func(doc map[string]any) error
+}
+
+var migrations = []Migration{
+	{File: SettingsFile, From: 0, Description: "default the storage backend to json", Migrate: migrateSettingsV1},
+	{File: NotesFile, From: 0, Description: "add the note history and its counter", Migrate: migrateNotesV1},
+	{File: ItemsFile, From: 0, Description: "replace a null item list with an empty one", Migrate: migrateItemsV1},
+}
+
+// schemaFiles lists the versioned files with their current version and the
+// struct they are decoded into.
+var schemaFiles = []struct {
+	name    string
+	version int
+	value   func() any
+}{
+	{SettingsFile, SettingsSchema, func() any { return &entities.Settings{} }},
+	{NotesFile, NotesSchema, func() any { return &entities.EnhancedNoteStorage{} }},
+	{ItemsFile, ItemsSchema, func() any { return &entities.ItemsHistory{} }},
+}
+
+// schemaValue returns a new value of the struct a versioned file decodes
+// into, or nil for other files.
+func schemaValue(file string) any {
+	for _, f := range schemaFiles {
+		if f.name == file {
+			return f.value()
+		}
+	}
+	return nil
+}
+
+// SchemaError reports a file written by a newer kodo than this one.
+type SchemaError struct {
+	File      string
+	Version   int
+	Supported int
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s has schema version %d but this kodo supports up to version %d, please upgrade kodo",
+		e.File, e.Version, e.Supported)
+}
+
+// MigrationResult describes a file upgraded by MigrateDir.
+type MigrationResult struct {
+	File   string
+	From   int
+	To     int
+	Backup string
+}
+
+// MigrateDir upgrades every versioned file in dir to the current schema.
+// The original of each upgraded file is kept next to it as
+// <file>.v<version>.bak.
+func MigrateDir(dir string) ([]MigrationResult, error) {
+	var results []MigrationResult
+
+	err := WithLock(dir, true, func() error {
+		for _, file := range schemaFiles {
+			path := filepath.Join(dir, file.name)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return fmt.Errorf("failed to read %s: %v", file.name, err)
+			}
+
+			value := file.value()
+			from, err := Decode(file.name, data, value)
+			if err != nil {
+				return err
+			}
+			if from == file.version {
+				continue
+			}
+
+			backup := fmt.Sprintf("%s.v%d.bak", path, from)
+			if err := WriteFile(backup, data, 0644); err != nil {
+				return fmt.Errorf("failed to back up %s: %v", file.name, err)
+			}
+
+			migrated, err := json.MarshalIndent(value, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal %s: %v", file.name, err)
+			}
+			if err := WriteFile(path, migrated, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %v", file.name, err)
+			}
+
+			results = append(results, MigrationResult{File: file.name, From: from, To: file.version, Backup: backup})
+		}
+		return nil
+	})
+
+	return results, err
+}
+
+// Decode unmarshals a versioned file into v, running the migrations up to
+// the current version first. It returns the version the data was written
+// with and a SchemaError when that is newer than this kodo.
+func Decode(file string, data []byte, v any) (int, error) {
+	current := -1
+	for _, f := range schemaFiles {
+		if f.name == file {
+			current = f.version
+		}
+	}
+	if current < 0 {
+		return 0, fmt.Errorf("%s is not a versioned file", file)
+	}
+
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal %s: %v", file, err)
+	}
+
+	version := header.SchemaVersion
+	if version > current {
+		return version, &SchemaError{File: file, Version: version, Supported: current}
+	}
+	if version == current {
+		if err := json.Unmarshal(data, v); err != nil {
+			return version, fmt.Errorf("failed to unmarshal %s: %v", file, err)
+		}
+		return version, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return version, fmt.Errorf("failed to unmarshal %s: %v", file, err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	for from := version; from < current; from++ {
+		migration, ok := findMigration(file, from)
+		if !ok {
+			return version, fmt.Errorf("no migration of %s from schema version %d", file, from)
+		}
+		if err := migration.Migrate(doc); err != nil {
+			return version, fmt.Errorf("failed to migrate %s from schema version %d: %v", file, from, err)
+		}
+	}
+	doc["schema_version"] = current
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return version, err
+	}
+	if err := json.Unmarshal(migrated, v); err != nil {
+		return version, fmt.Errorf("failed to unmarshal migrated %s: %v", file, err)
+	}
+	return version, nil
+}
+
+func findMigration(file string, from int) (Migration, bool) {
+	for _, m := range migrations {
+		if m.File == file && m.From == from {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
+
+func intValue(v any) int {
+	switch n := v.(type) {
+	case json.Number:
+		i, _ := n.Int64()
+		return int(i)
+	case float64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
+
+func migrateSettingsV1(doc map[string]any) error {
+	if backend, _ := doc["storage"].(string); backend == "" {
+		doc["storage"] = BackendJSON
+	}
+	return nil
+}
+
+// migrateNotesV1 upgrades files of the first note service, which had no
+// history, and files whose counters fell behind their records.
+func migrateNotesV1(doc map[string]any) error {
+	for _, key := range []string{"notes", "folders", "history"} {
+		if _, ok := doc[key].([]any); !ok {
+			doc[key] = []any{}
+		}
+	}
+
+	nextID := 1
+	for _, key := range []string{"notes", "folders"} {
+		for _, record := range doc[key].([]any) {
+			if record, ok := record.(map[string]any); ok {
+				nextID = max(nextID, intValue(record["id"])+1)
+			}
+		}
+	}
+	if intValue(doc["next_id"]) < nextID {
+		doc["next_id"] = nextID
+	}
+
+	nextHistoryID := 1
+	for _, entry := range doc["history"].([]any) {
+		if entry, ok := entry.(map[string]any); ok {
+			nextHistoryID = max(nextHistoryID, intValue(entry["id"])+1)
+		}
+	}
+	if intValue(doc["next_history_id"]) < nextHistoryID {
+		doc["next_history_id"] = nextHistoryID
+	}
+
+	return nil
+}
+
+func migrateItemsV1(doc map[string]any) error {
+	if _, ok := doc["current_items"].([]any); !ok {
+		doc["current_items"] = []any{}
+	}
+	return nil
+}
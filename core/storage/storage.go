@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+const (
+	BackendJSON  = "json"
+	BackendBolt  = "bolt"
+	BackendFiles = "files"
+)
+
+var Backends = []string{BackendJSON, BackendBolt, BackendFiles}
+
+var ErrNotFound = errors.New("record not found")
+
+// Store persists the notes and the item history of a project.
+type Store interface {
+	Backend() string
+
+	// ViewNotes runs fn with a read-only view of the notes.
+	ViewNotes(fn func(tx NoteTx) error) error
+	// UpdateNotes runs fn in a read-write transaction. Nothing is written
+	// when fn returns an error.
+	UpdateNotes(fn func(tx NoteTx) error) error
+
+	// DumpNotes and RestoreNotes move a whole note set between stores.
+	DumpNotes() (*entities.EnhancedNoteStorage, error)
+	RestoreNotes(notes *entities.EnhancedNoteStorage) error
+
+	// LoadStats returns nil without an error when no history was saved yet.
+	LoadStats() (*entities.ItemsHistory, error)
+	SaveStats(history *entities.ItemsHistory) error
+	// UpdateStats runs fn on the history and saves it, with no other
+	// writer in between. fn gets an empty history when none was saved
+	// yet. Nothing is written when fn returns an error.
+	UpdateStats(fn func(history *entities.ItemsHistory) error) error
+	// PatchStats runs fn in a read-write transaction on the records of the
+	// history, for updates that touch a few of them. Nothing is written
+	// when fn returns an error.
+	PatchStats(fn func(tx StatsTx) error) error
+
+	Close() error
+}
+
+type NoteQuery struct {
+	FolderID *int
+	Category string
+	Tag      string
+}
+
+func (q NoteQuery) Match(note entities.Note) bool {
+	if q.FolderID != nil && (note.FolderID == nil || *note.FolderID != *q.FolderID) {
+		return false
+	}
+	if q.Category != "" && note.Category != q.Category {
+		return false
+	}
+	if q.Tag != "" && !slices.Contains(note.Tags, q.Tag) {
+		return false
+	}
+	return true
+}
+
+type NoteTx interface {
+	Note(id int) (entities.Note, error)
+	Notes(q NoteQuery) ([]entities.Note, error)
+	PutNote(note entities.Note) error
+	DeleteNote(id int) error
+
+	Folder(id int) (entities.Folder, error)
+	Folders() ([]entities.Folder, error)
+	PutFolder(folder entities.Folder) error
+	DeleteFolder(id int) error
+
+	// NextID allocates an ID for a new note or folder; both share one
+	// sequence, which is not contiguous in every backend.
+	NextID() (int, error)
+
+	// AddHistory assigns the entry the next history ID and stores it.
+	AddHistory(entry entities.NoteHistoryEntry) (entities.NoteHistoryEntry, error)
+	// History returns the entries matching the filter in the order they
+	// were added. Limit and Offset are left to the caller.
+	History(filter entities.NoteHistoryFilter) ([]entities.NoteHistoryEntry, error)
+	DeleteHistory(id int) error
+}
+
+// StatsTx reads and writes the parts of the item history one at a time.
+type StatsTx interface {
+	// Stats returns the history without its snapshots and lifecycles, or
+	// nil when none was saved yet. PutStats ignores them as well.
+	Stats() (*entities.ItemsHistory, error)
+	PutStats(stats *entities.ItemsHistory) error
+
+	// Snapshots returns the snapshots in time order.
+	Snapshots() ([]entities.BranchSnapshot, error)
+	AppendSnapshot(snapshot entities.BranchSnapshot) error
+	ReplaceSnapshots(snapshots []entities.BranchSnapshot) error
+
+	Lifecycles() ([]entities.ItemLifecycle, error)
+	PutLifecycle(lifecycle entities.ItemLifecycle) error
+	DeleteLifecycle(key string) error
+}
+
+// Open opens the store of a backend in the config directory.
+func Open(dir, backend string) (Store, error) {
+	switch backend {
+	case "", BackendJSON:
+		return NewJSONStore(dir), nil
+	case BackendBolt:
+		return OpenBoltStore(dir)
+	case BackendFiles:
+		return NewFilesStore(dir), nil
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", backend)
+}
+
+func matchHistory(entry entities.NoteHistoryEntry, filter entities.NoteHistoryFilter) bool {
+	if filter.NoteID != nil && entry.NoteID != *filter.NoteID {
+		return false
+	}
+	if filter.Action != nil && entry.Action != *filter.Action {
+		return false
+	}
+	if filter.Author != nil && entry.Author != *filter.Author {
+		return false
+	}
+	if filter.GitBranch != nil && (entry.GitBranch == nil || *entry.GitBranch != *filter.GitBranch) {
+		return false
+	}
+	if filter.Since != nil && entry.Timestamp.Before(*filter.Since) {
+		return false
+	}
+	if filter.Until != nil && entry.Timestamp.After(*filter.Until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prodemmi/kodo/core/entities"
+)
+
+func TestUpdateStatsKeepsConcurrentWrites(t *testing.T) {
+	for _, backend := range Backends {
+		t.Run(backend, func(t *testing.T) {
+			store, err := Open(t.TempDir(), backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+
+			const writers = 10
+			var wg sync.WaitGroup
+			for i := 0; i < writers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					err := store.UpdateStats(func(history *entities.ItemsHistory) error {
+						history.Lifecycles = append(history.Lifecycles, entities.ItemLifecycle{Key: fmt.Sprintf("item-%d", i)})
+						return nil
+					})
+					if err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			history, err := store.LoadStats()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if history == nil {
+				t.Fatal("no history saved")
+			}
+			if len(history.Lifecycles) != writers {
+				t.Fatalf("history kept %d of %d lifecycles", len(history.Lifecycles), writers)
+			}
+		})
+	}
+}
+
+func TestUpdateStatsWritesNothingOnError(t *testing.T) {
+	store := NewJSONStore(t.TempDir())
+
+	err := store.UpdateStats(func(history *entities.ItemsHistory) error {
+		history.TotalItems = 1
+		return fmt.Errorf("failed")
+	})
+	if err == nil {
+		t.Fatal("expected the error of fn")
+	}
+
+	history, err := store.LoadStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history != nil {
+		t.Errorf("history was saved: %+v", history)
+	}
+}
+
+func TestPatchStatsRecords(t *testing.T) {
+	for _, backend := range Backends {
+		t.Run(backend, func(t *testing.T) {
+			store, err := Open(t.TempDir(), backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+
+			now := time.Now()
+			err = store.SaveStats(&entities.ItemsHistory{
+				GitCommit:     "a",
+				CreatedAt:     now,
+				BranchHistory: []entities.BranchSnapshot{{Commit: "a", Timestamp: now}},
+				Lifecycles:    []entities.ItemLifecycle{{Key: "one"}, {Key: "two"}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = store.PatchStats(func(tx StatsTx) error {
+				stats, err := tx.Stats()
+				if err != nil {
+					return err
+				}
+				if stats == nil || stats.GitCommit != "a" || stats.BranchHistory != nil || stats.Lifecycles != nil {
+					t.Errorf("stats = %+v, want the saved stats without records", stats)
+				}
+
+				stats.GitCommit = "b"
+				if err := tx.PutStats(stats); err != nil {
+					return err
+				}
+				if err := tx.AppendSnapshot(entities.BranchSnapshot{Commit: "b", Timestamp: now.Add(time.Second)}); err != nil {
+					return err
+				}
+				if err := tx.PutLifecycle(entities.ItemLifecycle{Key: "one", Title: "changed"}); err != nil {
+					return err
+				}
+				return tx.DeleteLifecycle("two")
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			history, err := store.LoadStats()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if history.GitCommit != "b" {
+				t.Errorf("commit = %q, want b", history.GitCommit)
+			}
+			if len(history.BranchHistory) != 2 || history.BranchHistory[1].Commit != "b" {
+				t.Errorf("snapshots = %+v, want a and b", history.BranchHistory)
+			}
+			if len(history.Lifecycles) != 1 || history.Lifecycles[0].Title != "changed" {
+				t.Errorf("lifecycles = %+v, want only the changed one", history.Lifecycles)
+			}
+		})
+	}
+}
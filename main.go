@@ -2,13 +2,22 @@ package main
 
 import (
 	"embed"
+	"encoding/json"
+	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/prodemmi/kodo/core"
 	"github.com/prodemmi/kodo/core/cli"
 	"github.com/prodemmi/kodo/core/entities"
 	"github.com/prodemmi/kodo/core/handlers"
 	"github.com/prodemmi/kodo/core/services"
+	"github.com/prodemmi/kodo/core/storage"
 	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 )
@@ -26,6 +35,7 @@ func main() {
 	pflag.BoolVarP(&config.Flags.Silent, "silent", "s", config.Flags.Silent, "Silent the logger")
 	showHelp := pflag.BoolP("help", "h", false, "Show help message")
 
+	pflag.CommandLine.SetInterspersed(false)
 	pflag.Parse()
 
 	if *showHelp {
@@ -40,6 +50,39 @@ func main() {
 		logger = services.NewLogger()
 	}
 
+	// The git integration runs inside merges, before anything loads or
+	// migrates the data files.
+	if args := pflag.Args(); len(args) > 0 {
+		switch args[0] {
+		case "merge-driver":
+			if err := runMergeDriver(config, args[1:]); err != nil {
+				logger.Fatal("failed to merge data file", zap.Error(err))
+				os.Exit(1)
+			}
+			return
+		case "install-merge-driver":
+			if err := runInstallMergeDriver(config); err != nil {
+				logger.Fatal("failed to install merge driver", zap.Error(err))
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	// Upgrade data files written by older versions
+	migrated, err := storage.MigrateDir(config.Flags.Config)
+	if err != nil {
+		logger.Fatal("failed to migrate data files", zap.Error(err))
+		os.Exit(1)
+	}
+	for _, m := range migrated {
+		logger.Info("Data file migrated",
+			zap.String("file", m.File),
+			zap.Int("from", m.From),
+			zap.Int("to", m.To),
+			zap.String("backup", m.Backup))
+	}
+
 	// Initialize services
 	settingsService := services.NewSettingsService(config, logger)
 
@@ -49,17 +92,49 @@ func main() {
 		os.Exit(1)
 	}
 
-	noteService := services.NewNoteService(config, logger)
-	historyService := services.NewHistoryService(config, logger)
+	settings, err := settingsService.LoadSettings()
+	if err != nil {
+		logger.Fatal("failed to load settings", zap.Error(err))
+		os.Exit(1)
+	}
+
+	store, err := storage.Open(config.Flags.Config, settings.Storage)
+	if err != nil {
+		logger.Fatal("failed to open storage", zap.Error(err))
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	noteService := services.NewNoteService(config, logger, store)
+	historyService := services.NewHistoryService(config, logger, store)
 	scannerService := services.NewScannerService(config, settingsService, historyService, logger)
-	remoteService := services.NewRemoteManager(logger, settingsService, noteService)
+	remoteService := services.NewRemoteManager(config, logger, settingsService, noteService, scannerService, historyService)
+	commentService := services.NewCommentService(config, logger)
+	linkService := services.NewLinkService(config, logger, noteService)
+	convertService := services.NewConvertService(logger, noteService, scannerService, linkService)
+	sprintService := services.NewSprintService(config, logger, settingsService, historyService, noteService)
+	chartService := services.NewChartService(logger, settingsService, historyService, sprintService)
+	automationService := services.NewAutomationService(config, logger, settingsService, historyService, scannerService)
+
+	noteService.OnDelete(linkService.DeleteNoteLinks)
+
+	scannerService.OnRescan(commentService.Reconcile)
+	scannerService.OnRescan(linkService.Reconcile)
+	scannerService.OnRescan(sprintService.Reconcile)
+	scannerService.OnRescan(automationService.Reconcile)
 
 	// Initialize handlers
 	noteHandler := handlers.NewNoteHandler(logger, noteService, remoteService)
 	historyHandler := handlers.NewHistoryHandler(logger, scannerService, historyService, settingsService)
 	chatHandler := handlers.NewChatHandler(logger)
 	settingsHandler := handlers.NewSettingHandler(logger, settingsService, scannerService)
-	itemHandler := handlers.NewItemHandler(logger, scannerService, historyService, settingsService)
+	itemHandler := handlers.NewItemHandler(logger, scannerService, historyService, settingsService, remoteService)
+	commentHandler := handlers.NewCommentHandler(logger, commentService, scannerService)
+	linkHandler := handlers.NewLinkHandler(logger, linkService, scannerService)
+	convertHandler := handlers.NewConvertHandler(logger, convertService, scannerService)
+	sprintHandler := handlers.NewSprintHandler(logger, sprintService, scannerService)
+	chartHandler := handlers.NewChartHandler(logger, chartService, settingsService, scannerService)
+	automationHandler := handlers.NewAutomationHandler(logger, automationService, scannerService)
 
 	// Prepare history service
 	if err := historyService.Initialize(); err != nil {
@@ -67,6 +142,41 @@ func main() {
 		os.Exit(1)
 	}
 
+	if args := pflag.Args(); len(args) > 0 {
+		switch args[0] {
+		case "backfill":
+			if err := runBackfill(scannerService, args[1:]); err != nil {
+				logger.Fatal("failed to backfill history", zap.Error(err))
+				os.Exit(1)
+			}
+		case "hotspots":
+			if err := runHotspots(scannerService, historyService, settingsService, args[1:]); err != nil {
+				logger.Fatal("failed to build hotspot report", zap.Error(err))
+				os.Exit(1)
+			}
+		case "release-notes":
+			if err := runReleaseNotes(historyService, settingsService, args[1:]); err != nil {
+				logger.Fatal("failed to generate release notes", zap.Error(err))
+				os.Exit(1)
+			}
+		case "export":
+			if err := runExport(scannerService, historyService, settingsService, args[1:]); err != nil {
+				logger.Fatal("failed to export history", zap.Error(err))
+				os.Exit(1)
+			}
+		case "migrate-storage":
+			if err := runMigrateStorage(config, settingsService, store, args[1:]); err != nil {
+				logger.Fatal("failed to migrate storage", zap.Error(err))
+				os.Exit(1)
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+			cli.PrintHelp()
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize and start the server
 	server := core.NewServer(
 		config,
@@ -76,9 +186,311 @@ func main() {
 		chatHandler,
 		settingsHandler,
 		itemHandler,
+		commentHandler,
+		linkHandler,
+		convertHandler,
+		sprintHandler,
+		chartHandler,
+		automationHandler,
 		staticFiles,
 		scannerService,
 	)
 
 	server.Start()
 }
+
+func runBackfill(scannerService *services.ScannerService, args []string) error {
+	flags := pflag.NewFlagSet("backfill", pflag.ContinueOnError)
+
+	var opts services.BackfillOptions
+	var since string
+	flags.StringVarP(&opts.Branch, "branch", "b", "", "Branch to replay (default current branch)")
+	flags.IntVar(&opts.Every, "every", 1, "Scan every n-th commit")
+	flags.IntVar(&opts.Limit, "limit", 0, "Scan at most n evenly spaced commits")
+	flags.StringVar(&since, "since", "", "Only replay commits after this date (YYYY-MM-DD)")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if since != "" {
+		t, err := time.Parse("2006-01-02", since)
+		if err != nil {
+			return fmt.Errorf("invalid --since date %q", since)
+		}
+		opts.Since = t
+	}
+
+	result, err := scannerService.Backfill(opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Backfilled %s: scanned %d of %d commits, %d added, %d updated, %d already tracked\n",
+		result.Branch, result.Scanned, result.Commits, result.Added, result.Updated, result.Skipped)
+
+	return nil
+}
+
+func runHotspots(scannerService *services.ScannerService, historyService *services.HistoryService, settingsService *services.SettingsService, args []string) error {
+	flags := pflag.NewFlagSet("hotspots", pflag.ContinueOnError)
+
+	var opts services.HotspotOptions
+	flags.StringVarP(&opts.BoardID, "board", "b", "", "Board to report on (default board)")
+	flags.IntVar(&opts.Window, "window", 90, "Count commits from the last n days")
+	flags.IntVar(&opts.Limit, "limit", 20, "Show at most n files and directories")
+	flags.IntVar(&opts.Depth, "depth", 0, "Aggregate directories up to n levels deep")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := scannerService.Rescan(); err != nil {
+		return err
+	}
+
+	report, err := historyService.GetHotspots(settingsService, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Hotspots since %s (%d commits)\n", report.Since.Format("2006-01-02"), report.Commits)
+	for _, section := range []struct {
+		title    string
+		hotspots []entities.Hotspot
+	}{{"Files", report.Files}, {"Directories", report.Directories}} {
+		fmt.Printf("\n%s\n", section.title)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  SCORE\tITEMS\tHIGH\tCOMMITS\tLINES\tPATH")
+		for _, h := range section.hotspots {
+			fmt.Fprintf(w, "  %d\t%d\t%d\t%d\t%d\t%s\n", h.Score, h.Items, h.HighPriority, h.Commits, h.LinesChanged, h.Path)
+		}
+		_ = w.Flush()
+	}
+
+	return nil
+}
+
+func runReleaseNotes(historyService *services.HistoryService, settingsService *services.SettingsService, args []string) error {
+	flags := pflag.NewFlagSet("release-notes", pflag.ContinueOnError)
+
+	format := flags.String("format", "markdown", "Output markdown or json")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		return fmt.Errorf("usage: kodo release-notes <from> [to]")
+	}
+	if *format != "markdown" && *format != "json" {
+		return fmt.Errorf("invalid --format %q", *format)
+	}
+
+	notes, err := historyService.ReleaseNotes(settingsService, flags.Arg(0), flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(notes)
+	}
+
+	fmt.Print(services.RenderReleaseNotesMarkdown(notes))
+	return nil
+}
+
+func runExport(scannerService *services.ScannerService, historyService *services.HistoryService, settingsService *services.SettingsService, args []string) error {
+	flags := pflag.NewFlagSet("export", pflag.ContinueOnError)
+
+	var opts services.ExportOptions
+	var output string
+	flags.StringVar(&opts.Format, "format", services.ExportCSV, "Output csv or ndjson")
+	flags.StringVarP(&opts.BoardID, "board", "b", "", "Board to export (default board)")
+	flags.StringVarP(&output, "output", "o", "", "Write to a file instead of stdout")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: kodo export <%s>", strings.Join(services.ExportDatasets, "|"))
+	}
+	opts.Dataset = flags.Arg(0)
+
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	if err := scannerService.Rescan(); err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return historyService.Export(w, settingsService, scannerService.GetItems(), opts)
+}
+
+func runMigrateStorage(config *entities.Config, settingsService *services.SettingsService, store storage.Store, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: kodo migrate-storage <%s>", strings.Join(storage.Backends, "|"))
+	}
+
+	backend := args[0]
+	if backend == store.Backend() {
+		return fmt.Errorf("storage already uses the %s backend", backend)
+	}
+
+	target, err := storage.Open(config.Flags.Config, backend)
+	if err != nil {
+		return err
+	}
+	defer target.Close()
+
+	result, err := storage.Copy(store, target)
+	if err != nil {
+		return err
+	}
+
+	_, err = settingsService.UpdateSettings(func(settings *entities.Settings) error {
+		settings.Storage = backend
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Migrated storage from %s to %s: %d notes, %d folders, %d note history entries, %d snapshots, %d lifecycles\n",
+		store.Backend(), backend, result.Notes, result.Folders, result.HistoryEntries, result.Snapshots, result.Lifecycles)
+
+	return nil
+}
+
+// runMergeDriver merges the versions git passes as %O %A %B and writes the
+// result over %A. The optional %P names the file.
+func runMergeDriver(config *entities.Config, args []string) error {
+	if len(args) < 3 || len(args) > 4 {
+		return fmt.Errorf("usage: kodo merge-driver <base> <ours> <theirs> [path]")
+	}
+
+	var sides [3][]byte
+	for i, path := range args[:3] {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		sides[i] = data
+	}
+
+	path, dir := args[1], config.Flags.Config
+	if len(args) == 4 {
+		path, dir = args[3], filepath.Dir(args[3])
+	}
+
+	file, err := storage.MergeFileName(path, sides[1])
+	if err != nil {
+		return err
+	}
+
+	result, err := storage.Merge(file, sides[0], sides[1], sides[2])
+	if err != nil {
+		return err
+	}
+	if err := storage.WriteFile(args[1], result.Data, 0644); err != nil {
+		return err
+	}
+
+	for _, name := range []string{storage.LinksFile, storage.SprintsFile} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		conflicts, err := storage.NoteReferenceConflicts(name, data, result.Renumbered)
+		if err != nil {
+			return err
+		}
+		result.Conflicts = append(result.Conflicts, conflicts...)
+	}
+
+	if len(result.Conflicts) > 0 || len(result.Renumbered) > 0 {
+		resolution := filepath.Join(dir, storage.MergeResolutionFile)
+		if err := storage.AppendMergeResolution(resolution, result); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "kodo: merged %s with %d conflicting fields taken from the latest edit and %d renumbered records, review %s\n",
+			file, len(result.Conflicts), len(result.Renumbered), resolution)
+	}
+
+	return nil
+}
+
+// runInstallMergeDriver registers the merge driver in the git config of the
+// repository and assigns it to the data files in .gitattributes.
+func runInstallMergeDriver(config *entities.Config) error {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return fmt.Errorf("not inside a git repository")
+	}
+	root := strings.TrimSpace(string(out))
+
+	executable := "kodo"
+	if _, err := exec.LookPath(executable); err != nil {
+		if executable, err = os.Executable(); err != nil {
+			return err
+		}
+	}
+
+	for _, setting := range [][2]string{
+		{"merge.kodo.name", "kodo data file merge"},
+		{"merge.kodo.driver", fmt.Sprintf("%q merge-driver %%O %%A %%B %%P", executable)},
+	} {
+		if out, err := exec.Command("git", "config", setting[0], setting[1]).CombinedOutput(); err != nil {
+			return fmt.Errorf("git config %s: %s", setting[0], strings.TrimSpace(string(out)))
+		}
+	}
+
+	dir, err := filepath.Abs(config.Flags.Config)
+	if err != nil {
+		return err
+	}
+	if dir, err = filepath.Rel(root, dir); err != nil {
+		return err
+	}
+
+	attributesFile := filepath.Join(root, ".gitattributes")
+	attributes, err := os.ReadFile(attributesFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	lines := strings.Split(string(attributes), "\n")
+
+	var added []string
+	for _, file := range []string{storage.NotesFile, storage.ItemsFile, storage.SettingsFile} {
+		line := filepath.ToSlash(filepath.Join(dir, file)) + " merge=kodo"
+		if !slices.Contains(lines, line) {
+			added = append(added, line)
+		}
+	}
+
+	if len(added) > 0 {
+		content := string(attributes)
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += strings.Join(added, "\n") + "\n"
+		if err := os.WriteFile(attributesFile, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Registered the kodo merge driver and added %d entries to %s\n", len(added), attributesFile)
+	return nil
+}
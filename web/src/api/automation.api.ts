@@ -0,0 +1,21 @@
+import { AutomationChange, AutomationReport } from "../types/automation";
+import api from "../utils/api";
+
+export const getAutomationPreview = async (
+  rule?: string
+): Promise<AutomationReport> => {
+  const response = await api.get<AutomationReport>("/automation/preview", {
+    params: rule ? { rule } : {},
+  });
+  return response.data;
+};
+
+export const getAutomationLog = async (
+  limit = 100
+): Promise<AutomationChange[]> => {
+  const response = await api.get<{ log: AutomationChange[]; count: number }>(
+    "/automation/log",
+    { params: { limit } }
+  );
+  return response.data.log;
+};
@@ -0,0 +1,37 @@
+import {
+  BurndownChart,
+  BurnupChart,
+  ChartParams,
+  CumulativeFlowChart,
+  Forecast,
+  ForecastParams,
+} from "../types/chart";
+import api from "../utils/api";
+
+export const getBurndown = async (
+  params: ChartParams
+): Promise<BurndownChart> => {
+  const response = await api.get<BurndownChart>("/charts/burndown", {
+    params,
+  });
+  return response.data;
+};
+
+export const getBurnup = async (params: ChartParams): Promise<BurnupChart> => {
+  const response = await api.get<BurnupChart>("/charts/burnup", { params });
+  return response.data;
+};
+
+export const getCumulativeFlow = async (
+  params: ChartParams
+): Promise<CumulativeFlowChart> => {
+  const response = await api.get<CumulativeFlowChart>("/charts/cfd", {
+    params,
+  });
+  return response.data;
+};
+
+export const getForecast = async (params: ForecastParams): Promise<Forecast> => {
+  const response = await api.get<Forecast>("/charts/forecast", { params });
+  return response.data;
+};
@@ -1,4 +1,17 @@
-import { History, TrendData, RecentChanges, Compare } from "../types/stat";
+import {
+  History,
+  TrendData,
+  RecentChanges,
+  Compare,
+  CompareParams,
+  DebtParams,
+  DebtPoint,
+  DebtReport,
+  HotspotParams,
+  HotspotReport,
+  ReleaseNotes,
+  ReleaseNotesParams,
+} from "../types/stat";
 import api from "../utils/api";
 
 export const loadHistory = async (): Promise<History> => {
@@ -16,11 +29,70 @@ export const loadChanges = async (): Promise<RecentChanges> => {
   return response.data;
 };
 
-export const loadComparison = async (): Promise<Compare> => {
-  const response = await api.get<Compare>(`history/compare`);
+export const loadComparison = async (
+  params: CompareParams = {}
+): Promise<Compare> => {
+  const response = await api.get<Compare>(`history/compare`, { params });
   return response.data;
 };
 
+export const loadHotspots = async (
+  params: HotspotParams = {}
+): Promise<HotspotReport> => {
+  const response = await api.get<HotspotReport>(`history/hotspots`, {
+    params,
+  });
+  return response.data;
+};
+
+export const loadDebt = async (params: DebtParams = {}): Promise<DebtReport> => {
+  const response = await api.get<DebtReport>(`history/debt`, { params });
+  return response.data;
+};
+
+export const loadDebtHistory = async (path?: string): Promise<DebtPoint[]> => {
+  const response = await api.get<{ points: DebtPoint[]; count: number }>(
+    `history/debt/history`,
+    { params: path ? { path } : {} }
+  );
+  return response.data.points;
+};
+
+export const loadReleaseNotes = async (
+  params: ReleaseNotesParams
+): Promise<ReleaseNotes> => {
+  const response = await api.get<ReleaseNotes>(`history/release-notes`, {
+    params,
+  });
+  return response.data;
+};
+
+export const loadReleaseNotesMarkdown = async (
+  params: ReleaseNotesParams
+): Promise<string> => {
+  const response = await api.get<string>(`history/release-notes`, {
+    params: { ...params, format: "markdown" },
+    responseType: "text",
+  });
+  return response.data;
+};
+
+export type ExportDataset =
+  | "items"
+  | "transitions"
+  | "snapshots"
+  | "snapshot_counts";
+
+export const historyExportUrl = (
+  dataset: ExportDataset,
+  format: "csv" | "ndjson",
+  board?: string
+): string => {
+  const params = new URLSearchParams({ dataset, format });
+  if (board) params.set("board", board);
+  return `${api.defaults.baseURL}/history/export?${params.toString()}`;
+};
+
 export const refreshStats = async (): Promise<{ success: boolean }> => {
   const response = await api.post<{ success: boolean }>(`history`);
   return response.data;
@@ -1,11 +1,30 @@
-import { Item, ItemContext, OpenFileResponse } from "../types/item";
+import {
+  AddItemCommentParams,
+  Item,
+  ItemComment,
+  ItemCommentsResponse,
+  ItemContext,
+  ItemIssue,
+  ItemLifecycle,
+  ItemRemoval,
+  ItemSyncResult,
+  OpenFileResponse,
+} from "../types/item";
+import { BoardSummary } from "../types/settings";
 import api from "../utils/api";
 
-export const getItems = async (): Promise<Item[]> => {
-  const response = await api.get<Item[]>("/items");
+export const getItems = async (q?: string, board?: string): Promise<Item[]> => {
+  const response = await api.get<Item[]>("/items", {
+    params: { q: q || undefined, board: board || undefined },
+  });
   return response.data;
 };
 
+export const getBoards = async (): Promise<BoardSummary[]> => {
+  const response = await api.get<{ boards: BoardSummary[] }>("/boards");
+  return response.data.boards;
+};
+
 export const getItem = async (id: number): Promise<Item> => {
   const response = await api.get<Item>(`/items/${id}`);
   return response.data;
@@ -40,3 +59,81 @@ export const openFile = async (
   });
   return response.data;
 };
+
+export const getItemComments = async (
+  key: string
+): Promise<ItemCommentsResponse> => {
+  const response = await api.get<ItemCommentsResponse>("/items/comments", {
+    params: { key },
+  });
+  return response.data;
+};
+
+export const addItemComment = async (
+  params: AddItemCommentParams
+): Promise<ItemComment> => {
+  const response = await api.post<{ comment: ItemComment }>(
+    "/items/comments",
+    params
+  );
+  return response.data.comment;
+};
+
+export const deleteItemComment = async (id: number): Promise<any> => {
+  const response = await api.delete<any>("/items/comments/delete", {
+    data: { id },
+  });
+  return response.data;
+};
+
+export const convertItemToNote = async (params: {
+  item_key: string;
+  folderId?: number;
+  delete_source?: boolean;
+}): Promise<any> => {
+  const response = await api.post<any>("/items/to-note", params);
+  return response.data;
+};
+
+export const convertNoteToItem = async (params: {
+  note_id: number;
+  file: string;
+  line: number;
+  type?: string;
+}): Promise<any> => {
+  const response = await api.post<any>("/notes/to-item", params);
+  return response.data;
+};
+
+export const createItemIssue = async (itemId: number): Promise<ItemIssue> => {
+  const response = await api.post<{ issue: ItemIssue }>("/items/issues", {
+    item_id: itemId,
+  });
+  return response.data.issue;
+};
+
+export const syncItemIssues = async (): Promise<ItemSyncResult> => {
+  const response = await api.post<ItemSyncResult>("/items/issues/sync");
+  return response.data;
+};
+
+export const getArchivedItems = async (
+  board?: string
+): Promise<ItemLifecycle[]> => {
+  const response = await api.get<{ items: ItemLifecycle[] }>(
+    "/items/archived",
+    { params: { board: board || undefined, refresh: true } }
+  );
+  return response.data.items;
+};
+
+export const setItemRemoval = async (params: {
+  key: string;
+  removal: ItemRemoval;
+}): Promise<ItemLifecycle> => {
+  const response = await api.put<{ item: ItemLifecycle }>(
+    "/items/archived",
+    params
+  );
+  return response.data.item;
+};
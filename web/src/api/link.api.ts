@@ -0,0 +1,32 @@
+import { CreateLinkParams, Link, LinksResponse } from "../types/link";
+import api from "../utils/api";
+
+export const getNoteLinks = async (noteId: number): Promise<LinksResponse> => {
+  const response = await api.get<LinksResponse>("/links", {
+    params: { note_id: noteId },
+  });
+  return response.data;
+};
+
+export const getItemLinks = async (itemKey: string): Promise<LinksResponse> => {
+  const response = await api.get<LinksResponse>("/links", {
+    params: { item_key: itemKey },
+  });
+  return response.data;
+};
+
+export const createLink = async (params: CreateLinkParams): Promise<Link> => {
+  const response = await api.post<{ status: string; link: Link }>(
+    "/links",
+    params
+  );
+  return response.data.link;
+};
+
+export const deleteLink = async (id: number) => {
+  const response = await api.delete<{ status: string; message: string }>(
+    "/links/delete",
+    { data: { id } }
+  );
+  return response.data;
+};
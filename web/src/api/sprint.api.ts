@@ -0,0 +1,59 @@
+import {
+  Sprint,
+  SprintAssignParams,
+  SprintParams,
+  SprintReport,
+} from "../types/sprint";
+import api from "../utils/api";
+
+export const getSprints = async (): Promise<Sprint[]> => {
+  const response = await api.get<{ sprints: Sprint[] }>("/sprints");
+  return response.data.sprints;
+};
+
+export const createSprint = async (params: SprintParams): Promise<Sprint> => {
+  const response = await api.post<{ sprint: Sprint }>("/sprints", params);
+  return response.data.sprint;
+};
+
+export const updateSprint = async (params: SprintParams): Promise<Sprint> => {
+  const response = await api.put<{ sprint: Sprint }>(
+    "/sprints/update",
+    params
+  );
+  return response.data.sprint;
+};
+
+export const deleteSprint = async (id: number) => {
+  const response = await api.delete<{ status: string; message: string }>(
+    "/sprints/delete",
+    { data: { id } }
+  );
+  return response.data;
+};
+
+export const assignToSprint = async (
+  params: SprintAssignParams
+): Promise<Sprint> => {
+  const response = await api.post<{ sprint: Sprint }>(
+    "/sprints/assign",
+    params
+  );
+  return response.data.sprint;
+};
+
+export const unassignFromSprint = async (
+  params: SprintAssignParams
+): Promise<Sprint> => {
+  const response = await api.delete<{ sprint: Sprint }>("/sprints/assign", {
+    data: params,
+  });
+  return response.data.sprint;
+};
+
+export const getSprintReport = async (id: number): Promise<SprintReport> => {
+  const response = await api.get<SprintReport>("/sprints/report", {
+    params: { id },
+  });
+  return response.data;
+};
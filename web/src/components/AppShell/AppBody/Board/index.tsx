@@ -24,7 +24,11 @@ import {
   Alert,
   Button,
 } from "@mantine/core";
-import { IconAlertCircle, IconHistory } from "@tabler/icons-react";
+import {
+  IconAlertCircle,
+  IconArchive,
+  IconHistory,
+} from "@tabler/icons-react";
 import { useQueryClient } from "@tanstack/react-query";
 import { Item } from "../../../../types/item";
 import { useItems, useUpdateItem } from "../../../../hooks/use-items";
@@ -34,6 +38,7 @@ import { useSettings } from "../../../../hooks/use-settings";
 
 const DroppableColumn = lazy(() => import("./sections/DroppableColumn"));
 const HistoryDrawer = lazy(() => import("./sections/HistoryDrawer"));
+const ArchivedDrawer = lazy(() => import("./sections/ArchivedDrawer"));
 
 interface Column {
   title: string;
@@ -57,6 +62,7 @@ export default function Board() {
   const [columns, setColumns] = useState<Record<string, Column>>({});
   const [columnOrder, setColumnOrder] = useState<string[]>([]);
   const [openItemHistory, setOpenItemHistory] = useState(false);
+  const [openArchived, setOpenArchived] = useState(false);
   const [error, setError] = useState<string | null>(null);
   const [drawerOpened, setDrawerOpened] = useState(false);
   const [selectedItem, setSelectedItem] = useState<Item | null>(null);
@@ -308,12 +314,21 @@ export default function Board() {
             <Title order={2} mb="md">
               Kanban Board
             </Title>
-            <Button
-              leftSection={<IconHistory size={16} />}
-              onClick={() => setOpenItemHistory(true)}
-            >
-              History
-            </Button>
+            <Group gap="sm">
+              <Button
+                variant="light"
+                leftSection={<IconArchive size={16} />}
+                onClick={() => setOpenArchived(true)}
+              >
+                Archived
+              </Button>
+              <Button
+                leftSection={<IconHistory size={16} />}
+                onClick={() => setOpenItemHistory(true)}
+              >
+                History
+              </Button>
+            </Group>
           </Group>
           <DndContext
             sensors={sensors}
@@ -407,6 +422,12 @@ export default function Board() {
             }}
           />
         </Suspense>
+        <Suspense>
+          <ArchivedDrawer
+            isOpen={openArchived}
+            onClose={() => setOpenArchived(false)}
+          />
+        </Suspense>
       </>
     )
   );
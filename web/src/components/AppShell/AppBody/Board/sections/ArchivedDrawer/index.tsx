@@ -0,0 +1,110 @@
+import {
+  Badge,
+  Card,
+  Drawer,
+  Group,
+  Loader,
+  SegmentedControl,
+  Stack,
+  Text,
+} from "@mantine/core";
+import { useMemo, useState } from "react";
+import {
+  useArchivedItems,
+  useSetItemRemoval,
+} from "../../../../../../hooks/use-items";
+import { ItemRemoval } from "../../../../../../types/item";
+
+interface Props {
+  isOpen: boolean;
+  onClose: () => void;
+}
+
+export default function ArchivedDrawer({ isOpen, onClose }: Props) {
+  const [filter, setFilter] = useState<string>("all");
+  const { data: items, isLoading } = useArchivedItems(isOpen);
+  const { mutate: setRemoval } = useSetItemRemoval();
+
+  const filtered = useMemo(
+    () =>
+      (items || []).filter(
+        (item) => filter === "all" || item.removal === filter
+      ),
+    [items, filter]
+  );
+
+  return (
+    <Drawer
+      opened={isOpen}
+      onClose={onClose}
+      title="Archived Items"
+      position="right"
+      size="lg"
+      styles={{ title: { fontWeight: "bold", fontSize: "1.25rem" } }}
+    >
+      <Stack gap="md">
+        <SegmentedControl
+          value={filter}
+          onChange={setFilter}
+          data={[
+            { label: "All", value: "all" },
+            { label: "Resolved", value: "resolved" },
+            { label: "Discarded", value: "discarded" },
+          ]}
+        />
+
+        {isLoading && <Loader size="sm" />}
+
+        {!isLoading && filtered.length === 0 && (
+          <Text size="sm" c="dimmed">
+            No archived items
+          </Text>
+        )}
+
+        {filtered.map((item) => (
+          <Card key={item.key} withBorder p="sm">
+            <Group justify="space-between" mb={4}>
+              <Text fw={600} size="sm">
+                {item.type}: {item.title}
+              </Text>
+              <SegmentedControl
+                size="xs"
+                value={item.removal || "discarded"}
+                onChange={(removal) =>
+                  setRemoval({ key: item.key, removal: removal as ItemRemoval })
+                }
+                data={[
+                  { label: "Resolved", value: "resolved" },
+                  { label: "Discarded", value: "discarded" },
+                ]}
+              />
+            </Group>
+            <Text size="xs" c="dimmed">
+              {item.file}:{item.line}
+            </Text>
+            <Group gap="xs" mt={6}>
+              <Badge size="xs" variant="light">
+                first seen {new Date(item.first_seen_at).toLocaleDateString()}
+              </Badge>
+              {item.removed_at && (
+                <Badge size="xs" variant="light" color="gray">
+                  removed {new Date(item.removed_at).toLocaleDateString()}
+                </Badge>
+              )}
+              {item.removed_commit && (
+                <Badge size="xs" variant="outline" color="gray">
+                  {item.removed_commit.slice(0, 7)}
+                </Badge>
+              )}
+            </Group>
+            {item.removed_message && (
+              <Text size="xs" mt={4}>
+                {item.removed_message}
+              </Text>
+            )}
+          </Card>
+        ))}
+      </Stack>
+    </Drawer>
+  );
+}
@@ -0,0 +1,164 @@
+import {
+  Card,
+  Group,
+  Progress,
+  ScrollArea,
+  Select,
+  Stack,
+  Table,
+  Text,
+  Title,
+} from "@mantine/core";
+import { useMemo, useState } from "react";
+import {
+  useBurndown,
+  useCumulativeFlow,
+  useForecast,
+} from "../../../../../../hooks/use-charts";
+import { useSprints } from "../../../../../../hooks/use-sprints";
+
+interface Props {
+  enabled: boolean;
+}
+
+export default function ChartsPanel({ enabled }: Props) {
+  const [sprint, setSprint] = useState<string | null>(null);
+  const { data: sprints } = useSprints();
+
+  const params = useMemo(
+    () => (sprint ? { sprint: Number(sprint) } : {}),
+    [sprint]
+  );
+  const { data: burndown } = useBurndown(enabled, params);
+  const { data: flow } = useCumulativeFlow(enabled, params);
+  const { data: forecast } = useForecast(enabled);
+
+  const maxScope = Math.max(1, ...(burndown?.points || []).map((p) => p.scope));
+  const maxFlow = Math.max(
+    1,
+    ...(flow?.points || []).map((point) =>
+      (flow?.columns || []).reduce((sum, col) => sum + (point[col.id] || 0), 0)
+    )
+  );
+
+  return (
+    <Stack>
+      <Select
+        placeholder="Last 30 days"
+        clearable
+        value={sprint}
+        onChange={setSprint}
+        data={(sprints || []).map((s) => ({
+          value: String(s.id),
+          label: s.name,
+        }))}
+        w={240}
+      />
+
+      <Card withBorder>
+        <Title order={3} mb="md">
+          Burndown
+        </Title>
+        <ScrollArea>
+          <Table>
+            <Table.Thead>
+              <Table.Tr>
+                <Table.Th>Date</Table.Th>
+                <Table.Th>Remaining</Table.Th>
+                <Table.Th>Ideal</Table.Th>
+                <Table.Th>Scope</Table.Th>
+                <Table.Th w="40%">Remaining vs Scope</Table.Th>
+              </Table.Tr>
+            </Table.Thead>
+            <Table.Tbody>
+              {(burndown?.points || []).map((point) => (
+                <Table.Tr key={point.date}>
+                  <Table.Td>{point.date}</Table.Td>
+                  <Table.Td>{point.remaining}</Table.Td>
+                  <Table.Td>{point.ideal.toFixed(1)}</Table.Td>
+                  <Table.Td>{point.scope}</Table.Td>
+                  <Table.Td>
+                    <Progress.Root size="md">
+                      <Progress.Section
+                        value={(point.remaining / maxScope) * 100}
+                        color="orange"
+                      />
+                      <Progress.Section
+                        value={(point.completed / maxScope) * 100}
+                        color="green"
+                      />
+                    </Progress.Root>
+                  </Table.Td>
+                </Table.Tr>
+              ))}
+            </Table.Tbody>
+          </Table>
+        </ScrollArea>
+      </Card>
+
+      <Card withBorder>
+        <Group justify="space-between" mb="md">
+          <Title order={3}>Forecast</Title>
+          {forecast && (
+            <Text size="xs" c="dimmed">
+              {forecast.remaining} remaining ·{" "}
+              {forecast.average_throughput.toFixed(2)} done/day over{" "}
+              {forecast.window_days} days
+            </Text>
+          )}
+        </Group>
+        {forecast?.message ? (
+          <Text size="sm" c="dimmed">
+            {forecast.message}
+          </Text>
+        ) : (
+          <Group gap="xl">
+            {(forecast?.completion || []).map((p) => (
+              <Stack key={p.percentile} gap={0}>
+                <Text size="xs" c="dimmed">
+                  {p.percentile}% confidence
+                </Text>
+                <Text fw={600}>
+                  {new Date(p.date).toLocaleDateString()} ({p.days}d)
+                </Text>
+              </Stack>
+            ))}
+          </Group>
+        )}
+      </Card>
+
+      <Card withBorder>
+        <Group justify="space-between" mb="md">
+          <Title order={3}>Cumulative Flow</Title>
+          <Group gap="xs">
+            {(flow?.columns || []).map((col) => (
+              <Text key={col.id} size="xs" c={col.color}>
+                ■ {col.name}
+              </Text>
+            ))}
+          </Group>
+        </Group>
+        <Stack gap={4}>
+          {(flow?.points || []).map((point) => {
+            return (
+              <Group key={point.date} gap="sm" wrap="nowrap">
+                <Text size="xs" w={80}>
+                  {point.date}
+                </Text>
+                <Progress.Root size="lg" style={{ flex: 1 }}>
+                  {(flow?.columns || []).map((col) => (
+                    <Progress.Section
+                      key={col.id}
+                      value={((point[col.id] || 0) / maxFlow) * 100}
+                      color={col.color}
+                    />
+                  ))}
+                </Progress.Root>
+              </Group>
+            );
+          })}
+        </Stack>
+      </Card>
+    </Stack>
+  );
+}
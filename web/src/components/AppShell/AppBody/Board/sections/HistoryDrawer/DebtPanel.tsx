@@ -0,0 +1,130 @@
+import {
+  Alert,
+  Badge,
+  Card,
+  Group,
+  NumberInput,
+  Table,
+  Text,
+  Title,
+} from "@mantine/core";
+import { IconAlertTriangle } from "@tabler/icons-react";
+import { useMemo, useState } from "react";
+import { useDebt, useDebtHistory } from "../../../../../../hooks/use-history";
+
+interface Props {
+  enabled: boolean;
+}
+
+export default function DebtPanel({ enabled }: Props) {
+  const [depth, setDepth] = useState<number>(0);
+  const [selected, setSelected] = useState<string | undefined>();
+
+  const params = useMemo(() => ({ depth }), [depth]);
+  const { data: report } = useDebt(enabled, params);
+  const { data: points } = useDebtHistory(enabled, selected);
+
+  return (
+    <>
+      <Group mb="md" align="flex-end">
+        <NumberInput
+          label="Directory depth"
+          description="0 keeps every level"
+          min={0}
+          value={depth}
+          onChange={(value) => setDepth(Number(value) || 0)}
+          w={160}
+        />
+        {report && (
+          <Text size="sm" c="dimmed">
+            {report.total.toFixed(1)} debt points, compared with{" "}
+            {new Date(report.since).toLocaleDateString()}
+          </Text>
+        )}
+      </Group>
+
+      {(report?.regressions || []).length > 0 && (
+        <Alert
+          mb="md"
+          color="red"
+          icon={<IconAlertTriangle size={16} />}
+          title="Debt regressions"
+        >
+          {report!.regressions.map((r) => (
+            <Text key={r.path} size="sm">
+              {r.path} went from {r.previous.toFixed(1)} to{" "}
+              {r.score.toFixed(1)} debt points
+            </Text>
+          ))}
+        </Alert>
+      )}
+
+      <Card withBorder mb="md">
+        <Title order={3} mb="md">
+          Debt by Directory
+        </Title>
+        <Table highlightOnHover>
+          <Table.Thead>
+            <Table.Tr>
+              <Table.Th>Directory</Table.Th>
+              <Table.Th>Items</Table.Th>
+              <Table.Th>Score</Table.Th>
+              <Table.Th>Change</Table.Th>
+            </Table.Tr>
+          </Table.Thead>
+          <Table.Tbody>
+            {(report?.directories || []).map((dir) => (
+              <Table.Tr
+                key={dir.path}
+                onClick={() => setSelected(dir.path)}
+                style={{ cursor: "pointer" }}
+              >
+                <Table.Td>
+                  <Text
+                    size="sm"
+                    ff="monospace"
+                    pl={(dir.path.split("/").length - 1) * 12}
+                  >
+                    {dir.path}
+                  </Text>
+                </Table.Td>
+                <Table.Td>{dir.items}</Table.Td>
+                <Table.Td>{dir.score.toFixed(1)}</Table.Td>
+                <Table.Td>
+                  <Badge
+                    variant="light"
+                    color={dir.delta > 0 ? "red" : dir.delta < 0 ? "green" : "gray"}
+                  >
+                    {dir.delta > 0 ? "+" : ""}
+                    {dir.delta.toFixed(1)}
+                  </Badge>
+                </Table.Td>
+              </Table.Tr>
+            ))}
+          </Table.Tbody>
+        </Table>
+      </Card>
+
+      <Card withBorder>
+        <Title order={3} mb="md">
+          History: {selected || "whole project"}
+        </Title>
+        <Table>
+          <Table.Tbody>
+            {(points || []).map((point) => (
+              <Table.Tr key={point.timestamp}>
+                <Table.Td>{new Date(point.timestamp).toLocaleString()}</Table.Td>
+                <Table.Td>
+                  <Text size="sm" ff="monospace">
+                    {point.commit}
+                  </Text>
+                </Table.Td>
+                <Table.Td>{point.score.toFixed(1)}</Table.Td>
+              </Table.Tr>
+            ))}
+          </Table.Tbody>
+        </Table>
+      </Card>
+    </>
+  );
+}
@@ -0,0 +1,85 @@
+import { Card, NumberInput, Group, Table, Text, Title } from "@mantine/core";
+import { useMemo, useState } from "react";
+import { useHotspots } from "../../../../../../hooks/use-history";
+import { Hotspot } from "../../../../../../types/stat";
+
+interface Props {
+  enabled: boolean;
+}
+
+function HotspotTable({ title, hotspots }: { title: string; hotspots: Hotspot[] }) {
+  return (
+    <Card withBorder>
+      <Title order={3} mb="md">
+        {title}
+      </Title>
+      <Table>
+        <Table.Thead>
+          <Table.Tr>
+            <Table.Th>Path</Table.Th>
+            <Table.Th>Score</Table.Th>
+            <Table.Th>Items</Table.Th>
+            <Table.Th>High</Table.Th>
+            <Table.Th>Commits</Table.Th>
+            <Table.Th>Lines</Table.Th>
+          </Table.Tr>
+        </Table.Thead>
+        <Table.Tbody>
+          {hotspots.map((hotspot) => (
+            <Table.Tr key={hotspot.path}>
+              <Table.Td>
+                <Text size="sm" ff="monospace">
+                  {hotspot.path}
+                </Text>
+              </Table.Td>
+              <Table.Td>{hotspot.score}</Table.Td>
+              <Table.Td>{hotspot.items}</Table.Td>
+              <Table.Td>{hotspot.high_priority}</Table.Td>
+              <Table.Td>{hotspot.commits}</Table.Td>
+              <Table.Td>{hotspot.lines_changed}</Table.Td>
+            </Table.Tr>
+          ))}
+        </Table.Tbody>
+      </Table>
+    </Card>
+  );
+}
+
+export default function HotspotsPanel({ enabled }: Props) {
+  const [window, setWindow] = useState<number>(90);
+  const [depth, setDepth] = useState<number>(0);
+
+  const params = useMemo(() => ({ window, depth }), [window, depth]);
+  const { data: report } = useHotspots(enabled, params);
+
+  return (
+    <>
+      <Group mb="md" align="flex-end">
+        <NumberInput
+          label="Window (days)"
+          min={1}
+          value={window}
+          onChange={(value) => setWindow(Number(value) || 90)}
+          w={160}
+        />
+        <NumberInput
+          label="Directory depth"
+          description="0 keeps every level"
+          min={0}
+          value={depth}
+          onChange={(value) => setDepth(Number(value) || 0)}
+          w={160}
+        />
+        {report && (
+          <Text size="sm" c="dimmed">
+            {report.commits} commits since{" "}
+            {new Date(report.since).toLocaleDateString()}
+          </Text>
+        )}
+      </Group>
+
+      <HotspotTable title="Files" hotspots={report?.files || []} />
+      <HotspotTable title="Directories" hotspots={report?.directories || []} />
+    </>
+  );
+}
@@ -23,6 +23,7 @@ import {
   LoadingOverlay,
   Drawer,
   DrawerBody,
+  Menu,
 } from "@mantine/core";
 import {
   IconHistory,
@@ -36,6 +37,10 @@ import {
   IconTrash,
   IconChartLine,
   IconCalendar,
+  IconChartArea,
+  IconFlame,
+  IconScale,
+  IconDownload,
 } from "@tabler/icons-react";
 import {
   useChanges,
@@ -45,7 +50,14 @@ import {
   useRefreshStats,
   useTrends,
 } from "../../../../../../hooks/use-history";
+import {
+  ExportDataset,
+  historyExportUrl,
+} from "../../../../../../api/history.api";
 import { RoleGuard } from "../../../../../Investor";
+import ChartsPanel from "./ChartsPanel";
+import HotspotsPanel from "./HotspotsPanel";
+import DebtPanel from "./DebtPanel";
 
 interface Props {
   isOpen: boolean;
@@ -127,6 +139,13 @@ export default function HistoryDrawer({ isOpen, onClose }: Props) {
       .map((branch) => ({ value: branch, label: branch }));
   };
 
+  const exportDatasets: { value: ExportDataset; label: string }[] = [
+    { value: "items", label: "Current items" },
+    { value: "transitions", label: "Status transitions" },
+    { value: "snapshots", label: "Snapshots" },
+    { value: "snapshot_counts", label: "Snapshot counts" },
+  ];
+
   const title = useMemo(() => {
     return (
       <Group justify="space-between" w="100%" pr="sm">
@@ -139,6 +158,36 @@ export default function HistoryDrawer({ isOpen, onClose }: Props) {
 
         <RoleGuard.Consumer>
           <Group>
+            <Menu position="bottom-end">
+              <Menu.Target>
+                <Button leftSection={<IconDownload size={16} />} variant="light">
+                  Export
+                </Button>
+              </Menu.Target>
+              <Menu.Dropdown>
+                {exportDatasets.map((dataset) => (
+                  <Menu.Item key={dataset.value} closeMenuOnClick={false}>
+                    <Group justify="space-between" gap="md">
+                      <Text size="sm">{dataset.label}</Text>
+                      <Group gap={4}>
+                        {(["csv", "ndjson"] as const).map((format) => (
+                          <Button
+                            key={format}
+                            component="a"
+                            href={historyExportUrl(dataset.value, format)}
+                            download
+                            size="compact-xs"
+                            variant="subtle"
+                          >
+                            {format.toUpperCase()}
+                          </Button>
+                        ))}
+                      </Group>
+                    </Group>
+                  </Menu.Item>
+                ))}
+              </Menu.Dropdown>
+            </Menu>
             <Button
               leftSection={<IconRefresh size={16} />}
               onClick={() => refreshStats()}
@@ -313,6 +362,21 @@ export default function HistoryDrawer({ isOpen, onClose }: Props) {
                   >
                     Comparison
                   </Tabs.Tab>
+                  <Tabs.Tab
+                    value="charts"
+                    leftSection={<IconChartArea size={16} />}
+                  >
+                    Charts
+                  </Tabs.Tab>
+                  <Tabs.Tab
+                    value="hotspots"
+                    leftSection={<IconFlame size={16} />}
+                  >
+                    Hotspots
+                  </Tabs.Tab>
+                  <Tabs.Tab value="debt" leftSection={<IconScale size={16} />}>
+                    Debt
+                  </Tabs.Tab>
                 </Tabs.List>
 
                 <Tabs.Panel value="timeline" pt="md">
@@ -1001,6 +1065,18 @@ export default function HistoryDrawer({ isOpen, onClose }: Props) {
                     </Alert>
                   )}
                 </Tabs.Panel>
+
+                <Tabs.Panel value="charts" pt="xl">
+                  <ChartsPanel enabled={activeTab === "charts"} />
+                </Tabs.Panel>
+
+                <Tabs.Panel value="hotspots" pt="xl">
+                  <HotspotsPanel enabled={activeTab === "hotspots"} />
+                </Tabs.Panel>
+
+                <Tabs.Panel value="debt" pt="xl">
+                  <DebtPanel enabled={activeTab === "debt"} />
+                </Tabs.Panel>
               </Tabs>
             </>
           )}
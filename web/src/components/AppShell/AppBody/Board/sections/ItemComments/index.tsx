@@ -0,0 +1,131 @@
+import {
+  ActionIcon,
+  Button,
+  Card,
+  Group,
+  Loader,
+  Stack,
+  Text,
+  Textarea,
+} from "@mantine/core";
+import { IconMessageCircle, IconTrash } from "@tabler/icons-react";
+import { useMemo, useState } from "react";
+import { Item, ItemComment } from "../../../../../../types/item";
+import {
+  useAddItemComment,
+  useDeleteItemComment,
+  useItemComments,
+} from "../../../../../../hooks/use-items";
+
+type Props = {
+  item: Item;
+};
+
+export default function ItemComments({ item }: Props) {
+  const { data, isLoading } = useItemComments(item.key);
+  const { mutate: addComment, isPending } = useAddItemComment();
+  const { mutate: deleteComment } = useDeleteItemComment(item.key);
+
+  const [body, setBody] = useState("");
+  const [replyTo, setReplyTo] = useState<ItemComment | null>(null);
+
+  const childrenByParent = useMemo(() => {
+    const map = new Map<number | undefined, ItemComment[]>();
+    (data?.thread.comments || []).forEach((comment) => {
+      const list = map.get(comment.parent_id) || [];
+      list.push(comment);
+      map.set(comment.parent_id, list);
+    });
+    return map;
+  }, [data]);
+
+  const submit = () => {
+    if (!body.trim()) return;
+    addComment(
+      { item_key: item.key, parent_id: replyTo?.id, body },
+      {
+        onSuccess: () => {
+          setBody("");
+          setReplyTo(null);
+        },
+      }
+    );
+  };
+
+  const renderComments = (parentId: number | undefined, depth: number) =>
+    (childrenByParent.get(parentId) || []).map((comment) => (
+      <Stack key={comment.id} gap={4} pl={depth * 16}>
+        <Group justify="space-between">
+          <Text size="xs" c="dimmed">
+            {comment.author} · {new Date(comment.created_at).toLocaleString()}
+          </Text>
+          <Group gap={4}>
+            <Button
+              size="compact-xs"
+              variant="subtle"
+              onClick={() => setReplyTo(comment)}
+            >
+              Reply
+            </Button>
+            <ActionIcon
+              size="sm"
+              variant="subtle"
+              color="red"
+              onClick={() => deleteComment(comment.id)}
+            >
+              <IconTrash size={12} />
+            </ActionIcon>
+          </Group>
+        </Group>
+        <Text size="sm" styles={{ root: { whiteSpace: "break-spaces" } }}>
+          {comment.body}
+        </Text>
+        {renderComments(comment.id, depth + 1)}
+      </Stack>
+    ));
+
+  return (
+    <Card withBorder p="md">
+      <Group gap="sm" mb="sm">
+        <IconMessageCircle size={16} color="#495057" />
+        <Text size="sm" fw={600}>
+          Discussion ({data?.count || 0})
+        </Text>
+      </Group>
+
+      {isLoading && <Loader size="sm" />}
+
+      <Stack gap="sm">
+        {renderComments(undefined, 0)}
+
+        {replyTo && (
+          <Group justify="space-between">
+            <Text size="xs" c="dimmed">
+              Replying to {replyTo.author}
+            </Text>
+            <Button
+              size="compact-xs"
+              variant="subtle"
+              onClick={() => setReplyTo(null)}
+            >
+              Cancel
+            </Button>
+          </Group>
+        )}
+
+        <Textarea
+          placeholder="Write a comment..."
+          autosize
+          minRows={2}
+          value={body}
+          onChange={(e) => setBody(e.currentTarget.value)}
+        />
+        <Group justify="flex-end">
+          <Button size="xs" loading={isPending} onClick={submit}>
+            Comment
+          </Button>
+        </Group>
+      </Stack>
+    </Card>
+  );
+}
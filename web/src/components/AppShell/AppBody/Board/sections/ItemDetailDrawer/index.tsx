@@ -15,8 +15,14 @@ import {
   createShikiAdapter,
 } from "@mantine/code-highlight";
 import { Item } from "../../../../../../types/item";
-import { useItemContext, useOpenFile } from "../../../../../../hooks/use-items";
+import {
+  useCreateItemIssue,
+  useItemContext,
+  useOpenFile,
+} from "../../../../../../hooks/use-items";
 import { useMemo, useEffect, useState, useCallback } from "react";
+import ItemComments from "../ItemComments";
+import ItemLinks from "../ItemLinks";
 
 // Import styles for CodeHighlight
 import "@mantine/code-highlight/styles.css";
@@ -24,6 +30,7 @@ import {
   IconChevronDown,
   IconChevronRight,
   IconCode,
+  IconBrandGithub,
   IconFileText,
 } from "@tabler/icons-react";
 
@@ -69,6 +76,8 @@ export default function ItemDetailDrawer({
   );
 
   const { mutate } = useOpenFile();
+  const { mutate: createIssue, isPending: isCreatingIssue } =
+    useCreateItemIssue();
 
   const [showCodeContext, setShowCodeContext] = useState(true);
 
@@ -168,6 +177,26 @@ export default function ItemDetailDrawer({
                 <Badge color="gray" variant="outline" size="sm">
                   Type: {selectedItem.type}
                 </Badge>
+                {selectedItem.stale && (
+                  <Badge color="yellow" variant="light" size="sm">
+                    Stale
+                  </Badge>
+                )}
+                {selectedItem.issue_number ? (
+                  <Badge color="dark" variant="outline" size="sm">
+                    Issue #{selectedItem.issue_number}
+                  </Badge>
+                ) : (
+                  <Button
+                    size="compact-xs"
+                    variant="light"
+                    leftSection={<IconBrandGithub size={12} />}
+                    loading={isCreatingIssue}
+                    onClick={() => createIssue(selectedItem.id)}
+                  >
+                    Create Issue
+                  </Button>
+                )}
               </Group>
             </Stack>
           </Card>
@@ -236,6 +265,10 @@ export default function ItemDetailDrawer({
               </Text>
             </Card>
           )}
+
+          <ItemLinks item={selectedItem} />
+
+          <ItemComments item={selectedItem} />
         </Stack>
       )}
     </Drawer>
@@ -0,0 +1,103 @@
+import {
+  ActionIcon,
+  Badge,
+  Button,
+  Card,
+  Group,
+  Select,
+  Stack,
+  Text,
+} from "@mantine/core";
+import { IconLink, IconTrash } from "@tabler/icons-react";
+import { useState } from "react";
+import { Item } from "../../../../../../types/item";
+import { LinkType } from "../../../../../../types/link";
+import {
+  useCreateLink,
+  useDeleteLink,
+  useItemLinks,
+} from "../../../../../../hooks/use-links";
+import { useNotes } from "../../../../../../hooks/use-notes";
+
+const linkTypes: LinkType[] = ["references", "blocks", "documents"];
+
+type Props = {
+  item: Item;
+};
+
+export default function ItemLinks({ item }: Props) {
+  const { data } = useItemLinks(item.key);
+  const { data: notes } = useNotes();
+  const { mutate: createLink, isPending } = useCreateLink();
+  const { mutate: deleteLink } = useDeleteLink();
+
+  const [noteId, setNoteId] = useState<string | null>(null);
+  const [linkType, setLinkType] = useState<LinkType>("references");
+
+  const submit = () => {
+    if (!noteId) return;
+    createLink(
+      { note_id: Number(noteId), item_key: item.key, type: linkType },
+      { onSuccess: () => setNoteId(null) }
+    );
+  };
+
+  return (
+    <Card withBorder p="md">
+      <Group gap="sm" mb="sm">
+        <IconLink size={16} color="#495057" />
+        <Text size="sm" fw={600}>
+          Linked Notes ({data?.count || 0})
+        </Text>
+      </Group>
+
+      <Stack gap="xs">
+        {data?.links.map((link) => (
+          <Group key={link.id} justify="space-between">
+            <Group gap="xs">
+              <Badge size="xs" variant="light">
+                {link.type}
+              </Badge>
+              <Text size="sm" c={link.note ? undefined : "dimmed"}>
+                {link.note?.title || `Missing note #${link.note_id}`}
+              </Text>
+            </Group>
+            <ActionIcon
+              size="sm"
+              variant="subtle"
+              color="red"
+              onClick={() => deleteLink(link.id)}
+            >
+              <IconTrash size={12} />
+            </ActionIcon>
+          </Group>
+        ))}
+
+        <Group gap="xs" align="flex-end">
+          <Select
+            size="xs"
+            placeholder="Select a note"
+            searchable
+            style={{ flex: 1 }}
+            value={noteId}
+            onChange={setNoteId}
+            data={(notes?.notes || []).map((note) => ({
+              value: String(note.id),
+              label: note.title,
+            }))}
+          />
+          <Select
+            size="xs"
+            w={120}
+            value={linkType}
+            onChange={(value) => value && setLinkType(value as LinkType)}
+            data={linkTypes}
+          />
+          <Button size="xs" loading={isPending} onClick={submit}>
+            Link
+          </Button>
+        </Group>
+      </Stack>
+    </Card>
+  );
+}
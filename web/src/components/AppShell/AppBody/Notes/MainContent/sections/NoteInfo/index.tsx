@@ -31,6 +31,7 @@ import { RoleGuard } from "../../../../../../Investor";
 import { categories, tagColors } from "../../../constants";
 import { useUpdateNote } from "../../../../../../../hooks/use-notes";
 import NoteTitle from "./sections/NoteTitle";
+import NoteLinks from "../NoteLinks";
 
 interface Props {
   editor: Editor;
@@ -333,6 +334,8 @@ export default function NoteInfo({ editor }: Props) {
             </Group>
           )}
         </Group>
+        <NoteLinks noteId={selectedNote.id} />
+
         {error && (
           <Alert color="red" variant="light">
             {error}
@@ -0,0 +1,49 @@
+import { ActionIcon, Badge, Group, Stack, Text } from "@mantine/core";
+import { IconLink, IconTrash } from "@tabler/icons-react";
+import { useDeleteLink, useNoteLinks } from "../../../../../../../hooks/use-links";
+
+type Props = {
+  noteId: number;
+};
+
+export default function NoteLinks({ noteId }: Props) {
+  const { data } = useNoteLinks(noteId);
+  const { mutate: deleteLink } = useDeleteLink();
+
+  if (!data || data.count === 0) return null;
+
+  return (
+    <Stack gap={4}>
+      <Group gap="xs">
+        <IconLink size={14} color="#868e96" />
+        <Text size="xs" c="dimmed">
+          Linked items
+        </Text>
+      </Group>
+      {data.links.map((link) => (
+        <Group key={link.id} gap="xs">
+          <Badge size="xs" variant="light">
+            {link.type}
+          </Badge>
+          <Text size="sm">
+            {link.item_type}: {link.item?.title || link.item_title}
+          </Text>
+          <Text size="xs" c="dimmed">
+            {link.file}:{link.line}
+          </Text>
+          <Badge size="xs" color={link.item ? "blue" : "gray"} variant="outline">
+            {link.item ? link.item.status : "removed"}
+          </Badge>
+          <ActionIcon
+            size="xs"
+            variant="subtle"
+            color="red"
+            onClick={() => deleteLink(link.id)}
+          >
+            <IconTrash size={10} />
+          </ActionIcon>
+        </Group>
+      ))}
+    </Stack>
+  );
+}
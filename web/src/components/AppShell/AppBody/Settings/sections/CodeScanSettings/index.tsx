@@ -8,12 +8,14 @@ import {
   TextInput,
   LoadingOverlay,
   ActionIcon,
+  NumberInput,
 } from "@mantine/core";
 import {
   useSettings,
   useUpdateSettings,
 } from "../../../../../../hooks/use-settings";
 import { IconEye, IconEyeOff } from "@tabler/icons-react";
+import { HistoryRetention } from "../../../../../../types/settings";
 
 export function CodeScanSettings() {
   const { data: settings, isSuccess } = useSettings();
@@ -56,6 +58,16 @@ export function CodeScanSettings() {
     });
   };
 
+  const handleRetentionChange = (
+    key: keyof HistoryRetention,
+    value: string | number
+  ) => {
+    if (typeof value !== "number") return;
+    updateSettings({
+      history_retention: { ...settings!.history_retention, [key]: value },
+    });
+  };
+
   if (!isSuccess) return <LoadingOverlay />;
 
   return (
@@ -109,6 +121,35 @@ export function CodeScanSettings() {
           />
         )}
       </Stack>
+
+      <Title size="h3" mt="lg">
+        History Retention
+      </Title>
+      <Stack gap="md" px="xs">
+        <Group grow align="flex-start">
+          <NumberInput
+            label="Full Snapshots"
+            description="Days to keep every snapshot with its items"
+            min={1}
+            value={settings.history_retention.full_days}
+            onChange={(value) => handleRetentionChange("full_days", value)}
+          />
+          <NumberInput
+            label="Daily Rollups"
+            description="Days to keep one rollup per day"
+            min={0}
+            value={settings.history_retention.daily_days}
+            onChange={(value) => handleRetentionChange("daily_days", value)}
+          />
+          <NumberInput
+            label="Weekly Rollups"
+            description="Days to keep one rollup per week (0 keeps forever)"
+            min={0}
+            value={settings.history_retention.weekly_days}
+            onChange={(value) => handleRetentionChange("weekly_days", value)}
+          />
+        </Group>
+      </Stack>
     </Container>
   );
 }
@@ -0,0 +1,18 @@
+import { useQuery } from "@tanstack/react-query";
+import { AutomationChange, AutomationReport } from "../types/automation";
+import { getAutomationLog, getAutomationPreview } from "../api/automation.api";
+
+export function useAutomationPreview(enabled: boolean, rule?: string) {
+  return useQuery<AutomationReport, Error>({
+    queryKey: ["automation", "preview", rule],
+    queryFn: () => getAutomationPreview(rule),
+    enabled,
+  });
+}
+
+export function useAutomationLog(limit = 100) {
+  return useQuery<AutomationChange[], Error>({
+    queryKey: ["automation", "log", limit],
+    queryFn: () => getAutomationLog(limit),
+  });
+}
@@ -0,0 +1,47 @@
+import { useQuery } from "@tanstack/react-query";
+import {
+  BurndownChart,
+  BurnupChart,
+  ChartParams,
+  CumulativeFlowChart,
+  Forecast,
+  ForecastParams,
+} from "../types/chart";
+import {
+  getBurndown,
+  getBurnup,
+  getCumulativeFlow,
+  getForecast,
+} from "../api/chart.api";
+
+export function useBurndown(enabled: boolean, params: ChartParams = {}) {
+  return useQuery<BurndownChart, Error>({
+    queryKey: ["charts", "burndown", params],
+    queryFn: () => getBurndown(params),
+    enabled,
+  });
+}
+
+export function useBurnup(enabled: boolean, params: ChartParams = {}) {
+  return useQuery<BurnupChart, Error>({
+    queryKey: ["charts", "burnup", params],
+    queryFn: () => getBurnup(params),
+    enabled,
+  });
+}
+
+export function useCumulativeFlow(enabled: boolean, params: ChartParams = {}) {
+  return useQuery<CumulativeFlowChart, Error>({
+    queryKey: ["charts", "cfd", params],
+    queryFn: () => getCumulativeFlow(params),
+    enabled,
+  });
+}
+
+export function useForecast(enabled: boolean, params: ForecastParams = {}) {
+  return useQuery<Forecast, Error>({
+    queryKey: ["charts", "forecast", params],
+    queryFn: () => getForecast(params),
+    enabled,
+  });
+}
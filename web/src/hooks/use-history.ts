@@ -1,10 +1,27 @@
 import { useQuery, useMutation, useQueryClient } from "@tanstack/react-query";
-import { History, TrendData, RecentChanges, Compare } from "../types/stat";
+import {
+  History,
+  TrendData,
+  RecentChanges,
+  Compare,
+  CompareParams,
+  DebtParams,
+  DebtPoint,
+  DebtReport,
+  HotspotParams,
+  HotspotReport,
+  ReleaseNotes,
+  ReleaseNotesParams,
+} from "../types/stat";
 import {
   loadHistory,
   loadTrends,
   loadChanges,
   loadComparison,
+  loadDebt,
+  loadDebtHistory,
+  loadHotspots,
+  loadReleaseNotes,
   refreshStats,
   cleanupStats,
 } from "../api/history.api";
@@ -33,14 +50,46 @@ export function useChanges(enabled: boolean) {
   });
 }
 
-export function useComparison(enabled: boolean) {
+export function useComparison(enabled: boolean, params: CompareParams = {}) {
   return useQuery<Compare, Error>({
-    queryKey: ["history", "comparison"],
-    queryFn: loadComparison,
+    queryKey: ["history", "comparison", params],
+    queryFn: () => loadComparison(params),
+    enabled,
+  });
+}
+
+export function useHotspots(enabled: boolean, params: HotspotParams = {}) {
+  return useQuery<HotspotReport, Error>({
+    queryKey: ["history", "hotspots", params],
+    queryFn: () => loadHotspots(params),
+    enabled,
+  });
+}
+
+export function useDebt(enabled: boolean, params: DebtParams = {}) {
+  return useQuery<DebtReport, Error>({
+    queryKey: ["history", "debt", params],
+    queryFn: () => loadDebt(params),
+    enabled,
+  });
+}
+
+export function useDebtHistory(enabled: boolean, path?: string) {
+  return useQuery<DebtPoint[], Error>({
+    queryKey: ["history", "debt", "history", path],
+    queryFn: () => loadDebtHistory(path),
     enabled,
   });
 }
 
+export function useReleaseNotes(params: ReleaseNotesParams) {
+  return useQuery<ReleaseNotes, Error>({
+    queryKey: ["history", "release-notes", params],
+    queryFn: () => loadReleaseNotes(params),
+    enabled: !!params.from,
+  });
+}
+
 export function useRefreshStats() {
   const queryClient = useQueryClient();
   return useMutation<{ success: boolean }, Error>({
@@ -1,25 +1,48 @@
 import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query";
 import {
+  AddItemCommentParams,
   Item,
+  ItemComment,
+  ItemCommentsResponse,
   ItemContext,
+  ItemIssue,
+  ItemLifecycle,
+  ItemRemoval,
   ItemStatus,
+  ItemSyncResult,
   OpenFileParams,
   OpenFileResponse,
   UpdateItemParams,
   UpdateItemResponse,
 } from "../types/item";
 import {
+  addItemComment,
+  createItemIssue,
+  deleteItemComment,
+  getArchivedItems,
+  getBoards,
   getItem,
+  getItemComments,
   getItemContext,
   getItems,
   openFile,
+  setItemRemoval,
+  syncItemIssues,
   updateItem,
 } from "../api/item.api";
+import { BoardSummary } from "../types/settings";
 
-export function useItems() {
+export function useItems(q?: string, board?: string) {
   return useQuery<Item[], Error>({
-    queryKey: ["items"],
-    queryFn: getItems,
+    queryKey: q || board ? ["items", q, board] : ["items"],
+    queryFn: () => getItems(q, board),
+  });
+}
+
+export function useBoards() {
+  return useQuery<BoardSummary[], Error>({
+    queryKey: ["boards"],
+    queryFn: getBoards,
   });
 }
 
@@ -62,3 +85,80 @@ export function useUpdateItem() {
     },
   });
 }
+
+export function useItemComments(key?: string) {
+  return useQuery<ItemCommentsResponse, Error>({
+    queryKey: ["item", "comments", key],
+    queryFn: () => getItemComments(key!),
+    enabled: !!key,
+  });
+}
+
+export function useAddItemComment() {
+  const queryClient = useQueryClient();
+
+  return useMutation<ItemComment, Error, AddItemCommentParams>({
+    mutationFn: addItemComment,
+    onSuccess: (_, variables) => {
+      queryClient.invalidateQueries({
+        queryKey: ["item", "comments", variables.item_key],
+      });
+    },
+  });
+}
+
+export function useDeleteItemComment(key?: string) {
+  const queryClient = useQueryClient();
+
+  return useMutation<any, Error, number>({
+    mutationFn: deleteItemComment,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["item", "comments", key] });
+    },
+  });
+}
+
+export function useCreateItemIssue() {
+  const queryClient = useQueryClient();
+
+  return useMutation<ItemIssue, Error, number>({
+    mutationFn: createItemIssue,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["items"] });
+    },
+  });
+}
+
+export function useSyncItemIssues() {
+  const queryClient = useQueryClient();
+
+  return useMutation<ItemSyncResult, Error>({
+    mutationFn: syncItemIssues,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["items"] });
+    },
+  });
+}
+
+export function useArchivedItems(enabled: boolean, board?: string) {
+  return useQuery<ItemLifecycle[], Error>({
+    queryKey: ["items", "archived", board],
+    queryFn: () => getArchivedItems(board),
+    enabled,
+  });
+}
+
+export function useSetItemRemoval() {
+  const queryClient = useQueryClient();
+
+  return useMutation<
+    ItemLifecycle,
+    Error,
+    { key: string; removal: ItemRemoval }
+  >({
+    mutationFn: setItemRemoval,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["items", "archived"] });
+    },
+  });
+}
@@ -0,0 +1,46 @@
+import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query";
+import { CreateLinkParams, Link, LinksResponse } from "../types/link";
+import {
+  createLink,
+  deleteLink,
+  getItemLinks,
+  getNoteLinks,
+} from "../api/link.api";
+
+export function useNoteLinks(noteId?: number) {
+  return useQuery<LinksResponse, Error>({
+    queryKey: ["links", "note", noteId],
+    queryFn: () => getNoteLinks(noteId!),
+    enabled: !!noteId,
+  });
+}
+
+export function useItemLinks(itemKey?: string) {
+  return useQuery<LinksResponse, Error>({
+    queryKey: ["links", "item", itemKey],
+    queryFn: () => getItemLinks(itemKey!),
+    enabled: !!itemKey,
+  });
+}
+
+export function useCreateLink() {
+  const queryClient = useQueryClient();
+
+  return useMutation<Link, Error, CreateLinkParams>({
+    mutationFn: createLink,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["links"] });
+    },
+  });
+}
+
+export function useDeleteLink() {
+  const queryClient = useQueryClient();
+
+  return useMutation<any, Error, number>({
+    mutationFn: deleteLink,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["links"] });
+    },
+  });
+}
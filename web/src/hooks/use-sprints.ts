@@ -0,0 +1,86 @@
+import { useMutation, useQuery, useQueryClient } from "@tanstack/react-query";
+import {
+  Sprint,
+  SprintAssignParams,
+  SprintParams,
+  SprintReport,
+} from "../types/sprint";
+import {
+  assignToSprint,
+  createSprint,
+  deleteSprint,
+  getSprintReport,
+  getSprints,
+  unassignFromSprint,
+  updateSprint,
+} from "../api/sprint.api";
+
+export function useSprints() {
+  return useQuery<Sprint[], Error>({
+    queryKey: ["sprints"],
+    queryFn: getSprints,
+  });
+}
+
+export function useSprintReport(id?: number) {
+  return useQuery<SprintReport, Error>({
+    queryKey: ["sprints", "report", id],
+    queryFn: () => getSprintReport(id!),
+    enabled: !!id,
+  });
+}
+
+export function useCreateSprint() {
+  const queryClient = useQueryClient();
+
+  return useMutation<Sprint, Error, SprintParams>({
+    mutationFn: createSprint,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["sprints"] });
+    },
+  });
+}
+
+export function useUpdateSprint() {
+  const queryClient = useQueryClient();
+
+  return useMutation<Sprint, Error, SprintParams>({
+    mutationFn: updateSprint,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["sprints"] });
+    },
+  });
+}
+
+export function useDeleteSprint() {
+  const queryClient = useQueryClient();
+
+  return useMutation<any, Error, number>({
+    mutationFn: deleteSprint,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["sprints"] });
+    },
+  });
+}
+
+export function useAssignToSprint() {
+  const queryClient = useQueryClient();
+
+  return useMutation<Sprint, Error, SprintAssignParams>({
+    mutationFn: assignToSprint,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["sprints"] });
+    },
+  });
+}
+
+export function useUnassignFromSprint() {
+  const queryClient = useQueryClient();
+
+  return useMutation<Sprint, Error, SprintAssignParams>({
+    mutationFn: unassignFromSprint,
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ["sprints"] });
+    },
+  });
+}
@@ -0,0 +1,22 @@
+export type AutomationAction = "escalate" | "flag_stale" | "unflag_stale";
+
+export interface AutomationChange {
+  rule_id: string;
+  rule_name: string;
+  action: AutomationAction;
+  item_key: string;
+  title: string;
+  file: string;
+  line: number;
+  from?: string;
+  to?: string;
+  error?: string;
+  timestamp: string;
+}
+
+export interface AutomationReport {
+  dry_run: boolean;
+  evaluated_at: string;
+  rules: number;
+  changes: AutomationChange[];
+}
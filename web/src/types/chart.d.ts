@@ -0,0 +1,85 @@
+import { KanbanColumn } from "./settings";
+
+export interface ChartRange {
+  board: string;
+  sprint_id?: number;
+  query?: string;
+  from: string;
+  to: string;
+}
+
+export interface ChartParams {
+  board?: string;
+  sprint?: number;
+  q?: string;
+  from?: string;
+  to?: string;
+}
+
+export interface BurndownPoint {
+  date: string;
+  remaining: number;
+  ideal: number;
+  scope: number;
+  completed: number;
+}
+
+export interface BurndownChart extends ChartRange {
+  points: BurndownPoint[];
+}
+
+export interface BurnupPoint {
+  date: string;
+  scope: number;
+  completed: number;
+}
+
+export interface BurnupChart extends ChartRange {
+  points: BurnupPoint[];
+}
+
+export interface CumulativeFlowChart extends ChartRange {
+  columns: KanbanColumn[];
+  points: Array<{ date: string } & Record<string, number>>;
+}
+
+export interface ForecastParams {
+  board?: string;
+  q?: string;
+  saved?: string;
+  column?: string;
+  until?: string;
+  window?: number;
+  trials?: number;
+}
+
+export interface ThroughputSample {
+  date: string;
+  completed: number;
+}
+
+export interface CompletionPercentile {
+  percentile: number;
+  days: number;
+  date: string;
+}
+
+export interface VolumePercentile {
+  percentile: number;
+  items: number;
+}
+
+export interface Forecast {
+  board: string;
+  query?: string;
+  column?: string;
+  remaining: number;
+  window_days: number;
+  trials: number;
+  average_throughput: number;
+  throughput: ThroughputSample[];
+  completion?: CompletionPercentile[];
+  until?: string;
+  items_by_date?: VolumePercentile[];
+  message?: string;
+}
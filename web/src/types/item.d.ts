@@ -34,6 +34,7 @@ export interface StatusHistory {
 
 export interface Item {
   id: number;
+  key: string;
   type: ItemType;
   title: string;
   description: string;
@@ -41,6 +42,9 @@ export interface Item {
   line: number;
   status: ItemStatus;
   priority: ItemPriority;
+  issue_number?: number;
+  boards: string[];
+  stale?: boolean;
 
   // Track status changes over time
   history?: StatusHistory[];
@@ -104,3 +108,76 @@ export interface UpdateItemResponse {
   id: number;
   status: string;
 }
+
+export interface ItemComment {
+  id: number;
+  parent_id?: number;
+  author: string;
+  body: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ItemCommentThread {
+  item_key: string;
+  file: string;
+  line: number;
+  type: ItemType;
+  title: string;
+  comments: ItemComment[];
+}
+
+export interface ItemCommentsResponse {
+  thread: ItemCommentThread;
+  count: number;
+}
+
+export interface AddItemCommentParams {
+  item_key: string;
+  parent_id?: number;
+  body: string;
+}
+
+export interface ItemIssue {
+  issue_number: number;
+  issue_url: string;
+  item_key: string;
+  file: string;
+  line: number;
+  type: ItemType;
+  title: string;
+  commit: string;
+  state: string;
+  created_at: string;
+  last_sync?: string;
+}
+
+export interface ItemSyncResult {
+  issues_closed: number;
+  items_moved: number;
+  tracked: number;
+  errors: string[];
+}
+
+export type ItemRemoval = "resolved" | "discarded";
+
+export interface ItemLifecycle {
+  key: string;
+  type: ItemType;
+  title: string;
+  file: string;
+  line: number;
+  priority: ItemPriority;
+  last_status: ItemStatus;
+  boards?: string[];
+  first_seen_at: string;
+  first_seen_commit: string;
+  last_seen_at: string;
+  last_seen_commit: string;
+  last_seen_branch?: string;
+  removed_at?: string;
+  removed_commit?: string;
+  removed_message?: string;
+  removal?: ItemRemoval;
+  manual_removal?: boolean;
+}
@@ -0,0 +1,34 @@
+import { Item, ItemType } from "./item";
+import { Note } from "./note";
+
+export type LinkType = "references" | "blocks" | "documents";
+
+export interface Link {
+  id: number;
+  note_id: number;
+  item_key: string;
+  type: LinkType;
+  file: string;
+  line: number;
+  item_type: ItemType;
+  item_title: string;
+  author: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ResolvedLink extends Link {
+  note?: Note;
+  item?: Item;
+}
+
+export interface LinksResponse {
+  links: ResolvedLink[];
+  count: number;
+}
+
+export interface CreateLinkParams {
+  note_id: number;
+  item_key: string;
+  type: LinkType;
+}
@@ -5,6 +5,17 @@ export type KanbanColumn = {
   auto_assign_pattern?: string;
 };
 
+export type Board = {
+  id: string;
+  name: string;
+  columns: KanbanColumn[];
+  path_filters?: string[];
+};
+
+export type BoardSummary = Board & {
+  item_count: number;
+};
+
 export type PriorityPatterns = {
   low: string;
   medium: string;
@@ -27,9 +38,46 @@ export type CodeScanSettings = {
   sync_enabled: boolean;
 };
 
+export type HistoryRetention = {
+  full_days: number;
+  daily_days: number;
+  weekly_days: number;
+};
+
+export type SavedQuery = {
+  name: string;
+  query: string;
+};
+
+export type AutomationRule = {
+  id: string;
+  name: string;
+  enabled: boolean;
+  query: string;
+  untouched_days: number;
+  no_commit_days?: number;
+  action: "escalate" | "flag_stale";
+  priority?: "LOW" | "MEDIUM" | "HIGH";
+};
+
+export type DebtModel = {
+  type_weights: Record<string, number>;
+  priority_weights: Record<string, number>;
+  age_per_month: number;
+  alert_threshold: number;
+  alert_window_days: number;
+};
+
 export type Settings = {
+  schema_version: number;
   kanban_columns: KanbanColumn[];
+  boards: Board[];
   priority_patterns: PriorityPatterns;
   github_auth: GithubAuth;
   code_scan_settings: CodeScanSettings;
+  saved_queries: SavedQuery[];
+  history_retention: HistoryRetention;
+  automation_rules: AutomationRule[];
+  debt_model: DebtModel;
+  storage: "json" | "bolt" | "files";
 };
@@ -0,0 +1,79 @@
+import { ItemStatus, ItemType } from "./item";
+import { Note } from "./note";
+
+export type SprintKind = "sprint" | "milestone";
+
+export type SprintState = "planned" | "active" | "closed";
+
+export interface SprintItem {
+  item_key: string;
+  type: ItemType;
+  title: string;
+  file: string;
+  added_at: string;
+  added_by: string;
+  removed_at?: string;
+}
+
+export interface Sprint {
+  id: number;
+  name: string;
+  kind: SprintKind;
+  goal: string;
+  start_date: string;
+  end_date: string;
+  items: SprintItem[];
+  note_ids: number[];
+  created_at: string;
+  updated_at: string;
+}
+
+export interface SprintItemReport {
+  item_key: string;
+  type: ItemType;
+  title: string;
+  file: string;
+  line: number;
+  status: ItemStatus;
+  in_code: boolean;
+  added_at: string;
+  removed_at?: string;
+  completed_at?: string;
+}
+
+export interface SprintReport {
+  sprint: Sprint;
+  state: SprintState;
+  days_total: number;
+  days_elapsed: number;
+  initial_scope: number;
+  scope_added: number;
+  scope_removed: number;
+  scope: number;
+  completed: number;
+  done_before_start: number;
+  remaining: number;
+  carry_over: number;
+  completion_percent: number;
+  items: SprintItemReport[];
+  added_items: SprintItemReport[];
+  removed_items: SprintItemReport[];
+  carry_over_items: SprintItemReport[];
+  notes: Note[];
+}
+
+export interface SprintParams {
+  id?: number;
+  name: string;
+  kind?: SprintKind;
+  goal?: string;
+  start_date?: string;
+  end_date: string;
+}
+
+export interface SprintAssignParams {
+  sprint_id: number;
+  item_id?: number;
+  item_key?: string;
+  note_id?: number;
+}
@@ -15,6 +15,9 @@ export interface BranchSnapshot {
     by_priority: Record<string, number>;
     items: Item[];
   };
+  backfilled?: boolean;
+  rollup?: "daily" | "weekly";
+  debt?: Record<string, number>;
 }
 
 export interface ItemChange {
@@ -32,6 +35,7 @@ export interface TrendData {
     todo: number;
     in_progress: number;
     done: number;
+    rollup?: "daily" | "weekly";
   }>;
   completion_rate: Array<{
     timestamp: string;
@@ -48,13 +52,22 @@ export interface TrendData {
   >;
 }
 
+export interface ItemMatchChange {
+  item: Item;
+  previous: Item;
+  changes: Array<"moved" | "renamed" | "priority">;
+  similarity: number;
+}
+
 export interface RecentChanges {
   added?: Item[];
   removed?: Item[];
+  changed?: ItemMatchChange[];
   status_changed?: ItemChange[];
   summary: {
     added: number;
     removed: number;
+    changed: number;
     status_changed: number;
   };
 }
@@ -71,8 +84,147 @@ export interface Changes {
   total: number;
 }
 
+export interface CompareSide {
+  ref: string;
+  commit: string;
+  commit_short: string;
+  message: string;
+  branch?: string;
+  timestamp: string;
+  source: "snapshot" | "scan";
+  history: BranchSnapshot["history"];
+  board: {
+    total: number;
+    done: number;
+    by_status: Record<string, number>;
+    by_type: Record<string, number>;
+    by_priority: Record<string, number>;
+  };
+}
+
+export interface ColumnDelta {
+  id: string;
+  name: string;
+  from: number;
+  to: number;
+  delta: number;
+}
+
+export interface CompareParams {
+  from?: string;
+  to?: string;
+  board?: string;
+}
+
 export interface Compare {
+  board: string;
+  from: CompareSide;
+  to: CompareSide;
+  added: Item[];
+  removed: Item[];
+  changed: ItemMatchChange[];
+  status_changed: ItemChange[];
+  columns: ColumnDelta[];
+  summary: RecentChanges["summary"];
   changes: Changes;
-  current: Omit<BranchSnapshot, "commit_short", "commit_message">;
-  previous: Omit<BranchSnapshot, "commit_short", "commit_message">;
+  current: CompareSide;
+  previous: CompareSide;
+  error?: string;
+}
+
+export interface HotspotParams {
+  board?: string;
+  window?: number;
+  limit?: number;
+  depth?: number;
+}
+
+export interface Hotspot {
+  path: string;
+  items: number;
+  high_priority: number;
+  commits: number;
+  lines_changed: number;
+  score: number;
+}
+
+export interface HotspotReport {
+  since: string;
+  window_days: number;
+  commits: number;
+  files: Hotspot[];
+  directories: Hotspot[];
+}
+
+export interface DebtParams {
+  path?: string;
+  depth?: number;
+  since?: string;
+}
+
+export interface DirectoryDebt {
+  path: string;
+  items: number;
+  score: number;
+  previous: number;
+  delta: number;
+}
+
+export interface DebtReport {
+  total: number;
+  since: string;
+  threshold: number;
+  directories: DirectoryDebt[];
+  regressions: DirectoryDebt[];
+}
+
+export interface DebtPoint {
+  timestamp: string;
+  commit: string;
+  score: number;
+}
+
+export interface ReleaseNotesParams {
+  from: string;
+  to?: string;
+}
+
+export interface ReleaseCommit {
+  hash: string;
+  short: string;
+  author: string;
+  message: string;
+  timestamp: string;
+  url?: string;
+}
+
+export interface ReleaseItem {
+  type: string;
+  title: string;
+  file: string;
+  line: number;
+  priority: string;
+  resolution?: "removed" | "done";
+  commits: ReleaseCommit[];
+}
+
+export interface ReleaseGroup {
+  type: string;
+  directory: string;
+  items: ReleaseItem[];
+}
+
+export interface ReleaseRef {
+  ref: string;
+  commit: string;
+  commit_short: string;
+  timestamp: string;
+}
+
+export interface ReleaseNotes {
+  from: ReleaseRef;
+  to: ReleaseRef;
+  resolved: ReleaseGroup[];
+  introduced: ReleaseGroup[];
+  summary: { resolved: number; introduced: number };
 }